@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/apikeys"
+	"github.com/sirupsen/logrus"
+)
+
+// apiKeys creates or revokes the api keys authenticating requests when AUTH_ENABLED is true
+func apiKeys(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing apikeys subcommand, expected create or revoke")
+	}
+
+	switch args[0] {
+	case "create":
+		return createAPIKey(args[1:])
+	case "revoke":
+		return revokeAPIKey(args[1:])
+	default:
+		return fmt.Errorf("unknown apikeys subcommand %q, expected create or revoke", args[0])
+	}
+}
+
+// createAPIKey prints a new api key once, only its hash is stored
+func createAPIKey(args []string) error {
+	flags := flag.NewFlagSet("apikeys create", flag.ExitOnError)
+	name := flags.String("name", "", "what the key is used by, e.g. billing-service")
+	user := flags.Int("user", 0, "id of the user the key acts for, none when omitted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var userID *int
+	if *user > 0 {
+		userID = user
+	}
+
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+
+	dbClient, err := connectDB()
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	created, err := apikeys.NewRepository(dbClient).CreateKey(context.Background(), *name, userID, auth.HashAPIKey(key))
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("created api key %d %q, it is not shown again", created.ID, created.Name)
+	fmt.Fprintln(os.Stdout, key)
+	return nil
+}
+
+func revokeAPIKey(args []string) error {
+	flags := flag.NewFlagSet("apikeys revoke", flag.ExitOnError)
+	id := flags.Int("id", 0, "id of the api key")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dbClient, err := connectDB()
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	if err := apikeys.NewRepository(dbClient).RevokeKey(context.Background(), *id); err != nil {
+		return err
+	}
+
+	logrus.Infof("revoked api key %d", *id)
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/nvasilev98/rentals/cmd/rentals/env"
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// checkConfig validates the application and database configuration, reads the JWKS file and connects
+// to the database, with REPOSITORY=memory it loads the seed file instead
+func checkConfig(args []string) error {
+	if err := flag.NewFlagSet("check-config", flag.ExitOnError).Parse(args); err != nil {
+		return err
+	}
+
+	appConfig, err := loadAppConfig()
+	if err != nil {
+		return err
+	}
+	logrus.Infof("application configuration is valid, the server listens on %s:%d", appConfig.Host, appConfig.Port)
+
+	if appConfig.JWKSFile != "" {
+		keys, err := auth.ReadJWKSFile(appConfig.JWKSFile)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("jwks file %s has %d signing keys", appConfig.JWKSFile, len(keys.Keys))
+	}
+
+	if appConfig.Repository == env.RepositoryMemory {
+		seed, err := memory.ReadSeedFile(appConfig.SeedFile)
+		if err != nil {
+			return err
+		}
+
+		if _, err := memory.NewRentalRepository(seed); err != nil {
+			return err
+		}
+		logrus.Infof("seed file %s is valid, no database is used", appConfig.SeedFile)
+		return nil
+	}
+
+	dbClient, err := connectDB()
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+	logrus.Info("database configuration is valid and the database is reachable")
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/nvasilev98/rentals/cmd/rentals/env"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+func loadAppConfig() (env.AppConfig, error) {
+	appConfig, err := env.LoadAppConfig()
+	if err != nil {
+		return env.AppConfig{}, err
+	}
+
+	return appConfig, appConfig.Validate()
+}
+
+// connectDB loads and validates the database configuration and opens a connection
+func connectDB() (*sql.DB, error) {
+	dbConfig, err := postgres.LoadDBConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dbConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	return postgres.Connect(dbConfig)
+}
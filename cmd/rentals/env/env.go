@@ -2,13 +2,33 @@ package env
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
+// Repository implementations selected by REPOSITORY
+const (
+	RepositoryPostgres = "postgres"
+	RepositoryMemory   = "memory"
+)
+
 type AppConfig struct {
 	Host string `envconfig:"HOST" default:"localhost"`
 	Port int    `envconfig:"PORT" default:"8080"`
+	// Repository is postgres, or memory to serve rentals from SeedFile without a database
+	Repository string `envconfig:"REPOSITORY" default:"postgres"`
+	SeedFile   string `envconfig:"SEED_FILE" default:"fixtures/sample-data.json"`
+	// CacheSize is the number of rental lookups and searches cached in process, 0 disables the cache
+	CacheSize int           `envconfig:"CACHE_SIZE" default:"1000"`
+	CacheTTL  time.Duration `envconfig:"CACHE_TTL" default:"30s"`
+	// AuthEnabled requires a JWT signed by a key of JWKSFile or an api key on every route,
+	// turning it off leaves every route anonymous and is meant for local development only
+	AuthEnabled bool   `envconfig:"AUTH_ENABLED" default:"true"`
+	JWKSFile    string `envconfig:"JWKS_FILE"`
+	// JWTIssuer and JWTAudience are compared with the iss and aud claims when they are set
+	JWTIssuer   string `envconfig:"JWT_ISSUER"`
+	JWTAudience string `envconfig:"JWT_AUDIENCE"`
 }
 
 // LoadAppConfig is loading the application config provided in the environment
@@ -20,3 +40,37 @@ func LoadAppConfig() (AppConfig, error) {
 
 	return config, nil
 }
+
+// Validate checks values that envconfig accepts but the server cannot use
+func (c AppConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("HOST must not be empty")
+	}
+
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("PORT must be between 1 and 65535, got %d", c.Port)
+	}
+
+	if c.Repository != RepositoryPostgres && c.Repository != RepositoryMemory {
+		return fmt.Errorf("REPOSITORY must be %s or %s, got %q", RepositoryPostgres, RepositoryMemory, c.Repository)
+	}
+
+	if c.Repository == RepositoryMemory && c.SeedFile == "" {
+		return fmt.Errorf("SEED_FILE must not be empty when REPOSITORY is %s", RepositoryMemory)
+	}
+
+	if c.CacheSize < 0 {
+		return fmt.Errorf("CACHE_SIZE must not be negative, got %d", c.CacheSize)
+	}
+
+	if c.CacheSize > 0 && c.CacheTTL <= 0 {
+		return fmt.Errorf("CACHE_TTL must be positive when the cache is enabled, got %s", c.CacheTTL)
+	}
+
+	// api keys are stored in postgres, without it a JWT is the only credential
+	if c.AuthEnabled && c.Repository == RepositoryMemory && c.JWKSFile == "" {
+		return fmt.Errorf("JWKS_FILE must be set when AUTH_ENABLED is true and REPOSITORY is %s", RepositoryMemory)
+	}
+
+	return nil
+}
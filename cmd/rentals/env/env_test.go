@@ -3,6 +3,7 @@ package env_test
 import (
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/nvasilev98/rentals/cmd/rentals/env"
 	. "github.com/onsi/ginkgo/v2"
@@ -12,23 +13,26 @@ import (
 var _ = Describe("Config", func() {
 
 	const (
-		hostEnv     = "HOST"
-		portEnv     = "PORT"
-		validHost   = "127.0.0.1"
-		defaultHost = "localhost"
-		validPort   = 8000
-		defaultPort = 8080
+		hostEnv       = "HOST"
+		portEnv       = "PORT"
+		repositoryEnv = "REPOSITORY"
+		validHost     = "127.0.0.1"
+		defaultHost   = "localhost"
+		validPort     = 8000
+		defaultPort   = 8080
 	)
 
 	When("environment is set", func() {
 		BeforeEach(func() {
 			Expect(os.Setenv(hostEnv, validHost)).To(Succeed())
 			Expect(os.Setenv(portEnv, strconv.Itoa(validPort))).To(Succeed())
+			Expect(os.Setenv(repositoryEnv, env.RepositoryMemory)).To(Succeed())
 		})
 
 		AfterEach(func() {
 			Expect(os.Unsetenv(hostEnv)).To(Succeed())
 			Expect(os.Unsetenv(portEnv)).To(Succeed())
+			Expect(os.Unsetenv(repositoryEnv)).To(Succeed())
 		})
 
 		It("host and port are provided", func() {
@@ -36,6 +40,7 @@ var _ = Describe("Config", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(config.Host).To(Equal(validHost))
 			Expect(config.Port).To(Equal(validPort))
+			Expect(config.Repository).To(Equal(env.RepositoryMemory))
 		})
 	})
 
@@ -45,7 +50,44 @@ var _ = Describe("Config", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(config.Host).To(Equal(defaultHost))
 			Expect(config.Port).To(Equal(defaultPort))
+			Expect(config.Repository).To(Equal(env.RepositoryPostgres))
+			Expect(config.SeedFile).To(Equal("fixtures/sample-data.json"))
+			Expect(config.CacheSize).To(Equal(1000))
+			Expect(config.CacheTTL).To(Equal(30 * time.Second))
+			Expect(config.AuthEnabled).To(BeTrue())
+			Expect(config.JWKSFile).To(BeEmpty())
 		})
 	})
 
+	DescribeTable("validating configuration",
+		func(config env.AppConfig, valid bool) {
+			if valid {
+				Expect(config.Validate()).To(Succeed())
+			} else {
+				Expect(config.Validate()).ToNot(Succeed())
+			}
+		},
+		Entry("default values", env.AppConfig{Host: defaultHost, Port: defaultPort, Repository: env.RepositoryPostgres}, true),
+		Entry("empty host", env.AppConfig{Port: defaultPort, Repository: env.RepositoryPostgres}, false),
+		Entry("port zero", env.AppConfig{Host: defaultHost, Repository: env.RepositoryPostgres}, false),
+		Entry("port above the tcp range", env.AppConfig{Host: defaultHost, Port: 65536, Repository: env.RepositoryPostgres}, false),
+		Entry("memory repository", env.AppConfig{Host: defaultHost, Port: defaultPort, Repository: env.RepositoryMemory,
+			SeedFile: "fixtures/sample-data.json"}, true),
+		Entry("memory repository without a seed file", env.AppConfig{Host: defaultHost, Port: defaultPort,
+			Repository: env.RepositoryMemory}, false),
+		Entry("negative cache size", env.AppConfig{Host: defaultHost, Port: defaultPort, Repository: env.RepositoryPostgres,
+			CacheSize: -1}, false),
+		Entry("cache without a ttl", env.AppConfig{Host: defaultHost, Port: defaultPort, Repository: env.RepositoryPostgres,
+			CacheSize: 10}, false),
+		Entry("cache with a ttl", env.AppConfig{Host: defaultHost, Port: defaultPort, Repository: env.RepositoryPostgres,
+			CacheSize: 10, CacheTTL: time.Minute}, true),
+		Entry("authentication with api keys only", env.AppConfig{Host: defaultHost, Port: defaultPort,
+			Repository: env.RepositoryPostgres, AuthEnabled: true}, true),
+		Entry("authentication without a database nor a jwks file", env.AppConfig{Host: defaultHost, Port: defaultPort,
+			Repository: env.RepositoryMemory, SeedFile: "fixtures/sample-data.json", AuthEnabled: true}, false),
+		Entry("authentication without a database with a jwks file", env.AppConfig{Host: defaultHost, Port: defaultPort,
+			Repository: env.RepositoryMemory, SeedFile: "fixtures/sample-data.json", AuthEnabled: true, JWKSFile: "jwks.json"}, true),
+		Entry("unknown repository", env.AppConfig{Host: defaultHost, Port: defaultPort, Repository: "mysql"}, false),
+	)
+
 })
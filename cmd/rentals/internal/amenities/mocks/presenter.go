@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presenter.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	amenities "github.com/nvasilev98/rentals/pkg/repository/postgres/amenities"
+)
+
+// MockAmenityRepository is a mock of AmenityRepository interface.
+type MockAmenityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAmenityRepositoryMockRecorder
+}
+
+// MockAmenityRepositoryMockRecorder is the mock recorder for MockAmenityRepository.
+type MockAmenityRepositoryMockRecorder struct {
+	mock *MockAmenityRepository
+}
+
+// NewMockAmenityRepository creates a new mock instance.
+func NewMockAmenityRepository(ctrl *gomock.Controller) *MockAmenityRepository {
+	mock := &MockAmenityRepository{ctrl: ctrl}
+	mock.recorder = &MockAmenityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAmenityRepository) EXPECT() *MockAmenityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAmenity mocks base method.
+func (m *MockAmenityRepository) CreateAmenity(ctx context.Context, input amenities.Input) (amenities.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAmenity", ctx, input)
+	ret0, _ := ret[0].(amenities.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAmenity indicates an expected call of CreateAmenity.
+func (mr *MockAmenityRepositoryMockRecorder) CreateAmenity(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAmenity", reflect.TypeOf((*MockAmenityRepository)(nil).CreateAmenity), ctx, input)
+}
+
+// DeleteAmenity mocks base method.
+func (m *MockAmenityRepository) DeleteAmenity(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAmenity", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAmenity indicates an expected call of DeleteAmenity.
+func (mr *MockAmenityRepositoryMockRecorder) DeleteAmenity(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAmenity", reflect.TypeOf((*MockAmenityRepository)(nil).DeleteAmenity), ctx, id)
+}
+
+// PatchAmenity mocks base method.
+func (m *MockAmenityRepository) PatchAmenity(ctx context.Context, id int, patch amenities.Patch) (amenities.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchAmenity", ctx, id, patch)
+	ret0, _ := ret[0].(amenities.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchAmenity indicates an expected call of PatchAmenity.
+func (mr *MockAmenityRepositoryMockRecorder) PatchAmenity(ctx, id, patch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchAmenity", reflect.TypeOf((*MockAmenityRepository)(nil).PatchAmenity), ctx, id, patch)
+}
+
+// ReplaceRentalAmenities mocks base method.
+func (m *MockAmenityRepository) ReplaceRentalAmenities(ctx context.Context, rentalID int, slugs []string) ([]amenities.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRentalAmenities", ctx, rentalID, slugs)
+	ret0, _ := ret[0].([]amenities.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceRentalAmenities indicates an expected call of ReplaceRentalAmenities.
+func (mr *MockAmenityRepositoryMockRecorder) ReplaceRentalAmenities(ctx, rentalID, slugs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRentalAmenities", reflect.TypeOf((*MockAmenityRepository)(nil).ReplaceRentalAmenities), ctx, rentalID, slugs)
+}
+
+// RetrieveAmenities mocks base method.
+func (m *MockAmenityRepository) RetrieveAmenities(ctx context.Context) ([]amenities.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveAmenities", ctx)
+	ret0, _ := ret[0].([]amenities.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveAmenities indicates an expected call of RetrieveAmenities.
+func (mr *MockAmenityRepositoryMockRecorder) RetrieveAmenities(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveAmenities", reflect.TypeOf((*MockAmenityRepository)(nil).RetrieveAmenities), ctx)
+}
+
+// RetrieveAmenityByID mocks base method.
+func (m *MockAmenityRepository) RetrieveAmenityByID(ctx context.Context, id int) (amenities.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveAmenityByID", ctx, id)
+	ret0, _ := ret[0].(amenities.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveAmenityByID indicates an expected call of RetrieveAmenityByID.
+func (mr *MockAmenityRepositoryMockRecorder) RetrieveAmenityByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveAmenityByID", reflect.TypeOf((*MockAmenityRepository)(nil).RetrieveAmenityByID), ctx, id)
+}
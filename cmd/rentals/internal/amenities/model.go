@@ -0,0 +1,28 @@
+package amenities
+
+type AmenityResponse struct {
+	ID   int    `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type AmenitiesResponse struct {
+	Amenities []AmenityResponse `json:"amenities"`
+}
+
+// AmenityRequest is the body of POST /amenities
+type AmenityRequest struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// AmenityPatchRequest is the body of PATCH /amenities/:id, omitted fields are left unchanged
+type AmenityPatchRequest struct {
+	Slug *string `json:"slug"`
+	Name *string `json:"name"`
+}
+
+// RentalAmenitiesRequest is the body of PUT /rentals/:id/amenities, it replaces every amenity of the rental
+type RentalAmenitiesRequest struct {
+	Amenities []string `json:"amenities"`
+}
@@ -0,0 +1,173 @@
+package amenities
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/httpx"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/amenities"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=presenter.go --destination mocks/presenter.go --package mocks
+
+// amenityErrors answers the errors of the amenity catalog, rentalAmenityErrors those of replacing
+// the amenities of a rental, where a missing rental is what is not found
+var (
+	amenityErrors = httpx.Errors{
+		Resource: "amenity",
+		Conflict: "amenity slug already exists",
+		Invalid:  "invalid amenity",
+	}
+	rentalAmenityErrors = httpx.Errors{
+		Resource: "rental",
+		Conflict: "amenity slug already exists",
+		Invalid:  "invalid amenity",
+	}
+)
+
+type AmenityRepository interface {
+	RetrieveAmenities(ctx context.Context) ([]amenities.Model, error)
+	RetrieveAmenityByID(ctx context.Context, id int) (amenities.Model, error)
+	CreateAmenity(ctx context.Context, input amenities.Input) (amenities.Model, error)
+	PatchAmenity(ctx context.Context, id int, patch amenities.Patch) (amenities.Model, error)
+	DeleteAmenity(ctx context.Context, id int) error
+	ReplaceRentalAmenities(ctx context.Context, rentalID int, slugs []string) ([]amenities.Model, error)
+}
+
+type Presenter struct {
+	amenityRepository AmenityRepository
+}
+
+// NewPresenter is a constructor function
+func NewPresenter(amenityRepository AmenityRepository) *Presenter {
+	return &Presenter{
+		amenityRepository: amenityRepository,
+	}
+}
+
+// RetrieveAmenities retrieves the whole amenity catalog ordered by slug
+func (p *Presenter) RetrieveAmenities(ctx *gin.Context) {
+	catalog, err := p.amenityRepository.RetrieveAmenities(ctx)
+	if err != nil {
+		logrus.Error("failed to retrieve amenities from repository: ", err)
+		ctx.JSON(amenityErrors.Response(err, "failed to retrieve amenities"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAmenitiesResponse(catalog))
+}
+
+// RetrieveAmenityByID retrieves an amenity by a given id
+func (p *Presenter) RetrieveAmenityByID(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	amenity, err := p.amenityRepository.RetrieveAmenityByID(ctx, id)
+	if err != nil {
+		logrus.Error("failed to retrieve amenity by id from repository: ", err)
+		ctx.JSON(amenityErrors.Response(err, "failed to retrieve amenity by id"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAmenityResponse(amenity))
+}
+
+// CreateAmenity adds an amenity to the catalog from the request body
+func (p *Presenter) CreateAmenity(ctx *gin.Context) {
+	var request AmenityRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	amenity, err := p.amenityRepository.CreateAmenity(ctx, amenities.Input{Slug: request.Slug, Name: request.Name})
+	if err != nil {
+		logrus.Error("failed to create amenity in repository: ", err)
+		ctx.JSON(amenityErrors.Response(err, "failed to create amenity"))
+		return
+	}
+
+	ctx.Header("Location", fmt.Sprintf("/amenities/%d", amenity.ID))
+	ctx.JSON(http.StatusCreated, toAmenityResponse(amenity))
+}
+
+// PatchAmenity changes only the fields present in the request body of an amenity by a given id
+func (p *Presenter) PatchAmenity(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	var request AmenityPatchRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	amenity, err := p.amenityRepository.PatchAmenity(ctx, id, amenities.Patch{Slug: request.Slug, Name: request.Name})
+	if err != nil {
+		logrus.Error("failed to patch amenity in repository: ", err)
+		ctx.JSON(amenityErrors.Response(err, "failed to patch amenity"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAmenityResponse(amenity))
+}
+
+// DeleteAmenity deletes an amenity by a given id
+func (p *Presenter) DeleteAmenity(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := p.amenityRepository.DeleteAmenity(ctx, id); err != nil {
+		logrus.Error("failed to delete amenity from repository: ", err)
+		ctx.JSON(amenityErrors.Response(err, "failed to delete amenity"))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ReplaceRentalAmenities replaces the amenities of a rental by a given id with the slugs of the request body
+func (p *Presenter) ReplaceRentalAmenities(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	var request RentalAmenitiesRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	replaced, err := p.amenityRepository.ReplaceRentalAmenities(ctx, id, request.Amenities)
+	if err != nil {
+		logrus.Error("failed to replace rental amenities in repository: ", err)
+		ctx.JSON(rentalAmenityErrors.Response(err, "failed to replace rental amenities"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAmenitiesResponse(replaced))
+}
+
+func toAmenityResponse(amenity amenities.Model) AmenityResponse {
+	return AmenityResponse{
+		ID:   amenity.ID,
+		Slug: amenity.Slug,
+		Name: amenity.Name,
+	}
+}
+
+func toAmenitiesResponse(catalog []amenities.Model) AmenitiesResponse {
+	amenitiesResponse := make([]AmenityResponse, 0, len(catalog))
+	for _, amenity := range catalog {
+		amenitiesResponse = append(amenitiesResponse, toAmenityResponse(amenity))
+	}
+
+	return AmenitiesResponse{Amenities: amenitiesResponse}
+}
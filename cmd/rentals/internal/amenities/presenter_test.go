@@ -0,0 +1,174 @@
+package amenities_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/amenities"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/amenities/mocks"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	a "github.com/nvasilev98/rentals/pkg/repository/postgres/amenities"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Presenter", func() {
+	var (
+		gomockCtrl      *gomock.Controller
+		mockAmenityRepo *mocks.MockAmenityRepository
+		presenter       *amenities.Presenter
+		recorder        *httptest.ResponseRecorder
+		mockContext     *gin.Context
+	)
+
+	BeforeEach(func() {
+		gomockCtrl, _ = gomock.WithContext(context.Background(), GinkgoT())
+		mockAmenityRepo = mocks.NewMockAmenityRepository(gomockCtrl)
+		presenter = amenities.NewPresenter(mockAmenityRepo)
+		recorder = httptest.NewRecorder()
+		mockContext, _ = gin.CreateTestContext(recorder)
+	})
+
+	AfterEach(func() {
+		gomockCtrl.Finish()
+	})
+
+	Context("RetrieveAmenities", func() {
+		When("the catalog is empty", func() {
+			It("should return http.StatusOK with an empty array", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/amenities", nil)
+				mockAmenityRepo.EXPECT().RetrieveAmenities(gomock.Any()).Return([]a.Model{}, nil)
+
+				presenter.RetrieveAmenities(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"amenities":[]}`))
+			})
+		})
+	})
+
+	Context("CreateAmenity", func() {
+		newRequest := func(body string) *http.Request {
+			request, _ := http.NewRequest(http.MethodPost, "/amenities", strings.NewReader(body))
+			return request
+		}
+
+		When("the amenity is valid", func() {
+			It("should return http.StatusCreated with its location", func() {
+				mockContext.Request = newRequest(`{"slug":"pets","name":"Pet friendly"}`)
+				mockAmenityRepo.EXPECT().CreateAmenity(gomock.Any(), a.Input{Slug: "pets", Name: "Pet friendly"}).
+					Return(a.Model{ID: 2, Slug: "pets", Name: "Pet friendly"}, nil)
+
+				presenter.CreateAmenity(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusCreated))
+				Expect(recorder.Header().Get("Location")).To(Equal("/amenities/2"))
+
+				var response amenities.AmenityResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response).To(Equal(amenities.AmenityResponse{ID: 2, Slug: "pets", Name: "Pet friendly"}))
+			})
+		})
+
+		DescribeTable("creating the amenity in repository fails with a known error class",
+			func(repoErr error, expectedStatus int, expectedCode string) {
+				mockContext.Request = newRequest(`{"slug":"pets","name":"Pet friendly"}`)
+				mockAmenityRepo.EXPECT().CreateAmenity(gomock.Any(), gomock.Any()).Return(a.Model{}, repoErr)
+
+				presenter.CreateAmenity(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(expectedStatus))
+
+				var response api.ErrorResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Error.Code).To(Equal(expectedCode))
+			},
+			Entry("duplicate slug", postgres.ErrConflict, http.StatusConflict, api.CodeConflict),
+			Entry("invalid amenity", &postgres.ValidationError{Kind: a.ErrInvalidAmenity,
+				Errors: []postgres.FieldError{{Field: "name", Reason: postgres.ReasonInvalidValue, Message: "must not be empty"}}},
+				http.StatusBadRequest, api.CodeValidationFailed),
+			Entry("unavailable database", postgres.ErrUnavailable, http.StatusServiceUnavailable, api.CodeServiceUnavailable),
+		)
+	})
+
+	Context("PatchAmenity", func() {
+		When("only the name is provided", func() {
+			It("should patch only the name", func() {
+				name := "Full kitchen"
+				mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
+				mockContext.Request, _ = http.NewRequest(http.MethodPatch, "/amenities/1", strings.NewReader(`{"name":"Full kitchen"}`))
+				mockAmenityRepo.EXPECT().PatchAmenity(gomock.Any(), 1, a.Patch{Name: &name}).
+					Return(a.Model{ID: 1, Slug: "kitchen", Name: name}, nil)
+
+				presenter.PatchAmenity(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("DeleteAmenity", func() {
+		When("the amenity does not exist", func() {
+			It("should return http.StatusNotFound", func() {
+				mockContext.Params = []gin.Param{{Key: "id", Value: "9"}}
+				mockContext.Request, _ = http.NewRequest(http.MethodDelete, "/amenities/9", nil)
+				mockAmenityRepo.EXPECT().DeleteAmenity(gomock.Any(), 9).Return(postgres.ErrNotFound)
+
+				presenter.DeleteAmenity(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Context("ReplaceRentalAmenities", func() {
+		newRequest := func(body string) *http.Request {
+			request, _ := http.NewRequest(http.MethodPut, "/rentals/1/amenities", strings.NewReader(body))
+			return request
+		}
+
+		BeforeEach(func() {
+			mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
+		})
+
+		When("every amenity is in the catalog", func() {
+			It("should return http.StatusOK with the amenities of the rental", func() {
+				mockContext.Request = newRequest(`{"amenities":["pets","kitchen"]}`)
+				mockAmenityRepo.EXPECT().ReplaceRentalAmenities(gomock.Any(), 1, []string{"pets", "kitchen"}).
+					Return([]a.Model{{ID: 1, Slug: "kitchen", Name: "Kitchen"}, {ID: 2, Slug: "pets", Name: "Pet friendly"}}, nil)
+
+				presenter.ReplaceRentalAmenities(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+
+				var response amenities.AmenitiesResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Amenities).To(HaveLen(2))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			It("should return http.StatusNotFound naming the rental", func() {
+				mockContext.Request = newRequest(`{"amenities":["pets"]}`)
+				mockAmenityRepo.EXPECT().ReplaceRentalAmenities(gomock.Any(), 1, gomock.Any()).Return(nil, postgres.ErrNotFound)
+
+				presenter.ReplaceRentalAmenities(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusNotFound))
+
+				var response api.ErrorResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Error.Message).To(Equal("rental not found"))
+			})
+		})
+
+		When("the body has unknown fields", func() {
+			It("should return http.StatusBadRequest without calling the repository", func() {
+				mockContext.Request = newRequest(`{"tags":["pets"]}`)
+
+				presenter.ReplaceRentalAmenities(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+})
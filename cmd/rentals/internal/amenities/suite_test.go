@@ -0,0 +1,13 @@
+package amenities_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAmenities(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Amenities Suite")
+}
@@ -0,0 +1,100 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/httpx"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=authorization.go --destination mocks/authorization.go --package mocks
+
+type RentalRepository interface {
+	RetrieveRentalByID(ctx context.Context, id int, currency string) (rentals.Model, error)
+}
+
+// Authorizer ties writes to the principal Authenticate put into the request. A request without a principal
+// only reaches it when authentication is disabled, and is let through.
+type Authorizer struct {
+	rentals RentalRepository
+}
+
+// NewAuthorizer is a constructor function, rentals is read uncached so that an owner is never stale
+func NewAuthorizer(rentals RentalRepository) *Authorizer {
+	return &Authorizer{
+		rentals: rentals,
+	}
+}
+
+// RequireRentalOwner aborts with 403 unless the principal acts for the owner of the rental of the id
+// parameter. A malformed id or a missing rental is left to the handler to answer.
+func (a *Authorizer) RequireRentalOwner(ctx *gin.Context) {
+	if _, ok := PrincipalFromContext(ctx); !ok {
+		ctx.Next()
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		ctx.Next()
+		return
+	}
+
+	rental, err := a.rentals.RetrieveRentalByID(ctx, id, "")
+	if errors.Is(err, postgres.ErrNotFound) {
+		ctx.Next()
+		return
+	}
+
+	if err != nil {
+		logrus.Error("failed to retrieve owner of rental: ", err)
+		ctx.AbortWithStatusJSON(httpx.ServiceErrorResponse(err, "failed to authorize request"))
+		return
+	}
+
+	if AuthorizeUser(ctx, rental.UserID) {
+		ctx.Next()
+	}
+}
+
+// RequireService aborts with 403 unless the principal is a service acting for no user, such as for changes
+// to the amenity catalog every rental shares
+func RequireService(ctx *gin.Context) {
+	if AuthorizeService(ctx) {
+		ctx.Next()
+	}
+}
+
+// AuthorizeService reports whether the request is made by a service, and aborts it with 403 when it is not
+func AuthorizeService(ctx *gin.Context) bool {
+	principal, ok := PrincipalFromContext(ctx)
+	if ok && !principal.IsService() {
+		forbid(ctx, principal.Subject, "a service")
+		return false
+	}
+
+	return true
+}
+
+// AuthorizeUser reports whether the request may act for a user, and aborts it with 403 when it may not
+func AuthorizeUser(ctx *gin.Context, userID int) bool {
+	principal, ok := PrincipalFromContext(ctx)
+	if ok && !principal.ActsFor(userID) {
+		forbid(ctx, principal.Subject, "user "+strconv.Itoa(userID))
+		return false
+	}
+
+	return true
+}
+
+func forbid(ctx *gin.Context, subject, actingFor string) {
+	logrus.Warnf("refused %s %s of %s acting for %s", ctx.Request.Method, ctx.Request.URL.Path, subject, actingFor)
+	ctx.AbortWithStatusJSON(http.StatusForbidden, api.NewForbiddenResponse())
+}
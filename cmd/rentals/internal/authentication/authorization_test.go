@@ -0,0 +1,161 @@
+package authentication_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/authentication"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/authentication/mocks"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Authorizer", func() {
+	var (
+		gomockCtrl  *gomock.Controller
+		mockRentals *mocks.MockRentalRepository
+		handler     *gin.Engine
+		principal   *auth.Principal
+		reached     bool
+	)
+
+	userID := func(id int) *int {
+		return &id
+	}
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, nil)
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	expectForbidden := func(recorder *httptest.ResponseRecorder) {
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		errResp := api.ErrorResponse{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+		Expect(errResp).To(Equal(api.NewForbiddenResponse()))
+		Expect(reached).To(BeFalse())
+	}
+
+	BeforeEach(func() {
+		gomockCtrl, _ = gomock.WithContext(context.Background(), GinkgoT())
+		mockRentals = mocks.NewMockRentalRepository(gomockCtrl)
+		principal = nil
+		reached = false
+
+		gin.SetMode(gin.TestMode)
+		handler = gin.New()
+		handler.Use(func(ctx *gin.Context) {
+			if principal != nil {
+				ctx.Request = ctx.Request.WithContext(auth.WithPrincipal(ctx.Request.Context(), *principal))
+			}
+		})
+
+		reach := func(ctx *gin.Context) {
+			reached = true
+			ctx.Status(http.StatusOK)
+		}
+		handler.PUT("/rentals/:id", authentication.NewAuthorizer(mockRentals).RequireRentalOwner, reach)
+		handler.POST("/amenities", authentication.RequireService, reach)
+		handler.POST("/users/:id/bookings", func(ctx *gin.Context) {
+			if authentication.AuthorizeUser(ctx, 3) {
+				reach(ctx)
+			}
+		})
+	})
+
+	AfterEach(func() {
+		gomockCtrl.Finish()
+	})
+
+	Context("RequireRentalOwner", func() {
+		It("should let the owner of the rental through", func() {
+			principal = &auth.Principal{Subject: "3", Method: auth.MethodJWT, UserID: userID(3)}
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(rentals.Model{ID: 1, UserID: 3}, nil)
+
+			Expect(serve(http.MethodPut, "/rentals/1").Code).To(Equal(http.StatusOK))
+		})
+
+		It("should let a service through", func() {
+			principal = &auth.Principal{Subject: "api-key:1", Method: auth.MethodAPIKey}
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(rentals.Model{ID: 1, UserID: 3}, nil)
+
+			Expect(serve(http.MethodPut, "/rentals/1").Code).To(Equal(http.StatusOK))
+		})
+
+		It("should return http.StatusForbidden to another user", func() {
+			principal = &auth.Principal{Subject: "api-key:2", Method: auth.MethodAPIKey, UserID: userID(4)}
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(rentals.Model{ID: 1, UserID: 3}, nil)
+
+			expectForbidden(serve(http.MethodPut, "/rentals/1"))
+		})
+
+		It("should return http.StatusForbidden to a token of a subject that is no user", func() {
+			principal = &auth.Principal{Subject: "billing", Method: auth.MethodJWT}
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(rentals.Model{ID: 1, UserID: 3}, nil)
+
+			expectForbidden(serve(http.MethodPut, "/rentals/1"))
+		})
+
+		It("should leave a missing rental to the handler", func() {
+			principal = &auth.Principal{Subject: "4", Method: auth.MethodJWT, UserID: userID(4)}
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(rentals.Model{}, postgres.ErrNotFound)
+
+			Expect(serve(http.MethodPut, "/rentals/1").Code).To(Equal(http.StatusOK))
+		})
+
+		It("should leave a malformed id to the handler without looking it up", func() {
+			principal = &auth.Principal{Subject: "4", Method: auth.MethodJWT, UserID: userID(4)}
+
+			Expect(serve(http.MethodPut, "/rentals/abc").Code).To(Equal(http.StatusOK))
+		})
+
+		It("should return http.StatusServiceUnavailable when the owner cannot be looked up", func() {
+			principal = &auth.Principal{Subject: "3", Method: auth.MethodJWT, UserID: userID(3)}
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").
+				Return(rentals.Model{}, fmt.Errorf("%w: connection refused", postgres.ErrUnavailable))
+
+			Expect(serve(http.MethodPut, "/rentals/1").Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(reached).To(BeFalse())
+		})
+
+		It("should let anonymous requests through when authentication is disabled", func() {
+			Expect(serve(http.MethodPut, "/rentals/1").Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("RequireService", func() {
+		It("should let a service through", func() {
+			principal = &auth.Principal{Subject: "api-key:1", Method: auth.MethodAPIKey}
+			Expect(serve(http.MethodPost, "/amenities").Code).To(Equal(http.StatusOK))
+		})
+
+		It("should return http.StatusForbidden to a user", func() {
+			principal = &auth.Principal{Subject: "api-key:2", Method: auth.MethodAPIKey, UserID: userID(3)}
+			expectForbidden(serve(http.MethodPost, "/amenities"))
+		})
+	})
+
+	Context("AuthorizeUser", func() {
+		It("should let the user through", func() {
+			principal = &auth.Principal{Subject: "3", Method: auth.MethodJWT, UserID: userID(3)}
+			Expect(serve(http.MethodPost, "/users/3/bookings").Code).To(Equal(http.StatusOK))
+		})
+
+		It("should return http.StatusForbidden to another user", func() {
+			principal = &auth.Principal{Subject: "4", Method: auth.MethodJWT, UserID: userID(4)}
+			expectForbidden(serve(http.MethodPost, "/users/3/bookings"))
+		})
+	})
+})
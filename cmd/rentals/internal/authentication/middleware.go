@@ -0,0 +1,131 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/apikeys"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=middleware.go --destination mocks/middleware.go --package mocks
+
+// APIKeyHeader carries an api key, JWTs are sent as Authorization: Bearer <token>
+const APIKeyHeader = "X-API-Key"
+
+type TokenVerifier interface {
+	Verify(token string) (auth.Principal, error)
+}
+
+type APIKeyRepository interface {
+	RetrieveActiveKeyByHash(ctx context.Context, hash string) (apikeys.Model, error)
+}
+
+// errMissingCredentials is returned when a request carries no credential, or more than one
+var errMissingCredentials = errors.New("missing credentials")
+
+type Middleware struct {
+	verifier TokenVerifier
+	apiKeys  APIKeyRepository
+}
+
+// NewMiddleware is a constructor function, a nil verifier rejects every JWT and nil apiKeys every api key
+func NewMiddleware(verifier TokenVerifier, apiKeys APIKeyRepository) *Middleware {
+	return &Middleware{
+		verifier: verifier,
+		apiKeys:  apiKeys,
+	}
+}
+
+// Authenticate puts the principal of a request into its context, see PrincipalFromContext,
+// and aborts with 401 when the request has no valid credential
+func (m *Middleware) Authenticate(ctx *gin.Context) {
+	principal, err := m.authenticate(ctx)
+	if err != nil {
+		status, response := toErrorResponse(err)
+		if status == http.StatusUnauthorized {
+			logrus.Warnf("rejected credentials of %s %s: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+			ctx.Header("WWW-Authenticate", api.BearerChallenge)
+		} else {
+			logrus.Error("failed to authenticate request: ", err)
+		}
+
+		ctx.AbortWithStatusJSON(status, response)
+		return
+	}
+
+	ctx.Request = ctx.Request.WithContext(auth.WithPrincipal(ctx.Request.Context(), principal))
+	ctx.Next()
+}
+
+// PrincipalFromContext returns the principal Authenticate put into the request
+func PrincipalFromContext(ctx *gin.Context) (auth.Principal, bool) {
+	return auth.PrincipalFromContext(ctx.Request.Context())
+}
+
+func (m *Middleware) authenticate(ctx *gin.Context) (auth.Principal, error) {
+	authorization := ctx.GetHeader("Authorization")
+	apiKey := ctx.GetHeader(APIKeyHeader)
+	switch {
+	case authorization != "" && apiKey != "":
+		return auth.Principal{}, fmt.Errorf("%w: both Authorization and %s are set", errMissingCredentials, APIKeyHeader)
+	case authorization != "":
+		return m.verifyToken(authorization)
+	case apiKey != "":
+		return m.verifyAPIKey(ctx, apiKey)
+	default:
+		return auth.Principal{}, errMissingCredentials
+	}
+}
+
+func (m *Middleware) verifyToken(authorization string) (auth.Principal, error) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return auth.Principal{}, fmt.Errorf("%w: Authorization is not a bearer token", errMissingCredentials)
+	}
+
+	if m.verifier == nil {
+		return auth.Principal{}, fmt.Errorf("%w: no JWKS is configured", auth.ErrInvalidToken)
+	}
+
+	return m.verifier.Verify(strings.TrimSpace(token))
+}
+
+func (m *Middleware) verifyAPIKey(ctx context.Context, apiKey string) (auth.Principal, error) {
+	if m.apiKeys == nil {
+		return auth.Principal{}, fmt.Errorf("%w: api keys need the postgres repository", errMissingCredentials)
+	}
+
+	if !strings.HasPrefix(apiKey, auth.APIKeyPrefix) {
+		return auth.Principal{}, fmt.Errorf("%w: api key does not start with %s", errMissingCredentials, auth.APIKeyPrefix)
+	}
+
+	key, err := m.apiKeys.RetrieveActiveKeyByHash(ctx, auth.HashAPIKey(apiKey))
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	return auth.Principal{Subject: "api-key:" + strconv.Itoa(key.ID), Method: auth.MethodAPIKey, UserID: key.UserID}, nil
+}
+
+// toErrorResponse answers every rejected credential alike, only failures to look one up are not a 401
+func toErrorResponse(err error) (int, api.ErrorResponse) {
+	switch {
+	case errors.Is(err, errMissingCredentials), errors.Is(err, auth.ErrInvalidToken), errors.Is(err, postgres.ErrNotFound):
+		return http.StatusUnauthorized, api.NewUnauthorizedResponse()
+	case errors.Is(err, postgres.ErrUnavailable):
+		return http.StatusServiceUnavailable, api.NewErrorResponse(api.CodeServiceUnavailable, "service is temporarily unavailable")
+	case errors.Is(err, postgres.ErrTimeout):
+		return http.StatusGatewayTimeout, api.NewErrorResponse(api.CodeTimeout, "request timed out")
+	default:
+		return http.StatusInternalServerError, api.NewErrorResponse(api.CodeInternal, "failed to authenticate request")
+	}
+}
@@ -0,0 +1,135 @@
+package authentication_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/authentication"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/authentication/mocks"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/apikeys"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const apiKey = auth.APIKeyPrefix + "secret"
+
+var _ = Describe("Middleware", func() {
+	var (
+		gomockCtrl   *gomock.Controller
+		mockVerifier *mocks.MockTokenVerifier
+		mockAPIKeys  *mocks.MockAPIKeyRepository
+		handler      *gin.Engine
+		principal    *auth.Principal
+	)
+
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/rentals", nil)
+		if header != "" {
+			request.Header.Set(header, value)
+		}
+
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	expectUnauthorized := func(recorder *httptest.ResponseRecorder) {
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal(api.BearerChallenge))
+		errResp := api.ErrorResponse{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+		Expect(errResp).To(Equal(api.NewUnauthorizedResponse()))
+		Expect(principal).To(BeNil())
+	}
+
+	BeforeEach(func() {
+		gomockCtrl, _ = gomock.WithContext(context.Background(), GinkgoT())
+		mockVerifier = mocks.NewMockTokenVerifier(gomockCtrl)
+		mockAPIKeys = mocks.NewMockAPIKeyRepository(gomockCtrl)
+		principal = nil
+
+		gin.SetMode(gin.TestMode)
+		handler = gin.New()
+		handler.Use(authentication.NewMiddleware(mockVerifier, mockAPIKeys).Authenticate)
+		handler.GET("/rentals", func(ctx *gin.Context) {
+			authenticated, ok := authentication.PrincipalFromContext(ctx)
+			Expect(ok).To(BeTrue())
+			principal = &authenticated
+			ctx.Status(http.StatusOK)
+		})
+	})
+
+	AfterEach(func() {
+		gomockCtrl.Finish()
+	})
+
+	It("should put the principal of a valid bearer token into the request context", func() {
+		mockVerifier.EXPECT().Verify("token").Return(auth.Principal{Subject: "user-42", Method: auth.MethodJWT}, nil)
+
+		Expect(serve("Authorization", "bearer token").Code).To(Equal(http.StatusOK))
+		Expect(*principal).To(Equal(auth.Principal{Subject: "user-42", Method: auth.MethodJWT}))
+	})
+
+	It("should look an api key up by its hash", func() {
+		userID := 3
+		mockAPIKeys.EXPECT().RetrieveActiveKeyByHash(gomock.Any(), auth.HashAPIKey(apiKey)).
+			Return(apikeys.Model{ID: 4, Name: "ci", UserID: &userID}, nil)
+
+		Expect(serve(authentication.APIKeyHeader, apiKey).Code).To(Equal(http.StatusOK))
+		Expect(*principal).To(Equal(auth.Principal{Subject: "api-key:4", Method: auth.MethodAPIKey, UserID: &userID}))
+	})
+
+	It("should reject a request without credentials", func() {
+		expectUnauthorized(serve("", ""))
+	})
+
+	It("should reject an invalid bearer token", func() {
+		mockVerifier.EXPECT().Verify("token").Return(auth.Principal{}, fmt.Errorf("%w: token is expired", auth.ErrInvalidToken))
+		expectUnauthorized(serve("Authorization", "Bearer token"))
+	})
+
+	It("should reject other authorization schemes", func() {
+		expectUnauthorized(serve("Authorization", "Basic dXNlcjpwYXNz"))
+	})
+
+	It("should reject an unknown or revoked api key", func() {
+		mockAPIKeys.EXPECT().RetrieveActiveKeyByHash(gomock.Any(), auth.HashAPIKey(apiKey)).
+			Return(apikeys.Model{}, postgres.ErrNotFound)
+		expectUnauthorized(serve(authentication.APIKeyHeader, apiKey))
+	})
+
+	It("should reject an api key without prefix without looking it up", func() {
+		expectUnauthorized(serve(authentication.APIKeyHeader, "secret"))
+	})
+
+	It("should return http.StatusServiceUnavailable when api keys cannot be looked up", func() {
+		mockAPIKeys.EXPECT().RetrieveActiveKeyByHash(gomock.Any(), gomock.Any()).
+			Return(apikeys.Model{}, postgres.ErrUnavailable)
+
+		recorder := serve(authentication.APIKeyHeader, apiKey)
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(principal).To(BeNil())
+	})
+
+	When("neither JWKS nor api keys are configured", func() {
+		BeforeEach(func() {
+			handler = gin.New()
+			handler.Use(authentication.NewMiddleware(nil, nil).Authenticate)
+			handler.GET("/rentals", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+		})
+
+		It("should reject every credential", func() {
+			expectUnauthorized(serve("Authorization", "Bearer token"))
+			expectUnauthorized(serve(authentication.APIKeyHeader, apiKey))
+		})
+	})
+})
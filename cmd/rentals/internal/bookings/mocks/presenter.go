@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presenter.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	bookings "github.com/nvasilev98/rentals/pkg/repository/postgres/bookings"
+)
+
+// MockBookingRepository is a mock of BookingRepository interface.
+type MockBookingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBookingRepositoryMockRecorder
+}
+
+// MockBookingRepositoryMockRecorder is the mock recorder for MockBookingRepository.
+type MockBookingRepositoryMockRecorder struct {
+	mock *MockBookingRepository
+}
+
+// NewMockBookingRepository creates a new mock instance.
+func NewMockBookingRepository(ctrl *gomock.Controller) *MockBookingRepository {
+	mock := &MockBookingRepository{ctrl: ctrl}
+	mock.recorder = &MockBookingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBookingRepository) EXPECT() *MockBookingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateBooking mocks base method.
+func (m *MockBookingRepository) CreateBooking(ctx context.Context, input bookings.Input) (bookings.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBooking", ctx, input)
+	ret0, _ := ret[0].(bookings.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBooking indicates an expected call of CreateBooking.
+func (mr *MockBookingRepositoryMockRecorder) CreateBooking(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBooking", reflect.TypeOf((*MockBookingRepository)(nil).CreateBooking), ctx, input)
+}
+
+// RetrieveAvailability mocks base method.
+func (m *MockBookingRepository) RetrieveAvailability(ctx context.Context, rentalID int, window bookings.Window) (bookings.Availability, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveAvailability", ctx, rentalID, window)
+	ret0, _ := ret[0].(bookings.Availability)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveAvailability indicates an expected call of RetrieveAvailability.
+func (mr *MockBookingRepositoryMockRecorder) RetrieveAvailability(ctx, rentalID, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveAvailability", reflect.TypeOf((*MockBookingRepository)(nil).RetrieveAvailability), ctx, rentalID, window)
+}
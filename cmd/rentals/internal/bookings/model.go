@@ -0,0 +1,37 @@
+package bookings
+
+import "time"
+
+// BookingRequest is the body of POST /rentals/:id/bookings, dates are in YYYY-MM-DD format
+// and the rental is taken from the night of from up to but excluding to
+type BookingRequest struct {
+	UserID int    `json:"user_id"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+type BookingResponse struct {
+	ID       int       `json:"id"`
+	RentalID int       `json:"rental_id"`
+	UserID   int       `json:"user_id"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Nights   int       `json:"nights"`
+	Created  time.Time `json:"created"`
+}
+
+type AvailabilityResponse struct {
+	RentalID  int              `json:"rental_id"`
+	From      string           `json:"from"`
+	To        string           `json:"to"`
+	Available bool             `json:"available"`
+	Booked    []PeriodResponse `json:"booked"`
+	Free      []PeriodResponse `json:"free"`
+}
+
+// PeriodResponse is a half-open range of nights [from, to)
+type PeriodResponse struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Nights int    `json:"nights"`
+}
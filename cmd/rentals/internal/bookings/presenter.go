@@ -0,0 +1,167 @@
+package bookings
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/authentication"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/httpx"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/bookings"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=presenter.go --destination mocks/presenter.go --package mocks
+
+// bookingErrors answers the errors of the booking repository, a missing rental is what is not found
+var bookingErrors = httpx.Errors{
+	Resource: "rental",
+	Conflict: "rental is already booked for some of the requested nights",
+	Invalid:  "invalid booking",
+	Query:    bookings.ErrInvalidWindow,
+}
+
+type BookingRepository interface {
+	CreateBooking(ctx context.Context, input bookings.Input) (bookings.Model, error)
+	RetrieveAvailability(ctx context.Context, rentalID int, window bookings.Window) (bookings.Availability, error)
+}
+
+type Presenter struct {
+	bookingRepository BookingRepository
+}
+
+// NewPresenter is a constructor function
+func NewPresenter(bookingRepository BookingRepository) *Presenter {
+	return &Presenter{
+		bookingRepository: bookingRepository,
+	}
+}
+
+// CreateBooking books a rental by a given id for the nights in the request body
+func (p *Presenter) CreateBooking(ctx *gin.Context) {
+	rentalID, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	var request BookingRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	if !authentication.AuthorizeUser(ctx, request.UserID) {
+		return
+	}
+
+	input, err := toBookingInput(rentalID, request)
+	if err != nil {
+		ctx.JSON(bookingErrors.Response(err, "invalid booking"))
+		return
+	}
+
+	booking, err := p.bookingRepository.CreateBooking(ctx, input)
+	if err != nil {
+		logrus.Error("failed to create booking in repository: ", err)
+		ctx.JSON(bookingErrors.Response(err, "failed to create booking"))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toBookingResponse(booking))
+}
+
+// RetrieveAvailability retrieves the booked and the free nights of a rental by a given id within the from and to window
+func (p *Presenter) RetrieveAvailability(ctx *gin.Context) {
+	rentalID, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	window, err := bookings.ParseWindow(ctx.Request.URL.Query())
+	if err != nil {
+		logrus.Error("failed to parse availability window: ", err)
+		ctx.JSON(bookingErrors.Response(err, "invalid query parameters"))
+		return
+	}
+
+	availability, err := p.bookingRepository.RetrieveAvailability(ctx, rentalID, window)
+	if err != nil {
+		logrus.Error("failed to retrieve availability from repository: ", err)
+		ctx.JSON(bookingErrors.Response(err, "failed to retrieve availability"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAvailabilityResponse(availability))
+}
+
+// toBookingInput parses the dates of the request, missing dates are left zero for Input.Validate to report
+func toBookingInput(rentalID int, request BookingRequest) (bookings.Input, error) {
+	var errs []postgres.FieldError
+	date := func(field, value string) time.Time {
+		if value == "" {
+			return time.Time{}
+		}
+
+		parsed, err := bookings.ParseDate(value)
+		if err != nil {
+			errs = append(errs, postgres.FieldError{Field: field, Reason: postgres.ReasonInvalidValue,
+				Message: "must be a date in YYYY-MM-DD format"})
+		}
+
+		return parsed
+	}
+
+	input := bookings.Input{
+		RentalID: rentalID,
+		UserID:   request.UserID,
+		From:     date("from", request.From),
+		To:       date("to", request.To),
+	}
+
+	if len(errs) > 0 {
+		return bookings.Input{}, &postgres.ValidationError{Errors: errs, Kind: bookings.ErrInvalidBooking}
+	}
+
+	return input, nil
+}
+
+func toBookingResponse(booking bookings.Model) BookingResponse {
+	return BookingResponse{
+		ID:       booking.ID,
+		RentalID: booking.RentalID,
+		UserID:   booking.UserID,
+		From:     booking.From.Format(bookings.DateLayout),
+		To:       booking.To.Format(bookings.DateLayout),
+		Nights:   nights(booking.From, booking.To),
+		Created:  booking.Created,
+	}
+}
+
+func toAvailabilityResponse(availability bookings.Availability) AvailabilityResponse {
+	return AvailabilityResponse{
+		RentalID:  availability.RentalID,
+		From:      availability.Window.From.Format(bookings.DateLayout),
+		To:        availability.Window.To.Format(bookings.DateLayout),
+		Available: availability.Available(),
+		Booked:    toPeriodsResponse(availability.Booked),
+		Free:      toPeriodsResponse(availability.Free),
+	}
+}
+
+func toPeriodsResponse(periods []bookings.Period) []PeriodResponse {
+	response := make([]PeriodResponse, 0, len(periods))
+	for _, period := range periods {
+		response = append(response, PeriodResponse{
+			From:   period.From.Format(bookings.DateLayout),
+			To:     period.To.Format(bookings.DateLayout),
+			Nights: nights(period.From, period.To),
+		})
+	}
+
+	return response
+}
+
+func nights(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -0,0 +1,172 @@
+package bookings_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/bookings"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/bookings/mocks"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	b "github.com/nvasilev98/rentals/pkg/repository/postgres/bookings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func date(day int) time.Time {
+	return time.Date(2024, 6, day, 0, 0, 0, 0, time.UTC)
+}
+
+var _ = Describe("Presenter", func() {
+	var (
+		gomockCtrl      *gomock.Controller
+		mockBookingRepo *mocks.MockBookingRepository
+		presenter       *bookings.Presenter
+		recorder        *httptest.ResponseRecorder
+		mockContext     *gin.Context
+	)
+
+	BeforeEach(func() {
+		gomockCtrl, _ = gomock.WithContext(context.Background(), GinkgoT())
+		mockBookingRepo = mocks.NewMockBookingRepository(gomockCtrl)
+		presenter = bookings.NewPresenter(mockBookingRepo)
+		recorder = httptest.NewRecorder()
+		mockContext, _ = gin.CreateTestContext(recorder)
+		mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
+	})
+
+	AfterEach(func() {
+		gomockCtrl.Finish()
+	})
+
+	Context("CreateBooking", func() {
+		newRequest := func(body string) *http.Request {
+			request, _ := http.NewRequest(http.MethodPost, "/rentals/1/bookings", strings.NewReader(body))
+			return request
+		}
+
+		When("the booking is for another user than the credentials act for", func() {
+			It("should return http.StatusForbidden without calling the repository", func() {
+				userID := 3
+				request := newRequest(`{"user_id":2,"from":"2024-06-01","to":"2024-06-05"}`)
+				mockContext.Request = request.WithContext(auth.WithPrincipal(request.Context(),
+					auth.Principal{Subject: "3", Method: auth.MethodJWT, UserID: &userID}))
+
+				presenter.CreateBooking(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		When("the rental is free", func() {
+			It("should return http.StatusCreated and the booking", func() {
+				mockContext.Request = newRequest(`{"user_id":2,"from":"2024-06-01","to":"2024-06-05"}`)
+				mockBookingRepo.EXPECT().CreateBooking(gomock.Any(), b.Input{RentalID: 1, UserID: 2, From: date(1), To: date(5)}).
+					Return(b.Model{ID: 9, RentalID: 1, UserID: 2, From: date(1), To: date(5)}, nil)
+
+				presenter.CreateBooking(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusCreated))
+
+				var response bookings.BookingResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.ID).To(Equal(9))
+				Expect(response.From).To(Equal("2024-06-01"))
+				Expect(response.To).To(Equal("2024-06-05"))
+				Expect(response.Nights).To(Equal(4))
+			})
+		})
+
+		When("a date is malformed", func() {
+			It("should return http.StatusBadRequest without calling the repository", func() {
+				mockContext.Request = newRequest(`{"user_id":2,"from":"June 1","to":"2024-06-05"}`)
+
+				presenter.CreateBooking(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+
+				var response api.ErrorResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Error.Code).To(Equal(api.CodeValidationFailed))
+				Expect(response.Error.Details[0].Field).To(Equal("from"))
+			})
+		})
+
+		When("the body has unknown fields", func() {
+			It("should return http.StatusBadRequest without calling the repository", func() {
+				mockContext.Request = newRequest(`{"user_id":2,"nights":3}`)
+
+				presenter.CreateBooking(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		DescribeTable("creating the booking in repository fails with a known error class",
+			func(repoErr error, expectedStatus int, expectedCode string) {
+				mockContext.Request = newRequest(`{"user_id":2,"from":"2024-06-01","to":"2024-06-05"}`)
+				mockBookingRepo.EXPECT().CreateBooking(gomock.Any(), gomock.Any()).Return(b.Model{}, repoErr)
+
+				presenter.CreateBooking(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(expectedStatus))
+
+				var response api.ErrorResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Error.Code).To(Equal(expectedCode))
+			},
+			Entry("overlapping booking", postgres.ErrConflict, http.StatusConflict, api.CodeConflict),
+			Entry("rental not found", postgres.ErrNotFound, http.StatusNotFound, api.CodeNotFound),
+			Entry("invalid booking", &postgres.ValidationError{Kind: b.ErrInvalidBooking,
+				Errors: []postgres.FieldError{{Field: "to", Reason: postgres.ReasonOutOfRange, Message: "must be after from"}}},
+				http.StatusBadRequest, api.CodeValidationFailed),
+			Entry("unavailable database", postgres.ErrUnavailable, http.StatusServiceUnavailable, api.CodeServiceUnavailable),
+		)
+	})
+
+	Context("RetrieveAvailability", func() {
+		When("the window is valid", func() {
+			It("should return http.StatusOK with the booked and the free nights", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/availability?from=2024-06-01&to=2024-06-10", nil)
+				mockBookingRepo.EXPECT().RetrieveAvailability(gomock.Any(), 1, b.Window{From: date(1), To: date(10)}).
+					Return(b.Availability{
+						RentalID: 1,
+						Window:   b.Window{From: date(1), To: date(10)},
+						Booked:   []b.Period{{From: date(3), To: date(5)}},
+						Free:     []b.Period{{From: date(1), To: date(3)}, {From: date(5), To: date(10)}},
+					}, nil)
+
+				presenter.RetrieveAvailability(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+
+				var response bookings.AvailabilityResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Available).To(BeFalse())
+				Expect(response.Booked).To(Equal([]bookings.PeriodResponse{{From: "2024-06-03", To: "2024-06-05", Nights: 2}}))
+				Expect(response.Free).To(HaveLen(2))
+			})
+		})
+
+		When("the window is missing", func() {
+			It("should return http.StatusBadRequest without calling the repository", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/availability", nil)
+
+				presenter.RetrieveAvailability(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			It("should return http.StatusNotFound", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/availability?from=2024-06-01&to=2024-06-10", nil)
+				mockBookingRepo.EXPECT().RetrieveAvailability(gomock.Any(), 1, gomock.Any()).Return(b.Availability{}, postgres.ErrNotFound)
+
+				presenter.RetrieveAvailability(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+})
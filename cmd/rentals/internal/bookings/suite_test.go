@@ -0,0 +1,13 @@
+package bookings_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBookings(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bookings Suite")
+}
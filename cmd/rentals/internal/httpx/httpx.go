@@ -0,0 +1,116 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+// ParseID reads the id path parameter and responds with 400 when it is not a positive integer
+func ParseID(ctx *gin.Context) (int, bool) {
+	rawID := ctx.Param("id")
+	if rawID == "" {
+		ctx.JSON(http.StatusBadRequest, api.NewErrorResponse(api.CodeInvalidParameter, "missing id parameter"))
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(rawID)
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error: api.NewFieldError(api.CodeInvalidParameter, "id", "id must be a positive integer"),
+		})
+		return 0, false
+	}
+
+	return id, true
+}
+
+// DecodeBody decodes a JSON request body rejecting unknown fields and responds with 400 when it fails
+func DecodeBody(ctx *gin.Context, request interface{}) bool {
+	decoder := json.NewDecoder(ctx.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		ctx.JSON(http.StatusBadRequest, api.NewErrorResponse(api.CodeInvalidBody, fmt.Sprintf("invalid request body: %s", err)))
+		return false
+	}
+
+	return true
+}
+
+// Errors holds what a presenter tells about its resource when a repository fails
+type Errors struct {
+	// Resource names what is not found or was changed since it was read, e.g. rental
+	Resource string
+	// Conflict is the message of a postgres.ErrConflict
+	Conflict string
+	// Invalid is the message of a *postgres.ValidationError of the request body
+	Invalid string
+	// Query is the kind of a *postgres.ValidationError of the query parameters
+	Query error
+}
+
+// Response maps a repository error class to an http status code and a coded error response,
+// message is used for errors of no known class
+func (e Errors) Response(err error, message string) (int, api.ErrorResponse) {
+	var validationErr *postgres.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		invalid := e.Invalid
+		if e.Query != nil && errors.Is(validationErr, e.Query) {
+			invalid = "invalid query parameters"
+		}
+
+		return http.StatusBadRequest, ValidationErrorResponse(validationErr, invalid)
+	case errors.Is(err, postgres.ErrNotFound):
+		return http.StatusNotFound, api.NewErrorResponse(api.CodeNotFound, e.Resource+" not found")
+	case errors.Is(err, postgres.ErrConflict):
+		return http.StatusConflict, api.NewErrorResponse(api.CodeConflict, e.Conflict)
+	case errors.Is(err, postgres.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, api.NewErrorResponse(api.CodePreconditionFailed, e.Resource+" was changed since it was read")
+	case errors.Is(err, postgres.ErrInvalidInput):
+		return http.StatusBadRequest, api.NewErrorResponse(api.CodeInvalidParameter, "invalid input")
+	default:
+		return ServiceErrorResponse(err, message)
+	}
+}
+
+// ServiceErrorResponse maps an error of the database being unreachable or slow to 503 or 504,
+// any other error is answered with 500 and message
+func ServiceErrorResponse(err error, message string) (int, api.ErrorResponse) {
+	switch {
+	case errors.Is(err, postgres.ErrUnavailable):
+		return http.StatusServiceUnavailable, api.NewErrorResponse(api.CodeServiceUnavailable, "service is temporarily unavailable")
+	case errors.Is(err, postgres.ErrTimeout):
+		return http.StatusGatewayTimeout, api.NewErrorResponse(api.CodeTimeout, "request timed out")
+	default:
+		return http.StatusInternalServerError, api.NewErrorResponse(api.CodeInternal, message)
+	}
+}
+
+// ValidationErrorResponse lists every invalid field of a validation error under message
+func ValidationErrorResponse(validationErr *postgres.ValidationError, message string) api.ErrorResponse {
+	details := make([]api.Error, 0, len(validationErr.Errors))
+	for _, fieldErr := range validationErr.Errors {
+		details = append(details, api.NewFieldError(ErrorCode(fieldErr.Reason), fieldErr.Field, fieldErr.Message))
+	}
+
+	return api.NewValidationErrorResponse(message, details)
+}
+
+// ErrorCode maps the reason of a field error to an api error code
+func ErrorCode(reason postgres.Reason) string {
+	switch reason {
+	case postgres.ReasonOutOfRange:
+		return api.CodeOutOfRange
+	case postgres.ReasonUnknownParameter:
+		return api.CodeUnknownParameter
+	default:
+		return api.CodeInvalidParameter
+	}
+}
@@ -0,0 +1,119 @@
+package httpx_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/httpx"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Httpx", func() {
+	newContext := func(body string) (*gin.Context, *httptest.ResponseRecorder) {
+		gin.SetMode(gin.TestMode)
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		return ctx, recorder
+	}
+
+	DescribeTable("ParseID",
+		func(rawID string, expectedID int, expectedOK bool) {
+			ctx, recorder := newContext("")
+			ctx.Params = gin.Params{{Key: "id", Value: rawID}}
+
+			id, ok := httpx.ParseID(ctx)
+			Expect(id).To(Equal(expectedID))
+			Expect(ok).To(Equal(expectedOK))
+			if !ok {
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			}
+		},
+		Entry("positive id", "7", 7, true),
+		Entry("missing id", "", 0, false),
+		Entry("non-numeric id", "abc", 0, false),
+		Entry("zero id", "0", 0, false),
+		Entry("negative id", "-1", 0, false),
+	)
+
+	Context("DecodeBody", func() {
+		type request struct {
+			Name string `json:"name"`
+		}
+
+		It("should decode a known body", func() {
+			ctx, _ := newContext(`{"name":"cabin"}`)
+
+			var decoded request
+			Expect(httpx.DecodeBody(ctx, &decoded)).To(BeTrue())
+			Expect(decoded).To(Equal(request{Name: "cabin"}))
+		})
+
+		It("should respond with http.StatusBadRequest to an unknown field", func() {
+			ctx, recorder := newContext(`{"name":"cabin","price":1}`)
+
+			var decoded request
+			Expect(httpx.DecodeBody(ctx, &decoded)).To(BeFalse())
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(api.CodeInvalidBody))
+		})
+	})
+
+	Context("Errors", func() {
+		errInvalidFilter := errors.New("invalid filter")
+		rentalErrors := httpx.Errors{
+			Resource: "rental",
+			Conflict: "rental conflicts with its current state",
+			Invalid:  "invalid rental",
+			Query:    errInvalidFilter,
+		}
+
+		DescribeTable("should map a repository error class",
+			func(err error, expectedStatus int, expectedResp api.ErrorResponse) {
+				status, errResp := rentalErrors.Response(err, "failed to retrieve rental")
+				Expect(status).To(Equal(expectedStatus))
+				Expect(errResp).To(Equal(expectedResp))
+			},
+			Entry("not found", fmt.Errorf("%w: rental 1", postgres.ErrNotFound),
+				http.StatusNotFound, api.NewErrorResponse(api.CodeNotFound, "rental not found")),
+			Entry("conflict", postgres.ErrConflict,
+				http.StatusConflict, api.NewErrorResponse(api.CodeConflict, "rental conflicts with its current state")),
+			Entry("precondition failed", postgres.ErrPreconditionFailed,
+				http.StatusPreconditionFailed, api.NewErrorResponse(api.CodePreconditionFailed, "rental was changed since it was read")),
+			Entry("invalid input", postgres.ErrInvalidInput,
+				http.StatusBadRequest, api.NewErrorResponse(api.CodeInvalidParameter, "invalid input")),
+			Entry("unavailable", postgres.ErrUnavailable,
+				http.StatusServiceUnavailable, api.NewErrorResponse(api.CodeServiceUnavailable, "service is temporarily unavailable")),
+			Entry("timeout", postgres.ErrTimeout,
+				http.StatusGatewayTimeout, api.NewErrorResponse(api.CodeTimeout, "request timed out")),
+			Entry("unknown", errors.New("boom"),
+				http.StatusInternalServerError, api.NewErrorResponse(api.CodeInternal, "failed to retrieve rental")),
+			Entry("invalid body", &postgres.ValidationError{Errors: []postgres.FieldError{
+				{Field: "name", Reason: postgres.ReasonInvalidValue, Message: "must not be empty"},
+				{Field: "sleeps", Reason: postgres.ReasonOutOfRange, Message: "must be positive"},
+			}},
+				http.StatusBadRequest, api.NewValidationErrorResponse("invalid rental", []api.Error{
+					api.NewFieldError(api.CodeInvalidParameter, "name", "must not be empty"),
+					api.NewFieldError(api.CodeOutOfRange, "sleeps", "must be positive"),
+				})),
+			Entry("invalid query parameters", &postgres.ValidationError{Errors: []postgres.FieldError{
+				{Field: "colour", Reason: postgres.ReasonUnknownParameter, Message: "is not a known parameter"},
+			}, Kind: errInvalidFilter},
+				http.StatusBadRequest, api.NewValidationErrorResponse("invalid query parameters", []api.Error{
+					api.NewFieldError(api.CodeUnknownParameter, "colour", "is not a known parameter"),
+				})),
+		)
+	})
+})
@@ -0,0 +1,13 @@
+package httpx_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHttpx(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Httpx Suite")
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presenter.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	pricing "github.com/nvasilev98/rentals/pkg/repository/postgres/pricing"
+)
+
+// MockPricingRepository is a mock of PricingRepository interface.
+type MockPricingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPricingRepositoryMockRecorder
+}
+
+// MockPricingRepositoryMockRecorder is the mock recorder for MockPricingRepository.
+type MockPricingRepositoryMockRecorder struct {
+	mock *MockPricingRepository
+}
+
+// NewMockPricingRepository creates a new mock instance.
+func NewMockPricingRepository(ctrl *gomock.Controller) *MockPricingRepository {
+	mock := &MockPricingRepository{ctrl: ctrl}
+	mock.recorder = &MockPricingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPricingRepository) EXPECT() *MockPricingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ReplaceSeasons mocks base method.
+func (m *MockPricingRepository) ReplaceSeasons(ctx context.Context, rentalID int, seasons []pricing.Season) ([]pricing.Season, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceSeasons", ctx, rentalID, seasons)
+	ret0, _ := ret[0].([]pricing.Season)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceSeasons indicates an expected call of ReplaceSeasons.
+func (mr *MockPricingRepositoryMockRecorder) ReplaceSeasons(ctx, rentalID, seasons interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceSeasons", reflect.TypeOf((*MockPricingRepository)(nil).ReplaceSeasons), ctx, rentalID, seasons)
+}
+
+// RetrieveQuote mocks base method.
+func (m *MockPricingRepository) RetrieveQuote(ctx context.Context, rentalID int, trip pricing.Trip) (pricing.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveQuote", ctx, rentalID, trip)
+	ret0, _ := ret[0].(pricing.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveQuote indicates an expected call of RetrieveQuote.
+func (mr *MockPricingRepositoryMockRecorder) RetrieveQuote(ctx, rentalID, trip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveQuote", reflect.TypeOf((*MockPricingRepository)(nil).RetrieveQuote), ctx, rentalID, trip)
+}
+
+// RetrieveSeasons mocks base method.
+func (m *MockPricingRepository) RetrieveSeasons(ctx context.Context, rentalID int) ([]pricing.Season, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveSeasons", ctx, rentalID)
+	ret0, _ := ret[0].([]pricing.Season)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveSeasons indicates an expected call of RetrieveSeasons.
+func (mr *MockPricingRepositoryMockRecorder) RetrieveSeasons(ctx, rentalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveSeasons", reflect.TypeOf((*MockPricingRepository)(nil).RetrieveSeasons), ctx, rentalID)
+}
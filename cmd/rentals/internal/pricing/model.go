@@ -0,0 +1,49 @@
+package pricing
+
+// QuoteResponse is the itemized price of a trip, the rental is taken from the night of start up to but excluding end.
+// Amounts are in the minor unit of currency, the currency of the rental.
+type QuoteResponse struct {
+	RentalID int                `json:"rental_id"`
+	Currency string             `json:"currency"`
+	Start    string             `json:"start"`
+	End      string             `json:"end"`
+	Nights   []NightResponse    `json:"nights"`
+	Items    []LineItemResponse `json:"items"`
+	Total    int                `json:"total"`
+}
+
+type NightResponse struct {
+	Date     string `json:"date"`
+	Price    int    `json:"price"`
+	Seasonal bool   `json:"seasonal"`
+	Weekend  bool   `json:"weekend"`
+}
+
+// LineItemResponse is a single row of a quote, discounts have negative amounts
+type LineItemResponse struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Amount      int    `json:"amount"`
+}
+
+// SeasonsRequest is the body of PUT /rentals/:id/seasons, it replaces every seasonal price of the rental
+type SeasonsRequest struct {
+	Seasons []SeasonRequest `json:"seasons"`
+}
+
+// SeasonRequest replaces the price per day for the nights from from up to but excluding to
+type SeasonRequest struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	PricePerDay int    `json:"price_per_day"`
+}
+
+type SeasonsResponse struct {
+	Seasons []SeasonResponse `json:"seasons"`
+}
+
+type SeasonResponse struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	PricePerDay int    `json:"price_per_day"`
+}
@@ -0,0 +1,183 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/httpx"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/pricing"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=presenter.go --destination mocks/presenter.go --package mocks
+
+// pricingErrors answers the errors of the pricing repository, a missing rental is what is not found
+var pricingErrors = httpx.Errors{
+	Resource: "rental",
+	Conflict: "seasonal prices overlap",
+	Invalid:  "invalid seasonal prices",
+	Query:    pricing.ErrInvalidTrip,
+}
+
+type PricingRepository interface {
+	RetrieveQuote(ctx context.Context, rentalID int, trip pricing.Trip) (pricing.Quote, error)
+	RetrieveSeasons(ctx context.Context, rentalID int) ([]pricing.Season, error)
+	ReplaceSeasons(ctx context.Context, rentalID int, seasons []pricing.Season) ([]pricing.Season, error)
+}
+
+type Presenter struct {
+	pricingRepository PricingRepository
+}
+
+// NewPresenter is a constructor function
+func NewPresenter(pricingRepository PricingRepository) *Presenter {
+	return &Presenter{
+		pricingRepository: pricingRepository,
+	}
+}
+
+// RetrieveQuote retrieves the itemized price of a trip in a rental by a given id
+func (p *Presenter) RetrieveQuote(ctx *gin.Context) {
+	rentalID, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	trip, err := pricing.ParseTrip(ctx.Request.URL.Query())
+	if err != nil {
+		logrus.Error("failed to parse trip: ", err)
+		ctx.JSON(pricingErrors.Response(err, "invalid query parameters"))
+		return
+	}
+
+	quote, err := p.pricingRepository.RetrieveQuote(ctx, rentalID, trip)
+	if err != nil {
+		logrus.Error("failed to retrieve quote from repository: ", err)
+		ctx.JSON(pricingErrors.Response(err, "failed to retrieve quote"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toQuoteResponse(quote))
+}
+
+// RetrieveSeasons retrieves the seasonal prices of a rental by a given id
+func (p *Presenter) RetrieveSeasons(ctx *gin.Context) {
+	rentalID, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	seasons, err := p.pricingRepository.RetrieveSeasons(ctx, rentalID)
+	if err != nil {
+		logrus.Error("failed to retrieve seasonal prices from repository: ", err)
+		ctx.JSON(pricingErrors.Response(err, "failed to retrieve seasonal prices"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toSeasonsResponse(seasons))
+}
+
+// ReplaceSeasons replaces every seasonal price of a rental by a given id with the ones in the request body
+func (p *Presenter) ReplaceSeasons(ctx *gin.Context) {
+	rentalID, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	var request SeasonsRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	seasons, err := toSeasons(request)
+	if err != nil {
+		ctx.JSON(pricingErrors.Response(err, "invalid seasonal prices"))
+		return
+	}
+
+	seasons, err = p.pricingRepository.ReplaceSeasons(ctx, rentalID, seasons)
+	if err != nil {
+		logrus.Error("failed to replace seasonal prices in repository: ", err)
+		ctx.JSON(pricingErrors.Response(err, "failed to replace seasonal prices"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toSeasonsResponse(seasons))
+}
+
+// toSeasons parses the dates of the request, missing dates are left zero for pricing.ValidateSeasons to report
+func toSeasons(request SeasonsRequest) ([]pricing.Season, error) {
+	var errs []postgres.FieldError
+	date := func(field, value string) time.Time {
+		if value == "" {
+			return time.Time{}
+		}
+
+		parsed, err := pricing.ParseDate(value)
+		if err != nil {
+			errs = append(errs, postgres.FieldError{Field: field, Reason: postgres.ReasonInvalidValue,
+				Message: "must be a date in YYYY-MM-DD format"})
+		}
+
+		return parsed
+	}
+
+	seasons := make([]pricing.Season, 0, len(request.Seasons))
+	for i, season := range request.Seasons {
+		seasons = append(seasons, pricing.Season{
+			From:        date(fmt.Sprintf("seasons[%d].from", i), season.From),
+			To:          date(fmt.Sprintf("seasons[%d].to", i), season.To),
+			PricePerDay: season.PricePerDay,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, &postgres.ValidationError{Errors: errs, Kind: pricing.ErrInvalidSeasons}
+	}
+
+	return seasons, nil
+}
+
+func toQuoteResponse(quote pricing.Quote) QuoteResponse {
+	nights := make([]NightResponse, 0, len(quote.Nights))
+	for _, night := range quote.Nights {
+		nights = append(nights, NightResponse{
+			Date:     night.Date.Format(pricing.DateLayout),
+			Price:    night.Price,
+			Seasonal: night.Seasonal,
+			Weekend:  night.Weekend,
+		})
+	}
+
+	items := make([]LineItemResponse, 0, len(quote.Items))
+	for _, item := range quote.Items {
+		items = append(items, LineItemResponse{Type: item.Kind, Description: item.Description, Amount: item.Amount})
+	}
+
+	return QuoteResponse{
+		RentalID: quote.RentalID,
+		Currency: quote.Currency,
+		Start:    quote.Trip.Start.Format(pricing.DateLayout),
+		End:      quote.Trip.End.Format(pricing.DateLayout),
+		Nights:   nights,
+		Items:    items,
+		Total:    quote.Total,
+	}
+}
+
+func toSeasonsResponse(seasons []pricing.Season) SeasonsResponse {
+	response := make([]SeasonResponse, 0, len(seasons))
+	for _, season := range seasons {
+		response = append(response, SeasonResponse{
+			From:        season.From.Format(pricing.DateLayout),
+			To:          season.To.Format(pricing.DateLayout),
+			PricePerDay: season.PricePerDay,
+		})
+	}
+
+	return SeasonsResponse{Seasons: response}
+}
@@ -0,0 +1,139 @@
+package pricing_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/pricing"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/pricing/mocks"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	p "github.com/nvasilev98/rentals/pkg/repository/postgres/pricing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func date(day int) time.Time {
+	return time.Date(2024, 6, day, 0, 0, 0, 0, time.UTC)
+}
+
+var _ = Describe("Presenter", func() {
+	var (
+		gomockCtrl      *gomock.Controller
+		mockPricingRepo *mocks.MockPricingRepository
+		presenter       *pricing.Presenter
+		recorder        *httptest.ResponseRecorder
+		mockContext     *gin.Context
+	)
+
+	BeforeEach(func() {
+		gomockCtrl, _ = gomock.WithContext(context.Background(), GinkgoT())
+		mockPricingRepo = mocks.NewMockPricingRepository(gomockCtrl)
+		presenter = pricing.NewPresenter(mockPricingRepo)
+		recorder = httptest.NewRecorder()
+		mockContext, _ = gin.CreateTestContext(recorder)
+		mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
+	})
+
+	AfterEach(func() {
+		gomockCtrl.Finish()
+	})
+
+	Context("RetrieveQuote", func() {
+		When("the trip is valid", func() {
+			It("should return http.StatusOK with the itemized quote", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/quote?start=2024-06-03&end=2024-06-05", nil)
+				trip := p.Trip{Start: date(3), End: date(5)}
+				quote := p.Rules{PricePerDay: 8900, CleaningFee: 5000}.Quote(trip)
+				quote.RentalID, quote.Currency = 1, "EUR"
+				mockPricingRepo.EXPECT().RetrieveQuote(gomock.Any(), 1, trip).Return(quote, nil)
+
+				presenter.RetrieveQuote(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+
+				var response pricing.QuoteResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Currency).To(Equal("EUR"))
+				Expect(response.Nights).To(HaveLen(2))
+				Expect(response.Items).To(Equal([]pricing.LineItemResponse{
+					{Type: p.ItemNights, Description: "2 nights", Amount: 17800},
+					{Type: p.ItemCleaningFee, Description: "cleaning fee", Amount: 5000},
+				}))
+				Expect(response.Total).To(Equal(22800))
+			})
+		})
+
+		When("end is missing", func() {
+			It("should return http.StatusBadRequest without calling the repository", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/quote?start=2024-06-03", nil)
+
+				presenter.RetrieveQuote(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+
+				var response api.ErrorResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Error.Details[0].Field).To(Equal("end"))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			It("should return http.StatusNotFound", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/quote?start=2024-06-03&end=2024-06-05", nil)
+				mockPricingRepo.EXPECT().RetrieveQuote(gomock.Any(), 1, gomock.Any()).Return(p.Quote{}, postgres.ErrNotFound)
+
+				presenter.RetrieveQuote(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Context("ReplaceSeasons", func() {
+		When("the seasons are valid", func() {
+			It("should return http.StatusOK with the stored seasons", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodPut, "/rentals/1/seasons",
+					strings.NewReader(`{"seasons":[{"from":"2024-06-01","to":"2024-06-05","price_per_day":12000}]}`))
+				seasons := []p.Season{{From: date(1), To: date(5), PricePerDay: 12000}}
+				mockPricingRepo.EXPECT().ReplaceSeasons(gomock.Any(), 1, seasons).Return(seasons, nil)
+
+				presenter.ReplaceSeasons(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+
+				var response pricing.SeasonsResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Seasons).To(Equal([]pricing.SeasonResponse{{From: "2024-06-01", To: "2024-06-05", PricePerDay: 12000}}))
+			})
+		})
+
+		When("a date is malformed", func() {
+			It("should return http.StatusBadRequest naming the season", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodPut, "/rentals/1/seasons",
+					strings.NewReader(`{"seasons":[{"from":"2024-06-01","to":"June 5","price_per_day":12000}]}`))
+
+				presenter.ReplaceSeasons(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+
+				var response api.ErrorResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Error.Details[0].Field).To(Equal("seasons[0].to"))
+			})
+		})
+	})
+
+	Context("RetrieveSeasons", func() {
+		It("should return http.StatusOK with the seasons of the rental", func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/seasons", nil)
+			mockPricingRepo.EXPECT().RetrieveSeasons(gomock.Any(), 1).Return([]p.Season{}, nil)
+
+			presenter.RetrieveSeasons(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"seasons":[]}`))
+		})
+	})
+})
@@ -0,0 +1,55 @@
+package rentals
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+)
+
+// rentalETag is a strong validator of a rental representation. It changes with rentals.updated and the
+// owner's updated, and with whatever is derived on read, such as converted prices or the rating.
+func rentalETag(rental rentals.Model, response RentalResponse) (string, error) {
+	digest := sha256.New()
+	writeVersion(digest, rental)
+	if err := json.NewEncoder(digest).Encode(response); err != nil {
+		return "", fmt.Errorf("failed to encode rental %d: %w", rental.ID, err)
+	}
+
+	return toETag(digest), nil
+}
+
+// rentalsETag is a strong validator of a page of rentals, including its meta, links and facets
+func rentalsETag(page rentals.Page, response RentalsResponse) (string, error) {
+	digest := sha256.New()
+	for _, rental := range page.Rentals {
+		writeVersion(digest, rental)
+	}
+
+	if err := json.NewEncoder(digest).Encode(response); err != nil {
+		return "", fmt.Errorf("failed to encode rentals: %w", err)
+	}
+
+	return toETag(digest), nil
+}
+
+// lastModified is the time the rental or its owner last changed
+func lastModified(rental rentals.Model) time.Time {
+	if rental.UserUpdated.After(rental.Updated) {
+		return rental.UserUpdated
+	}
+
+	return rental.Updated
+}
+
+func writeVersion(digest hash.Hash, rental rentals.Model) {
+	fmt.Fprintf(digest, "%d:%d:%d\n", rental.ID, rental.Updated.UnixNano(), rental.UserUpdated.UnixNano())
+}
+
+func toETag(digest hash.Hash) string {
+	return `"` + hex.EncodeToString(digest.Sum(nil)[:16]) + `"`
+}
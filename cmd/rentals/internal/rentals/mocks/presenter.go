@@ -35,32 +35,91 @@ func (m *MockRentalRepository) EXPECT() *MockRentalRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateRental mocks base method.
+func (m *MockRentalRepository) CreateRental(ctx context.Context, input rentals.Input) (rentals.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRental", ctx, input)
+	ret0, _ := ret[0].(rentals.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRental indicates an expected call of CreateRental.
+func (mr *MockRentalRepositoryMockRecorder) CreateRental(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRental", reflect.TypeOf((*MockRentalRepository)(nil).CreateRental), ctx, input)
+}
+
+// DeleteRental mocks base method.
+func (m *MockRentalRepository) DeleteRental(ctx context.Context, id int, precondition rentals.Precondition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRental", ctx, id, precondition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRental indicates an expected call of DeleteRental.
+func (mr *MockRentalRepositoryMockRecorder) DeleteRental(ctx, id, precondition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRental", reflect.TypeOf((*MockRentalRepository)(nil).DeleteRental), ctx, id, precondition)
+}
+
+// PatchRental mocks base method.
+func (m *MockRentalRepository) PatchRental(ctx context.Context, id int, patch rentals.Patch, precondition rentals.Precondition) (rentals.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchRental", ctx, id, patch, precondition)
+	ret0, _ := ret[0].(rentals.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchRental indicates an expected call of PatchRental.
+func (mr *MockRentalRepositoryMockRecorder) PatchRental(ctx, id, patch, precondition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchRental", reflect.TypeOf((*MockRentalRepository)(nil).PatchRental), ctx, id, patch, precondition)
+}
+
 // RetrieveRentalByID mocks base method.
-func (m *MockRentalRepository) RetrieveRentalByID(ctx context.Context, id string) (rentals.Model, error) {
+func (m *MockRentalRepository) RetrieveRentalByID(ctx context.Context, id int, currency string) (rentals.Model, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RetrieveRentalByID", ctx, id)
+	ret := m.ctrl.Call(m, "RetrieveRentalByID", ctx, id, currency)
 	ret0, _ := ret[0].(rentals.Model)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RetrieveRentalByID indicates an expected call of RetrieveRentalByID.
-func (mr *MockRentalRepositoryMockRecorder) RetrieveRentalByID(ctx, id interface{}) *gomock.Call {
+func (mr *MockRentalRepositoryMockRecorder) RetrieveRentalByID(ctx, id, currency interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveRentalByID", reflect.TypeOf((*MockRentalRepository)(nil).RetrieveRentalByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveRentalByID", reflect.TypeOf((*MockRentalRepository)(nil).RetrieveRentalByID), ctx, id, currency)
 }
 
 // RetrieveRentals mocks base method.
-func (m *MockRentalRepository) RetrieveRentals(ctx context.Context, query map[string][]string) ([]rentals.Model, error) {
+func (m *MockRentalRepository) RetrieveRentals(ctx context.Context, filter rentals.Filter) (rentals.Page, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RetrieveRentals", ctx, query)
-	ret0, _ := ret[0].([]rentals.Model)
+	ret := m.ctrl.Call(m, "RetrieveRentals", ctx, filter)
+	ret0, _ := ret[0].(rentals.Page)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RetrieveRentals indicates an expected call of RetrieveRentals.
-func (mr *MockRentalRepositoryMockRecorder) RetrieveRentals(ctx, query interface{}) *gomock.Call {
+func (mr *MockRentalRepositoryMockRecorder) RetrieveRentals(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveRentals", reflect.TypeOf((*MockRentalRepository)(nil).RetrieveRentals), ctx, filter)
+}
+
+// UpdateRental mocks base method.
+func (m *MockRentalRepository) UpdateRental(ctx context.Context, id int, input rentals.Input, precondition rentals.Precondition) (rentals.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRental", ctx, id, input, precondition)
+	ret0, _ := ret[0].(rentals.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRental indicates an expected call of UpdateRental.
+func (mr *MockRentalRepositoryMockRecorder) UpdateRental(ctx, id, input, precondition interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveRentals", reflect.TypeOf((*MockRentalRepository)(nil).RetrieveRentals), ctx, query)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRental", reflect.TypeOf((*MockRentalRepository)(nil).UpdateRental), ctx, id, input, precondition)
 }
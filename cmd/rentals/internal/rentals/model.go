@@ -14,14 +14,54 @@ type RentalResponse struct {
 	Price           PriceResponse    `json:"price"`
 	Location        LocationResponse `json:"location"`
 	User            UserResponse     `json:"user"`
+	RatingAvg       float64          `json:"rating_avg"`
+	RatingCount     int              `json:"rating_count"`
+	Amenities       []string         `json:"amenities"`
+	Distance        *float64         `json:"distance,omitempty"`
+	Relevance       *float64         `json:"relevance,omitempty"`
 }
 
 type RentalsResponse struct {
 	Rentals []RentalResponse `json:"rentals"`
+	Meta    MetaResponse     `json:"meta"`
+	Links   LinksResponse    `json:"links"`
+	// Facets is only set when facets are requested, by facet name
+	Facets map[string][]FacetBucketResponse `json:"facets,omitempty"`
 }
 
+// FacetBucketResponse is a value of a term facet such as type, or a [min, max) range of a histogram facet such as price
+type FacetBucketResponse struct {
+	Value string `json:"value,omitempty"`
+	Min   *int   `json:"min,omitempty"`
+	Max   *int   `json:"max,omitempty"`
+	Count int    `json:"count"`
+}
+
+type MetaResponse struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type LinksResponse struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// PriceResponse holds the price per day together with the pricing rules of a rental.
+// Week and month are derived from the rules, see pricing.Rules.
 type PriceResponse struct {
-	Day int `json:"day"`
+	Day                     int     `json:"day"`
+	Week                    int     `json:"week"`
+	Month                   int     `json:"month"`
+	WeeklyDiscountPercent   float64 `json:"weekly_discount_percent"`
+	MonthlyDiscountPercent  float64 `json:"monthly_discount_percent"`
+	WeekendSurchargePercent float64 `json:"weekend_surcharge_percent"`
+	CleaningFee             int     `json:"cleaning_fee"`
+	ServiceFeePercent       float64 `json:"service_fee_percent"`
+	// Currency is the ISO 4217 code of every amount, the currency query parameter converts them
+	Currency string `json:"currency"`
 }
 
 type LocationResponse struct {
@@ -38,3 +78,75 @@ type UserResponse struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 }
+
+// RentalRequest is the body of POST /rentals and PUT /rentals/:id, it mirrors RentalResponse
+type RentalRequest struct {
+	Name            string          `json:"name"`
+	Description     string          `json:"description"`
+	Type            string          `json:"type"`
+	VehicleMake     string          `json:"make"`
+	VehicleModel    string          `json:"model"`
+	VehicleYear     int             `json:"year"`
+	VehicleLength   float32         `json:"length"`
+	Sleeps          int             `json:"sleeps"`
+	PrimaryImageURL string          `json:"primary_image_url"`
+	Price           PriceRequest    `json:"price"`
+	Location        LocationRequest `json:"location"`
+	UserID          int             `json:"user_id"`
+}
+
+// PriceRequest mirrors PriceResponse without the derived week and month
+type PriceRequest struct {
+	Day                     int     `json:"day"`
+	WeeklyDiscountPercent   float64 `json:"weekly_discount_percent"`
+	MonthlyDiscountPercent  float64 `json:"monthly_discount_percent"`
+	WeekendSurchargePercent float64 `json:"weekend_surcharge_percent"`
+	CleaningFee             int     `json:"cleaning_fee"`
+	ServiceFeePercent       float64 `json:"service_fee_percent"`
+	// Currency defaults to USD when it is omitted
+	Currency string `json:"currency"`
+}
+
+type LocationRequest struct {
+	HomeCity    string  `json:"city"`
+	HomeState   string  `json:"state"`
+	HomeZIP     string  `json:"zip"`
+	HomeCountry string  `json:"country"`
+	LAT         float32 `json:"lat"`
+	LNG         float32 `json:"lng"`
+}
+
+// RentalPatchRequest is the body of PATCH /rentals/:id, omitted fields are left unchanged
+type RentalPatchRequest struct {
+	Name            *string               `json:"name"`
+	Description     *string               `json:"description"`
+	Type            *string               `json:"type"`
+	VehicleMake     *string               `json:"make"`
+	VehicleModel    *string               `json:"model"`
+	VehicleYear     *int                  `json:"year"`
+	VehicleLength   *float32              `json:"length"`
+	Sleeps          *int                  `json:"sleeps"`
+	PrimaryImageURL *string               `json:"primary_image_url"`
+	Price           *PricePatchRequest    `json:"price"`
+	Location        *LocationPatchRequest `json:"location"`
+	UserID          *int                  `json:"user_id"`
+}
+
+type PricePatchRequest struct {
+	Day                     *int     `json:"day"`
+	WeeklyDiscountPercent   *float64 `json:"weekly_discount_percent"`
+	MonthlyDiscountPercent  *float64 `json:"monthly_discount_percent"`
+	WeekendSurchargePercent *float64 `json:"weekend_surcharge_percent"`
+	CleaningFee             *int     `json:"cleaning_fee"`
+	ServiceFeePercent       *float64 `json:"service_fee_percent"`
+	Currency                *string  `json:"currency"`
+}
+
+type LocationPatchRequest struct {
+	HomeCity    *string  `json:"city"`
+	HomeState   *string  `json:"state"`
+	HomeZIP     *string  `json:"zip"`
+	HomeCountry *string  `json:"country"`
+	LAT         *float32 `json:"lat"`
+	LNG         *float32 `json:"lng"`
+}
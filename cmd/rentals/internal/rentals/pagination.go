@@ -0,0 +1,68 @@
+package rentals
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+)
+
+// toLinksResponse builds links to the neighbouring pages by rewriting the offset of the current request.
+// Requests paginated with a cursor only get a next link, as cursors move forward.
+func toLinksResponse(requestURL *url.URL, filter rentals.Filter, page rentals.Page) LinksResponse {
+	var links LinksResponse
+	if filter.Cursor != nil {
+		if page.NextCursor != "" {
+			links.Next = withCursor(requestURL, page.NextCursor)
+		}
+
+		return links
+	}
+
+	if filter.Offset+filter.Limit < page.Total {
+		links.Next = withOffset(requestURL, filter.Offset+filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		prevOffset := filter.Offset - filter.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+
+		links.Prev = withOffset(requestURL, prevOffset)
+	}
+
+	return links
+}
+
+func withOffset(requestURL *url.URL, offset int) string {
+	return withQueryValue(requestURL, "offset", strconv.Itoa(offset))
+}
+
+func withCursor(requestURL *url.URL, cursor string) string {
+	return withQueryValue(requestURL, "cursor", cursor)
+}
+
+func withQueryValue(requestURL *url.URL, key, value string) string {
+	query := requestURL.Query()
+	query.Set(key, value)
+	link := url.URL{Path: requestURL.Path, RawQuery: query.Encode()}
+
+	return link.String()
+}
+
+// linkHeader formats the links as an RFC 8288 Link header value
+func linkHeader(links LinksResponse) string {
+	values := make([]string, 0, 2)
+	if links.Next != "" {
+		values = append(values, fmt.Sprintf(`<%s>; rel="next"`, links.Next))
+	}
+
+	if links.Prev != "" {
+		values = append(values, fmt.Sprintf(`<%s>; rel="prev"`, links.Prev))
+	}
+
+	return strings.Join(values, ", ")
+}
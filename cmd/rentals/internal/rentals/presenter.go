@@ -2,19 +2,41 @@ package rentals
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/authentication"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/httpx"
 	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/pricing"
 	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
 	"github.com/sirupsen/logrus"
 )
 
 //go:generate mockgen --source=presenter.go --destination mocks/presenter.go --package mocks
 
+// rentalErrors answers the errors of the rental repository
+var rentalErrors = httpx.Errors{
+	Resource: "rental",
+	Conflict: "rental conflicts with its current state",
+	Invalid:  "invalid rental",
+	Query:    rentals.ErrInvalidFilter,
+}
+
 type RentalRepository interface {
-	RetrieveRentalByID(ctx context.Context, id string) (rentals.Model, error)
-	RetrieveRentals(ctx context.Context, query map[string][]string) ([]rentals.Model, error)
+	RetrieveRentalByID(ctx context.Context, id int, currency string) (rentals.Model, error)
+	RetrieveRentals(ctx context.Context, filter rentals.Filter) (rentals.Page, error)
+	CreateRental(ctx context.Context, input rentals.Input) (rentals.Model, error)
+	UpdateRental(ctx context.Context, id int, input rentals.Input, precondition rentals.Precondition) (rentals.Model, error)
+	PatchRental(ctx context.Context, id int, patch rentals.Patch, precondition rentals.Precondition) (rentals.Model, error)
+	DeleteRental(ctx context.Context, id int, precondition rentals.Precondition) error
 }
 
 type Presenter struct {
@@ -28,39 +50,269 @@ func NewPresenter(rentalRepository RentalRepository) *Presenter {
 	}
 }
 
-// RetrieveRentalByID retrieves a rental by a given id
+// RetrieveRentalByID retrieves a rental by a given id, its prices are converted when currency is provided.
+// It responds with 304 when the If-None-Match or If-Modified-Since header still matches the rental.
 func (p *Presenter) RetrieveRentalByID(ctx *gin.Context) {
-	id := ctx.Param("id")
-	if id == "" {
-		ctx.JSON(http.StatusBadRequest, api.NewErrorResponse("missing id parameter"))
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
 		return
 	}
 
-	rental, err := p.rentalRepository.RetrieveRentalByID(ctx, id)
+	currency := strings.ToUpper(strings.TrimSpace(ctx.Query("currency")))
+	rental, err := p.rentalRepository.RetrieveRentalByID(ctx, id, currency)
 	if err != nil {
 		logrus.Error("failed to retrieve rental by id from repository: ", err)
-		ctx.JSON(http.StatusInternalServerError, api.NewErrorResponse("failed to retrieve rental by id"))
+		ctx.JSON(rentalErrors.Response(err, "failed to retrieve rental by id"))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, toRentalResponse(rental))
+	respondRental(ctx, http.StatusOK, rental)
 }
 
 // RetrieveRentals retrieves filtered, sorted or paginated rentals by passing query parameters
 func (p *Presenter) RetrieveRentals(ctx *gin.Context) {
-	queryParams := ctx.Request.URL.Query()
-	rentals, err := p.rentalRepository.RetrieveRentals(ctx, queryParams)
+	p.retrieveRentals(ctx, ctx.Request.URL.Query())
+}
+
+func (p *Presenter) retrieveRentals(ctx *gin.Context, query url.Values) {
+	filter, err := rentals.ParseFilter(query)
+	if err != nil {
+		var validationErr *postgres.ValidationError
+		if errors.As(err, &validationErr) {
+			ctx.JSON(http.StatusBadRequest, httpx.ValidationErrorResponse(validationErr, "invalid query parameters"))
+			return
+		}
+
+		logrus.Error("failed to parse rentals filter: ", err)
+		ctx.JSON(http.StatusBadRequest, api.NewErrorResponse(api.CodeInvalidParameter, "invalid query parameters"))
+		return
+	}
+
+	page, err := p.rentalRepository.RetrieveRentals(ctx, filter)
 	if err != nil {
 		logrus.Error("failed to retrieve rentals from repository: ", err)
-		ctx.JSON(http.StatusInternalServerError, api.NewErrorResponse("failed to retrieve rentals"))
+		ctx.JSON(rentalErrors.Response(err, "failed to retrieve rentals"))
+		return
+	}
+
+	response := toRentalsResponse(page, filter, toLinksResponse(ctx.Request.URL, filter, page))
+	if link := linkHeader(response.Links); link != "" {
+		ctx.Header("Link", link)
+	}
+
+	// a page has no Last-Modified, deleted rentals leave no trace to compare If-Modified-Since with
+	etag, err := rentalsETag(page, response)
+	if err != nil {
+		logrus.Error("failed to compute rentals etag: ", err)
+		ctx.JSON(http.StatusInternalServerError, api.NewErrorResponse(api.CodeInternal, "failed to retrieve rentals"))
+		return
+	}
+
+	api.SetValidators(ctx.Writer.Header(), etag, time.Time{})
+	if api.NotModified(ctx.Request, etag, time.Time{}) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RetrieveUserRentals retrieves the rentals owned by a user, accepting the query parameters of RetrieveRentals
+func (p *Presenter) RetrieveUserRentals(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, toRentalsResponse(rentals))
+	query := ctx.Request.URL.Query()
+	query.Set("user_id", strconv.Itoa(id))
+	p.retrieveRentals(ctx, query)
+}
+
+// CreateRental creates a rental from the request body
+func (p *Presenter) CreateRental(ctx *gin.Context) {
+	var request RentalRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	// a rental is only given to a user the request acts for
+	if !authentication.AuthorizeUser(ctx, request.UserID) {
+		return
+	}
+
+	rental, err := p.rentalRepository.CreateRental(ctx, toRentalInput(request))
+	if err != nil {
+		logrus.Error("failed to create rental in repository: ", err)
+		ctx.JSON(rentalErrors.Response(err, "failed to create rental"))
+		return
+	}
+
+	ctx.Header("Location", fmt.Sprintf("/rentals/%d", rental.ID))
+	respondRental(ctx, http.StatusCreated, rental)
+}
+
+// UpdateRental replaces every writable field of a rental by a given id, an If-Match header makes it conditional
+func (p *Presenter) UpdateRental(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	var request RentalRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	// a rental is only given to a user the request acts for
+	if !authentication.AuthorizeUser(ctx, request.UserID) {
+		return
+	}
+
+	precondition, ok := p.checkIfMatch(ctx, id)
+	if !ok {
+		return
+	}
+
+	rental, err := p.rentalRepository.UpdateRental(ctx, id, toRentalInput(request), precondition)
+	if err != nil {
+		logrus.Error("failed to update rental in repository: ", err)
+		ctx.JSON(rentalErrors.Response(err, "failed to update rental"))
+		return
+	}
+
+	respondRental(ctx, http.StatusOK, rental)
+}
+
+// PatchRental changes only the fields present in the request body of a rental by a given id,
+// an If-Match header makes it conditional
+func (p *Presenter) PatchRental(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	var request RentalPatchRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	if request.UserID != nil && !authentication.AuthorizeUser(ctx, *request.UserID) {
+		return
+	}
+
+	precondition, ok := p.checkIfMatch(ctx, id)
+	if !ok {
+		return
+	}
+
+	rental, err := p.rentalRepository.PatchRental(ctx, id, toRentalPatch(request), precondition)
+	if err != nil {
+		logrus.Error("failed to patch rental in repository: ", err)
+		ctx.JSON(rentalErrors.Response(err, "failed to patch rental"))
+		return
+	}
+
+	respondRental(ctx, http.StatusOK, rental)
+}
+
+// DeleteRental deletes a rental by a given id, an If-Match header makes it conditional
+func (p *Presenter) DeleteRental(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	precondition, ok := p.checkIfMatch(ctx, id)
+	if !ok {
+		return
+	}
+
+	if err := p.rentalRepository.DeleteRental(ctx, id, precondition); err != nil {
+		logrus.Error("failed to delete rental from repository: ", err)
+		ctx.JSON(rentalErrors.Response(err, "failed to delete rental"))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// checkIfMatch evaluates the If-Match header of a write against the stored rental, read without currency,
+// and responds with 412 when it does not match. The write is then made conditional on the matched version,
+// so a concurrent write in between fails with postgres.ErrPreconditionFailed as well.
+func (p *Presenter) checkIfMatch(ctx *gin.Context, id int) (rentals.Precondition, bool) {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		return rentals.Precondition{}, true
+	}
+
+	current, err := p.rentalRepository.RetrieveRentalByID(ctx, id, "")
+	if err != nil && !errors.Is(err, postgres.ErrNotFound) {
+		logrus.Error("failed to retrieve rental by id from repository: ", err)
+		ctx.JSON(rentalErrors.Response(err, "failed to retrieve rental by id"))
+		return rentals.Precondition{}, false
+	}
+
+	if err == nil {
+		etag, err := rentalETag(current, toRentalResponse(current))
+		if err != nil {
+			logrus.Error("failed to compute rental etag: ", err)
+			ctx.JSON(http.StatusInternalServerError, api.NewErrorResponse(api.CodeInternal, "failed to retrieve rental by id"))
+			return rentals.Precondition{}, false
+		}
+
+		if api.MatchETag(ifMatch, etag, true) {
+			return rentals.Precondition{Updated: current.Updated}, true
+		}
+	}
+
+	ctx.JSON(rentalErrors.Response(postgres.ErrPreconditionFailed, "failed to check rental version"))
+	return rentals.Precondition{}, false
+}
+
+// respondRental writes a rental with its ETag and Last-Modified headers, or 304 when a GET still matches them
+func respondRental(ctx *gin.Context, status int, rental rentals.Model) {
+	response := toRentalResponse(rental)
+	etag, err := rentalETag(rental, response)
+	if err != nil {
+		logrus.Error("failed to compute rental etag: ", err)
+		ctx.JSON(http.StatusInternalServerError, api.NewErrorResponse(api.CodeInternal, "failed to encode rental"))
+		return
+	}
+
+	modified := lastModified(rental)
+	if ctx.Query("currency") != "" {
+		// converted prices follow the exchange rates, which rentals.updated does not, so only the ETag validates them
+		modified = time.Time{}
+	}
+
+	api.SetValidators(ctx.Writer.Header(), etag, modified)
+	if api.NotModified(ctx.Request, etag, modified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.JSON(status, response)
 }
 
 func toRentalResponse(rental rentals.Model) RentalResponse {
-	price := PriceResponse{Day: rental.PricePerDay}
+	rules := pricing.Rules{
+		PricePerDay:             rental.PricePerDay,
+		WeeklyDiscountPercent:   rental.WeeklyDiscountPercent,
+		MonthlyDiscountPercent:  rental.MonthlyDiscountPercent,
+		WeekendSurchargePercent: rental.WeekendSurchargePercent,
+	}
+	price := PriceResponse{
+		Day:                     rental.PricePerDay,
+		Week:                    rules.Week(),
+		Month:                   rules.Month(),
+		WeeklyDiscountPercent:   rental.WeeklyDiscountPercent,
+		MonthlyDiscountPercent:  rental.MonthlyDiscountPercent,
+		WeekendSurchargePercent: rental.WeekendSurchargePercent,
+		CleaningFee:             rental.CleaningFee,
+		ServiceFeePercent:       rental.ServiceFeePercent,
+		Currency:                rental.Currency,
+	}
 	location := LocationResponse{
 		HomeCity:    rental.HomeCity,
 		HomeState:   rental.HomeState,
@@ -70,11 +322,17 @@ func toRentalResponse(rental rentals.Model) RentalResponse {
 		LNG:         rental.LNG,
 	}
 	user := UserResponse{
-		ID:        rental.ID,
+		ID:        rental.UserID,
 		FirstName: rental.FirstName,
 		LastName:  rental.LastName,
 	}
 
+	// amenities are always an array, empty when the rental offers none
+	amenities := rental.Amenities
+	if amenities == nil {
+		amenities = make([]string, 0)
+	}
+
 	return RentalResponse{
 		ID:              rental.ID,
 		Name:            rental.Name,
@@ -89,16 +347,116 @@ func toRentalResponse(rental rentals.Model) RentalResponse {
 		Price:           price,
 		Location:        location,
 		User:            user,
+		RatingAvg:       rental.RatingAvg,
+		RatingCount:     rental.RatingCount,
+		Amenities:       amenities,
+		Distance:        rental.Distance,
+		Relevance:       rental.Relevance,
 	}
 }
 
-func toRentalsResponse(rentals []rentals.Model) RentalsResponse {
+func toRentalsResponse(page rentals.Page, filter rentals.Filter, links LinksResponse) RentalsResponse {
 	rentalsResponse := make([]RentalResponse, 0)
-	for _, rental := range rentals {
+	for _, rental := range page.Rentals {
 		rentalsResponse = append(rentalsResponse, toRentalResponse(rental))
 	}
 
 	return RentalsResponse{
 		Rentals: rentalsResponse,
+		Meta: MetaResponse{
+			Total:      page.Total,
+			Limit:      filter.Limit,
+			Offset:     filter.Offset,
+			NextCursor: page.NextCursor,
+		},
+		Links:  links,
+		Facets: toFacetsResponse(page.Facets),
+	}
+}
+
+func toFacetsResponse(facets map[string][]rentals.FacetBucket) map[string][]FacetBucketResponse {
+	if facets == nil {
+		return nil
 	}
+
+	facetsResponse := make(map[string][]FacetBucketResponse, len(facets))
+	for name, buckets := range facets {
+		bucketsResponse := make([]FacetBucketResponse, 0, len(buckets))
+		for _, bucket := range buckets {
+			bucketsResponse = append(bucketsResponse, FacetBucketResponse{
+				Value: bucket.Value,
+				Min:   bucket.Min,
+				Max:   bucket.Max,
+				Count: bucket.Count,
+			})
+		}
+
+		facetsResponse[name] = bucketsResponse
+	}
+
+	return facetsResponse
+}
+
+func toRentalInput(request RentalRequest) rentals.Input {
+	return rentals.Input{
+		Name:                    request.Name,
+		Description:             request.Description,
+		Type:                    request.Type,
+		VehicleMake:             request.VehicleMake,
+		VehicleModel:            request.VehicleModel,
+		VehicleYear:             request.VehicleYear,
+		VehicleLength:           request.VehicleLength,
+		Sleeps:                  request.Sleeps,
+		PrimaryImageURL:         request.PrimaryImageURL,
+		PricePerDay:             request.Price.Day,
+		WeeklyDiscountPercent:   request.Price.WeeklyDiscountPercent,
+		MonthlyDiscountPercent:  request.Price.MonthlyDiscountPercent,
+		WeekendSurchargePercent: request.Price.WeekendSurchargePercent,
+		CleaningFee:             request.Price.CleaningFee,
+		ServiceFeePercent:       request.Price.ServiceFeePercent,
+		Currency:                request.Price.Currency,
+		HomeCity:                request.Location.HomeCity,
+		HomeState:               request.Location.HomeState,
+		HomeZIP:                 request.Location.HomeZIP,
+		HomeCountry:             request.Location.HomeCountry,
+		LAT:                     request.Location.LAT,
+		LNG:                     request.Location.LNG,
+		UserID:                  request.UserID,
+	}
+}
+
+func toRentalPatch(request RentalPatchRequest) rentals.Patch {
+	patch := rentals.Patch{
+		Name:            request.Name,
+		Description:     request.Description,
+		Type:            request.Type,
+		VehicleMake:     request.VehicleMake,
+		VehicleModel:    request.VehicleModel,
+		VehicleYear:     request.VehicleYear,
+		VehicleLength:   request.VehicleLength,
+		Sleeps:          request.Sleeps,
+		PrimaryImageURL: request.PrimaryImageURL,
+		UserID:          request.UserID,
+	}
+
+	if request.Price != nil {
+		patch.PricePerDay = request.Price.Day
+		patch.WeeklyDiscountPercent = request.Price.WeeklyDiscountPercent
+		patch.MonthlyDiscountPercent = request.Price.MonthlyDiscountPercent
+		patch.WeekendSurchargePercent = request.Price.WeekendSurchargePercent
+		patch.CleaningFee = request.Price.CleaningFee
+		patch.ServiceFeePercent = request.Price.ServiceFeePercent
+		patch.Currency = request.Price.Currency
+	}
+
+	if request.Location != nil {
+		patch.HomeCity = request.Location.HomeCity
+		patch.HomeState = request.Location.HomeState
+		patch.HomeZIP = request.Location.HomeZIP
+		patch.HomeCountry = request.Location.HomeCountry
+		patch.LAT = request.Location.LAT
+		patch.LNG = request.Location.LNG
+	}
+
+	return patch
 }
@@ -4,14 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/nvasilev98/rentals/cmd/rentals/internal/rentals"
 	"github.com/nvasilev98/rentals/cmd/rentals/internal/rentals/mocks"
 	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
 	r "github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -53,11 +58,46 @@ var _ = Describe("Presenter", func() {
 		})
 	})
 
+	When("id parameter is not numeric", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "abc"}}
+		})
+
+		It("should return http.StatusBadRequest code without calling the repository", func() {
+			presenter.RetrieveRentalByID(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(api.CodeInvalidParameter))
+			Expect(errResp.Error.Field).To(Equal("id"))
+		})
+	})
+
+	DescribeTable("retrieving rental by id from repository fails with a known error class",
+		func(repoErr error, expectedStatus int, expectedCode string) {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(r.Model{}, repoErr)
+
+			presenter.RetrieveRentalByID(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(expectedStatus))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(expectedCode))
+		},
+		Entry("not found", fmt.Errorf("failed to scan row: %w", postgres.ErrNotFound), http.StatusNotFound, api.CodeNotFound),
+		Entry("precondition failed", postgres.ErrPreconditionFailed, http.StatusPreconditionFailed, api.CodePreconditionFailed),
+		Entry("invalid input", postgres.ErrInvalidInput, http.StatusBadRequest, api.CodeInvalidParameter),
+		Entry("unavailable", postgres.ErrUnavailable, http.StatusServiceUnavailable, api.CodeServiceUnavailable),
+		Entry("timeout", postgres.ErrTimeout, http.StatusGatewayTimeout, api.CodeTimeout),
+	)
+
 	When("retrieving rental by id from repository fails", func() {
 		BeforeEach(func() {
 			mockContext.Request, _ = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
 			mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
-			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), gomock.Any()).Return(r.Model{}, errors.New("err"))
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), gomock.Any(), "").Return(r.Model{}, errors.New("err"))
 		})
 
 		It("should return http.StatusInternalServerError code", func() {
@@ -65,6 +105,7 @@ var _ = Describe("Presenter", func() {
 			Expect(mockContext.Writer.Status()).To(Equal(http.StatusInternalServerError))
 			errResp := api.ErrorResponse{}
 			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(api.CodeInternal))
 			Expect(errResp.Error.Message).To(Equal("failed to retrieve rental by id"))
 		})
 	})
@@ -78,7 +119,8 @@ var _ = Describe("Presenter", func() {
 		BeforeEach(func() {
 			mockContext.Request, _ = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
 			mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
-			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), gomock.Any()).Return(r.Model{ID: id, Name: name}, nil)
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), gomock.Any(), "").
+				Return(r.Model{ID: id, Name: name, RatingAvg: 4.5, RatingCount: 2, Amenities: []string{"kitchen", "pets"}}, nil)
 		})
 
 		It("should return http.StatusOK code", func() {
@@ -88,13 +130,131 @@ var _ = Describe("Presenter", func() {
 			Expect(json.Unmarshal(recorder.Body.Bytes(), &rentalResp)).To(Succeed())
 			Expect(rentalResp.ID).To(Equal(id))
 			Expect(rentalResp.Name).To(Equal(name))
+			Expect(rentalResp.RatingAvg).To(Equal(4.5))
+			Expect(rentalResp.RatingCount).To(Equal(2))
+			Expect(rentalResp.Amenities).To(Equal([]string{"kitchen", "pets"}))
+		})
+	})
+
+	When("retrieving a rental that has validators", func() {
+		var (
+			updated     = time.Date(2022, 2, 3, 4, 5, 6, 0, time.UTC)
+			userUpdated = updated.Add(time.Hour)
+		)
+
+		retrieve := func(header, value string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1", nil)
+			if header != "" {
+				ctx.Request.Header.Set(header, value)
+			}
+			ctx.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+			presenter.RetrieveRentalByID(ctx)
+			ctx.Writer.WriteHeaderNow()
+			return recorder
+		}
+
+		BeforeEach(func() {
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").
+				Return(r.Model{ID: 1, Name: "test", Updated: updated, UserUpdated: userUpdated}, nil).AnyTimes()
+		})
+
+		It("should set a strong ETag and the Last-Modified of the rental or its owner", func() {
+			recorder := retrieve("", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("ETag")).To(MatchRegexp(`^"[0-9a-f]{32}"$`))
+			Expect(recorder.Header().Get("Last-Modified")).To(Equal("Thu, 03 Feb 2022 05:05:06 GMT"))
+		})
+
+		It("should return http.StatusNotModified when If-None-Match lists the ETag", func() {
+			etag := retrieve("", "").Header().Get("ETag")
+
+			recorder := retrieve("If-None-Match", `"other", W/`+etag)
+			Expect(recorder.Code).To(Equal(http.StatusNotModified))
+			Expect(recorder.Body.Len()).To(BeZero())
+			Expect(recorder.Header().Get("ETag")).To(Equal(etag))
+		})
+
+		It("should return http.StatusOK when If-None-Match lists other ETags only", func() {
+			Expect(retrieve("If-None-Match", `"other"`).Code).To(Equal(http.StatusOK))
+		})
+
+		It("should compare If-Modified-Since with the Last-Modified", func() {
+			Expect(retrieve("If-Modified-Since", "Thu, 03 Feb 2022 05:05:06 GMT").Code).To(Equal(http.StatusNotModified))
+			Expect(retrieve("If-Modified-Since", "Thu, 03 Feb 2022 05:05:05 GMT").Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	When("retrieving rental by id in another currency", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1?currency=eur", nil)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "EUR").
+				Return(r.Model{ID: 1, PricePerDay: 9200, Currency: "EUR"}, nil)
+		})
+
+		It("should return the converted price together with its currency", func() {
+			presenter.RetrieveRentalByID(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			rentalResp := rentals.RentalResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &rentalResp)).To(Succeed())
+			Expect(rentalResp.Price.Day).To(Equal(9200))
+			Expect(rentalResp.Price.Currency).To(Equal("EUR"))
+		})
+
+		It("should set the ETag but no Last-Modified", func() {
+			presenter.RetrieveRentalByID(mockContext)
+			Expect(recorder.Header().Get("ETag")).ToNot(BeEmpty())
+			Expect(recorder.Header().Get("Last-Modified")).To(BeEmpty())
+		})
+	})
+
+	When("retrieving rental by id in a currency without exchange rate", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1?currency=XYZ", nil)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "XYZ").
+				Return(r.Model{}, &postgres.ValidationError{
+					Errors: []postgres.FieldError{{Field: "currency", Reason: postgres.ReasonInvalidValue, Message: "has no exchange rate"}},
+					Kind:   r.ErrInvalidFilter,
+				})
+		})
+
+		It("should return http.StatusBadRequest code for the currency", func() {
+			presenter.RetrieveRentalByID(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Details).To(HaveLen(1))
+			Expect(errResp.Error.Details[0].Field).To(Equal("currency"))
+		})
+	})
+
+	When("query parameters are invalid", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals?price_min=abc&near=foo", nil)
+		})
+
+		It("should return http.StatusBadRequest code with every invalid field", func() {
+			presenter.RetrieveRentals(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(api.CodeValidationFailed))
+			Expect(errResp.Error.Details).To(HaveLen(2))
+			Expect(errResp.Error.Details[0].Field).To(Equal("price_min"))
+			Expect(errResp.Error.Details[0].Code).To(Equal(api.CodeInvalidParameter))
+			Expect(errResp.Error.Details[1].Field).To(Equal("near"))
+			Expect(errResp.Error.Details[1].Code).To(Equal(api.CodeInvalidParameter))
 		})
 	})
 
 	When("retrieving rentals from repository fails", func() {
 		BeforeEach(func() {
 			mockContext.Request, _ = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
-			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).Return([]r.Model{}, errors.New("err"))
+			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).Return(r.Page{}, errors.New("err"))
 		})
 
 		It("should return http.StatusInternalServerError code", func() {
@@ -106,6 +266,27 @@ var _ = Describe("Presenter", func() {
 		})
 	})
 
+	When("retrieving rentals that were not modified", func() {
+		It("should return http.StatusNotModified when If-None-Match lists the ETag of the page", func() {
+			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).
+				Return(r.Page{Rentals: []r.Model{{ID: 1, Name: "test"}}, Total: 1}, nil).Times(2)
+
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals?type=camper-van", nil)
+			presenter.RetrieveRentals(mockContext)
+			etag := recorder.Header().Get("ETag")
+			Expect(etag).ToNot(BeEmpty())
+			Expect(recorder.Header().Get("Last-Modified")).To(BeEmpty())
+
+			notModified := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(notModified)
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/rentals?type=camper-van", nil)
+			ctx.Request.Header.Set("If-None-Match", etag)
+			presenter.RetrieveRentals(ctx)
+			ctx.Writer.WriteHeaderNow()
+			Expect(notModified.Code).To(Equal(http.StatusNotModified))
+		})
+	})
+
 	When("retrieving rentals succeeds", func() {
 		const (
 			id   = 1
@@ -114,7 +295,7 @@ var _ = Describe("Presenter", func() {
 
 		BeforeEach(func() {
 			mockContext.Request, _ = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
-			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).Return([]r.Model{{ID: id, Name: name}}, nil)
+			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).Return(r.Page{Rentals: []r.Model{{ID: id, Name: name}}, Total: 1}, nil)
 		})
 
 		It("should return http.StatusOK code", func() {
@@ -124,6 +305,324 @@ var _ = Describe("Presenter", func() {
 			Expect(json.Unmarshal(recorder.Body.Bytes(), &rentalResp)).To(Succeed())
 			Expect(rentalResp.Rentals[0].ID).To(Equal(id))
 			Expect(rentalResp.Rentals[0].Name).To(Equal(name))
+			Expect(rentalResp.Rentals[0].Distance).To(BeNil())
+			Expect(rentalResp.Meta).To(Equal(rentals.MetaResponse{Total: 1, Limit: r.DefaultLimit, Offset: 0}))
+			Expect(rentalResp.Links).To(Equal(rentals.LinksResponse{}))
+			Expect(recorder.Header().Get("Link")).To(BeEmpty())
+		})
+	})
+
+	When("retrieving a page with a cursor", func() {
+		cursor := r.Cursor{Sort: "price", Values: []interface{}{100.0}, ID: 3}.Encode()
+
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals?limit=2&sort=price&cursor="+cursor, nil)
+			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).
+				Return(r.Page{Rentals: []r.Model{{ID: 4}, {ID: 5}}, Total: 10, NextCursor: "next"}, nil)
+		})
+
+		It("should return the next cursor and link to it", func() {
+			presenter.RetrieveRentals(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			rentalResp := rentals.RentalsResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &rentalResp)).To(Succeed())
+			Expect(rentalResp.Meta.NextCursor).To(Equal("next"))
+			Expect(rentalResp.Links).To(Equal(rentals.LinksResponse{Next: "/rentals?cursor=next&limit=2&sort=price"}))
+		})
+	})
+
+	When("retrieving a page in the middle of the results", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals?limit=2&offset=3&sort=price", nil)
+			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).
+				Return(r.Page{Rentals: []r.Model{{ID: 4}, {ID: 5}}, Total: 10}, nil)
+		})
+
+		It("should return pagination metadata, links and a Link header", func() {
+			presenter.RetrieveRentals(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			rentalResp := rentals.RentalsResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &rentalResp)).To(Succeed())
+			Expect(rentalResp.Meta).To(Equal(rentals.MetaResponse{Total: 10, Limit: 2, Offset: 3}))
+			Expect(rentalResp.Links.Next).To(Equal("/rentals?limit=2&offset=5&sort=price"))
+			Expect(rentalResp.Links.Prev).To(Equal("/rentals?limit=2&offset=1&sort=price"))
+			Expect(recorder.Header().Get("Link")).To(Equal(
+				`</rentals?limit=2&offset=5&sort=price>; rel="next", </rentals?limit=2&offset=1&sort=price>; rel="prev"`))
+		})
+	})
+
+	When("retrieving rentals near a location succeeds", func() {
+		const distance = 12.5
+
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals?near=33.64,-117.93&radius=50km&sort=distance", nil)
+			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, filter r.Filter) (r.Page, error) {
+					Expect(filter.Radius).To(Equal(r.Distance{Value: 50, Unit: r.Kilometers}))
+					d := distance
+					return r.Page{Rentals: []r.Model{{ID: 1, Distance: &d}}, Total: 1}, nil
+				})
+		})
+
+		It("should include the distance of every rental", func() {
+			presenter.RetrieveRentals(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			rentalResp := rentals.RentalsResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &rentalResp)).To(Succeed())
+			Expect(*rentalResp.Rentals[0].Distance).To(Equal(distance))
+		})
+	})
+
+	When("retrieving rentals with facets", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals?type=trailer&facets=type,sleeps", nil)
+			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, filter r.Filter) (r.Page, error) {
+					Expect(filter.Facets).To(Equal([]string{r.FacetType, r.FacetSleeps}))
+					two := 2
+					return r.Page{Rentals: []r.Model{{ID: 1}}, Total: 1, Facets: map[string][]r.FacetBucket{
+						r.FacetType:   {{Value: "camper-van", Count: 4}, {Value: "trailer", Count: 1}},
+						r.FacetSleeps: {{Max: &two, Count: 0}, {Min: &two, Count: 1}},
+					}}, nil
+				})
+		})
+
+		It("should include the buckets of every facet", func() {
+			presenter.RetrieveRentals(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			var rentalResp map[string]json.RawMessage
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &rentalResp)).To(Succeed())
+			Expect(rentalResp["facets"]).To(MatchJSON(`{
+				"type": [{"value": "camper-van", "count": 4}, {"value": "trailer", "count": 1}],
+				"sleeps": [{"max": 2, "count": 0}, {"min": 2, "count": 1}]
+			}`))
+		})
+	})
+
+	When("creating a rental", func() {
+		BeforeEach(func() {
+			body := `{"name":"Westfalia","type":"camper-van","price":{"day":120},"location":{"lat":45.5,"lng":-122.6},"user_id":3}`
+			mockContext.Request, _ = http.NewRequest(http.MethodPost, "/rentals", strings.NewReader(body))
+		})
+
+		It("should return http.StatusCreated with the location of the new rental", func() {
+			expectedInput := r.Input{Name: "Westfalia", Type: "camper-van", PricePerDay: 120, LAT: 45.5, LNG: -122.6, UserID: 3}
+			mockRentalRepo.EXPECT().CreateRental(gomock.Any(), expectedInput).Return(r.Model{ID: 7, Name: "Westfalia"}, nil)
+
+			presenter.CreateRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusCreated))
+			Expect(recorder.Header().Get("Location")).To(Equal("/rentals/7"))
+			rentalResp := rentals.RentalResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &rentalResp)).To(Succeed())
+			Expect(rentalResp.ID).To(Equal(7))
+		})
+
+		It("should list every invalid field when validation fails", func() {
+			validationErr := &postgres.ValidationError{Errors: []postgres.FieldError{
+				{Field: "name", Reason: postgres.ReasonInvalidValue, Message: "must not be empty"},
+				{Field: "price.day", Reason: postgres.ReasonOutOfRange, Message: "must be greater than or equal to 0"},
+			}}
+			mockRentalRepo.EXPECT().CreateRental(gomock.Any(), gomock.Any()).Return(r.Model{}, validationErr)
+
+			presenter.CreateRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(api.CodeValidationFailed))
+			Expect(errResp.Error.Details).To(HaveLen(2))
+			Expect(errResp.Error.Details[1]).To(Equal(api.NewFieldError(api.CodeOutOfRange, "price.day",
+				"must be greater than or equal to 0")))
+		})
+	})
+
+	When("creating a rental for another user than the credentials act for", func() {
+		BeforeEach(func() {
+			body := `{"name":"Westfalia","type":"camper-van","price":{"day":120},"location":{"lat":45.5,"lng":-122.6},"user_id":3}`
+			request, _ := http.NewRequest(http.MethodPost, "/rentals", strings.NewReader(body))
+			userID := 4
+			mockContext.Request = request.WithContext(auth.WithPrincipal(request.Context(),
+				auth.Principal{Subject: "4", Method: auth.MethodJWT, UserID: &userID}))
+		})
+
+		It("should return http.StatusForbidden without calling the repository", func() {
+			presenter.CreateRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusForbidden))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(api.CodeForbidden))
+		})
+	})
+
+	When("the request body has unknown fields", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodPost, "/rentals", strings.NewReader(`{"color":"red"}`))
+		})
+
+		It("should return http.StatusBadRequest without calling the repository", func() {
+			presenter.CreateRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(api.CodeInvalidBody))
+		})
+	})
+
+	When("patching a rental", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodPatch, "/rentals/7", strings.NewReader(`{"price":{"day":150}}`))
+			mockContext.Params = []gin.Param{{Key: "id", Value: "7"}}
+		})
+
+		It("should pass only the fields present in the body", func() {
+			mockRentalRepo.EXPECT().PatchRental(gomock.Any(), 7, gomock.Any(), r.Precondition{}).
+				DoAndReturn(func(_ context.Context, _ int, patch r.Patch, _ r.Precondition) (r.Model, error) {
+					Expect(*patch.PricePerDay).To(Equal(150))
+					Expect(patch.Name).To(BeNil())
+					Expect(patch.LAT).To(BeNil())
+					return r.Model{ID: 7, PricePerDay: 150}, nil
+				})
+
+			presenter.PatchRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+		})
+	})
+
+	When("patching the pricing rules of a rental", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodPatch, "/rentals/7",
+				strings.NewReader(`{"price":{"weekly_discount_percent":10,"monthly_discount_percent":25}}`))
+			mockContext.Params = []gin.Param{{Key: "id", Value: "7"}}
+		})
+
+		It("should respond with the weekly and the monthly price derived from the rules", func() {
+			mockRentalRepo.EXPECT().PatchRental(gomock.Any(), 7, gomock.Any(), r.Precondition{}).
+				DoAndReturn(func(_ context.Context, _ int, patch r.Patch, _ r.Precondition) (r.Model, error) {
+					Expect(*patch.WeeklyDiscountPercent).To(Equal(10.0))
+					Expect(patch.PricePerDay).To(BeNil())
+					return r.Model{ID: 7, PricePerDay: 150, WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 25}, nil
+				})
+
+			presenter.PatchRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+
+			var rentalResp rentals.RentalResponse
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &rentalResp)).To(Succeed())
+			Expect(rentalResp.Price.Week).To(Equal(945))
+			Expect(rentalResp.Price.Month).To(Equal(3150))
+		})
+	})
+
+	When("replacing a rental that does not exist", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodPut, "/rentals/7", strings.NewReader(`{"name":"Westfalia"}`))
+			mockContext.Params = []gin.Param{{Key: "id", Value: "7"}}
+		})
+
+		It("should return http.StatusNotFound", func() {
+			mockRentalRepo.EXPECT().UpdateRental(gomock.Any(), 7, gomock.Any(), r.Precondition{}).Return(r.Model{}, postgres.ErrNotFound)
+
+			presenter.UpdateRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("writing a rental with an If-Match header", func() {
+		var (
+			updated = time.Date(2022, 2, 3, 4, 5, 6, 0, time.UTC)
+			current = r.Model{ID: 7, Name: "Westfalia", Updated: updated}
+			etag    string
+		)
+
+		BeforeEach(func() {
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), 7, "").Return(current, nil)
+			getContext, _ := gin.CreateTestContext(recorder)
+			getContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/7", nil)
+			getContext.Params = []gin.Param{{Key: "id", Value: "7"}}
+			presenter.RetrieveRentalByID(getContext)
+			etag = recorder.Header().Get("ETag")
+
+			recorder = httptest.NewRecorder()
+			mockContext, _ = gin.CreateTestContext(recorder)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "7"}}
+		})
+
+		It("should patch conditionally on the version that matched and return the new ETag", func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodPatch, "/rentals/7", strings.NewReader(`{"name":"Vanagon"}`))
+			mockContext.Request.Header.Set("If-Match", etag)
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), 7, "").Return(current, nil)
+			mockRentalRepo.EXPECT().PatchRental(gomock.Any(), 7, gomock.Any(), r.Precondition{Updated: updated}).
+				Return(r.Model{ID: 7, Name: "Vanagon", Updated: updated.Add(time.Minute)}, nil)
+
+			presenter.PatchRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("ETag")).ToNot(BeEmpty())
+			Expect(recorder.Header().Get("ETag")).ToNot(Equal(etag))
+		})
+
+		It("should return http.StatusPreconditionFailed without writing when the ETag is stale", func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodPut, "/rentals/7", strings.NewReader(`{"name":"Vanagon"}`))
+			mockContext.Request.Header.Set("If-Match", `"stale"`)
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), 7, "").Return(current, nil)
+
+			presenter.UpdateRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusPreconditionFailed))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(api.CodePreconditionFailed))
+		})
+
+		It("should return http.StatusPreconditionFailed when the rental changes before it is written", func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodDelete, "/rentals/7", nil)
+			mockContext.Request.Header.Set("If-Match", etag)
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), 7, "").Return(current, nil)
+			mockRentalRepo.EXPECT().DeleteRental(gomock.Any(), 7, r.Precondition{Updated: updated}).
+				Return(postgres.ErrPreconditionFailed)
+
+			presenter.DeleteRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("should return http.StatusPreconditionFailed when the rental does not exist", func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodDelete, "/rentals/7", nil)
+			mockContext.Request.Header.Set("If-Match", "*")
+			mockRentalRepo.EXPECT().RetrieveRentalByID(gomock.Any(), 7, "").Return(r.Model{}, postgres.ErrNotFound)
+
+			presenter.DeleteRental(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusPreconditionFailed))
+		})
+	})
+
+	When("deleting a rental", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodDelete, "/rentals/7", nil)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "7"}}
+		})
+
+		It("should return http.StatusNoContent", func() {
+			mockRentalRepo.EXPECT().DeleteRental(gomock.Any(), 7, r.Precondition{}).Return(nil)
+
+			presenter.DeleteRental(mockContext)
+			mockContext.Writer.WriteHeaderNow()
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	When("retrieving the rentals of a user", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/users/3/rentals?limit=2&user_id=9", nil)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "3"}}
+		})
+
+		It("should filter by the user in the path", func() {
+			mockRentalRepo.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, filter r.Filter) (r.Page, error) {
+					Expect(*filter.UserID).To(Equal(3))
+					Expect(filter.Limit).To(Equal(2))
+					return r.Page{Rentals: []r.Model{{ID: 1, UserID: 3}}, Total: 1}, nil
+				})
+
+			presenter.RetrieveUserRentals(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
 		})
 	})
 })
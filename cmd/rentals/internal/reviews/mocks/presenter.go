@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presenter.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	reviews "github.com/nvasilev98/rentals/pkg/repository/postgres/reviews"
+)
+
+// MockReviewRepository is a mock of ReviewRepository interface.
+type MockReviewRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewRepositoryMockRecorder
+}
+
+// MockReviewRepositoryMockRecorder is the mock recorder for MockReviewRepository.
+type MockReviewRepositoryMockRecorder struct {
+	mock *MockReviewRepository
+}
+
+// NewMockReviewRepository creates a new mock instance.
+func NewMockReviewRepository(ctrl *gomock.Controller) *MockReviewRepository {
+	mock := &MockReviewRepository{ctrl: ctrl}
+	mock.recorder = &MockReviewRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReviewRepository) EXPECT() *MockReviewRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateReview mocks base method.
+func (m *MockReviewRepository) CreateReview(ctx context.Context, input reviews.Input) (reviews.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReview", ctx, input)
+	ret0, _ := ret[0].(reviews.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReview indicates an expected call of CreateReview.
+func (mr *MockReviewRepositoryMockRecorder) CreateReview(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReview", reflect.TypeOf((*MockReviewRepository)(nil).CreateReview), ctx, input)
+}
+
+// RetrieveReviews mocks base method.
+func (m *MockReviewRepository) RetrieveReviews(ctx context.Context, rentalID int, filter reviews.Filter) (reviews.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveReviews", ctx, rentalID, filter)
+	ret0, _ := ret[0].(reviews.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveReviews indicates an expected call of RetrieveReviews.
+func (mr *MockReviewRepositoryMockRecorder) RetrieveReviews(ctx, rentalID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveReviews", reflect.TypeOf((*MockReviewRepository)(nil).RetrieveReviews), ctx, rentalID, filter)
+}
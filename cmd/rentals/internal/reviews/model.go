@@ -0,0 +1,32 @@
+package reviews
+
+import "time"
+
+// ReviewRequest is the body of POST /rentals/:id/reviews, booking_id ties the review to a completed booking of the user
+type ReviewRequest struct {
+	UserID    int    `json:"user_id"`
+	BookingID *int   `json:"booking_id"`
+	Rating    int    `json:"rating"`
+	Comment   string `json:"comment"`
+}
+
+type ReviewResponse struct {
+	ID        int       `json:"id"`
+	RentalID  int       `json:"rental_id"`
+	UserID    int       `json:"user_id"`
+	BookingID *int      `json:"booking_id,omitempty"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment"`
+	Created   time.Time `json:"created"`
+}
+
+type ReviewsResponse struct {
+	Reviews []ReviewResponse `json:"reviews"`
+	Meta    MetaResponse     `json:"meta"`
+}
+
+type MetaResponse struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
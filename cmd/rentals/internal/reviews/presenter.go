@@ -0,0 +1,114 @@
+package reviews
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/authentication"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/httpx"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/reviews"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=presenter.go --destination mocks/presenter.go --package mocks
+
+// reviewErrors answers the errors of the review repository, a missing rental is what is not found
+var reviewErrors = httpx.Errors{
+	Resource: "rental",
+	Conflict: "rental is already reviewed by the user or for the booking",
+	Invalid:  "invalid review",
+	Query:    reviews.ErrInvalidFilter,
+}
+
+type ReviewRepository interface {
+	CreateReview(ctx context.Context, input reviews.Input) (reviews.Model, error)
+	RetrieveReviews(ctx context.Context, rentalID int, filter reviews.Filter) (reviews.Page, error)
+}
+
+type Presenter struct {
+	reviewRepository ReviewRepository
+}
+
+// NewPresenter is a constructor function
+func NewPresenter(reviewRepository ReviewRepository) *Presenter {
+	return &Presenter{
+		reviewRepository: reviewRepository,
+	}
+}
+
+// CreateReview reviews a rental by a given id with the rating and comment in the request body
+func (p *Presenter) CreateReview(ctx *gin.Context) {
+	rentalID, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	var request ReviewRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	if !authentication.AuthorizeUser(ctx, request.UserID) {
+		return
+	}
+
+	review, err := p.reviewRepository.CreateReview(ctx, reviews.Input{
+		RentalID:  rentalID,
+		UserID:    request.UserID,
+		BookingID: request.BookingID,
+		Rating:    request.Rating,
+		Comment:   request.Comment,
+	})
+	if err != nil {
+		logrus.Error("failed to create review in repository: ", err)
+		ctx.JSON(reviewErrors.Response(err, "failed to create review"))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toReviewResponse(review))
+}
+
+// RetrieveReviews retrieves a page of the reviews of a rental by a given id, newest first
+func (p *Presenter) RetrieveReviews(ctx *gin.Context) {
+	rentalID, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	filter, err := reviews.ParseFilter(ctx.Request.URL.Query())
+	if err != nil {
+		logrus.Error("failed to parse reviews filter: ", err)
+		ctx.JSON(reviewErrors.Response(err, "invalid query parameters"))
+		return
+	}
+
+	page, err := p.reviewRepository.RetrieveReviews(ctx, rentalID, filter)
+	if err != nil {
+		logrus.Error("failed to retrieve reviews from repository: ", err)
+		ctx.JSON(reviewErrors.Response(err, "failed to retrieve reviews"))
+		return
+	}
+
+	reviewsResponse := make([]ReviewResponse, 0, len(page.Reviews))
+	for _, review := range page.Reviews {
+		reviewsResponse = append(reviewsResponse, toReviewResponse(review))
+	}
+
+	ctx.JSON(http.StatusOK, ReviewsResponse{
+		Reviews: reviewsResponse,
+		Meta:    MetaResponse{Total: page.Total, Limit: filter.Limit, Offset: filter.Offset},
+	})
+}
+
+func toReviewResponse(review reviews.Model) ReviewResponse {
+	return ReviewResponse{
+		ID:        review.ID,
+		RentalID:  review.RentalID,
+		UserID:    review.UserID,
+		BookingID: review.BookingID,
+		Rating:    review.Rating,
+		Comment:   review.Comment,
+		Created:   review.Created,
+	}
+}
@@ -0,0 +1,152 @@
+package reviews_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/reviews"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/reviews/mocks"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	r "github.com/nvasilev98/rentals/pkg/repository/postgres/reviews"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Presenter", func() {
+	created := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+
+	var (
+		gomockCtrl     *gomock.Controller
+		mockReviewRepo *mocks.MockReviewRepository
+		presenter      *reviews.Presenter
+		recorder       *httptest.ResponseRecorder
+		mockContext    *gin.Context
+	)
+
+	BeforeEach(func() {
+		gomockCtrl, _ = gomock.WithContext(context.Background(), GinkgoT())
+		mockReviewRepo = mocks.NewMockReviewRepository(gomockCtrl)
+		presenter = reviews.NewPresenter(mockReviewRepo)
+		recorder = httptest.NewRecorder()
+		mockContext, _ = gin.CreateTestContext(recorder)
+		mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
+	})
+
+	AfterEach(func() {
+		gomockCtrl.Finish()
+	})
+
+	Context("CreateReview", func() {
+		newRequest := func(body string) *http.Request {
+			request, _ := http.NewRequest(http.MethodPost, "/rentals/1/reviews", strings.NewReader(body))
+			return request
+		}
+
+		When("the review is by another user than the credentials act for", func() {
+			It("should return http.StatusForbidden without calling the repository", func() {
+				userID := 3
+				request := newRequest(`{"user_id":2,"rating":5}`)
+				mockContext.Request = request.WithContext(auth.WithPrincipal(request.Context(),
+					auth.Principal{Subject: "3", Method: auth.MethodJWT, UserID: &userID}))
+
+				presenter.CreateReview(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		When("the review is for a completed booking", func() {
+			It("should return http.StatusCreated and the review", func() {
+				bookingID := 4
+				mockContext.Request = newRequest(`{"user_id":2,"booking_id":4,"rating":5,"comment":"Spotless"}`)
+				mockReviewRepo.EXPECT().CreateReview(gomock.Any(),
+					r.Input{RentalID: 1, UserID: 2, BookingID: &bookingID, Rating: 5, Comment: "Spotless"}).
+					Return(r.Model{ID: 7, RentalID: 1, UserID: 2, BookingID: &bookingID, Rating: 5, Comment: "Spotless", Created: created}, nil)
+
+				presenter.CreateReview(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusCreated))
+
+				var response reviews.ReviewResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.ID).To(Equal(7))
+				Expect(*response.BookingID).To(Equal(4))
+				Expect(response.Rating).To(Equal(5))
+			})
+		})
+
+		When("the body has unknown fields", func() {
+			It("should return http.StatusBadRequest without calling the repository", func() {
+				mockContext.Request = newRequest(`{"user_id":2,"stars":5}`)
+
+				presenter.CreateReview(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		DescribeTable("creating the review in repository fails with a known error class",
+			func(repoErr error, expectedStatus int, expectedCode string) {
+				mockContext.Request = newRequest(`{"user_id":2,"rating":5}`)
+				mockReviewRepo.EXPECT().CreateReview(gomock.Any(), gomock.Any()).Return(r.Model{}, repoErr)
+
+				presenter.CreateReview(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(expectedStatus))
+
+				var response api.ErrorResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Error.Code).To(Equal(expectedCode))
+			},
+			Entry("second review", postgres.ErrConflict, http.StatusConflict, api.CodeConflict),
+			Entry("rental not found", postgres.ErrNotFound, http.StatusNotFound, api.CodeNotFound),
+			Entry("invalid review", &postgres.ValidationError{Kind: r.ErrInvalidReview,
+				Errors: []postgres.FieldError{{Field: "rating", Reason: postgres.ReasonOutOfRange, Message: "must be between 1 and 5"}}},
+				http.StatusBadRequest, api.CodeValidationFailed),
+			Entry("unavailable database", postgres.ErrUnavailable, http.StatusServiceUnavailable, api.CodeServiceUnavailable),
+		)
+	})
+
+	Context("RetrieveReviews", func() {
+		When("the rental has reviews", func() {
+			It("should return http.StatusOK with a page of them", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/reviews?limit=1", nil)
+				mockReviewRepo.EXPECT().RetrieveReviews(gomock.Any(), 1, r.Filter{Limit: 1}).
+					Return(r.Page{Reviews: []r.Model{{ID: 7, RentalID: 1, UserID: 2, Rating: 4, Created: created}}, Total: 3}, nil)
+
+				presenter.RetrieveReviews(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+
+				var response reviews.ReviewsResponse
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Reviews).To(HaveLen(1))
+				Expect(response.Reviews[0].BookingID).To(BeNil())
+				Expect(response.Meta).To(Equal(reviews.MetaResponse{Total: 3, Limit: 1}))
+			})
+		})
+
+		When("a query parameter is invalid", func() {
+			It("should return http.StatusBadRequest without calling the repository", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/reviews?limit=0", nil)
+
+				presenter.RetrieveReviews(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			It("should return http.StatusNotFound", func() {
+				mockContext.Request, _ = http.NewRequest(http.MethodGet, "/rentals/1/reviews", nil)
+				mockReviewRepo.EXPECT().RetrieveReviews(gomock.Any(), 1, gomock.Any()).Return(r.Page{}, postgres.ErrNotFound)
+
+				presenter.RetrieveReviews(mockContext)
+				Expect(mockContext.Writer.Status()).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+})
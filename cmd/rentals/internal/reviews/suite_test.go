@@ -0,0 +1,13 @@
+package reviews_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReviews(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Reviews Suite")
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presenter.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	users "github.com/nvasilev98/rentals/pkg/repository/postgres/users"
+)
+
+// MockUserRepository is a mock of UserRepository interface.
+type MockUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRepositoryMockRecorder
+}
+
+// MockUserRepositoryMockRecorder is the mock recorder for MockUserRepository.
+type MockUserRepositoryMockRecorder struct {
+	mock *MockUserRepository
+}
+
+// NewMockUserRepository creates a new mock instance.
+func NewMockUserRepository(ctrl *gomock.Controller) *MockUserRepository {
+	mock := &MockUserRepository{ctrl: ctrl}
+	mock.recorder = &MockUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method.
+func (m *MockUserRepository) CreateUser(ctx context.Context, input users.Input) (users.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, input)
+	ret0, _ := ret[0].(users.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserRepositoryMockRecorder) CreateUser(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), ctx, input)
+}
+
+// DeleteUser mocks base method.
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserRepositoryMockRecorder) DeleteUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepository)(nil).DeleteUser), ctx, id)
+}
+
+// PatchUser mocks base method.
+func (m *MockUserRepository) PatchUser(ctx context.Context, id int, patch users.Patch) (users.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchUser", ctx, id, patch)
+	ret0, _ := ret[0].(users.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchUser indicates an expected call of PatchUser.
+func (mr *MockUserRepositoryMockRecorder) PatchUser(ctx, id, patch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchUser", reflect.TypeOf((*MockUserRepository)(nil).PatchUser), ctx, id, patch)
+}
+
+// RetrieveUserByID mocks base method.
+func (m *MockUserRepository) RetrieveUserByID(ctx context.Context, id int) (users.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveUserByID", ctx, id)
+	ret0, _ := ret[0].(users.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveUserByID indicates an expected call of RetrieveUserByID.
+func (mr *MockUserRepositoryMockRecorder) RetrieveUserByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveUserByID", reflect.TypeOf((*MockUserRepository)(nil).RetrieveUserByID), ctx, id)
+}
+
+// RetrieveUsers mocks base method.
+func (m *MockUserRepository) RetrieveUsers(ctx context.Context, filter users.Filter) (users.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveUsers", ctx, filter)
+	ret0, _ := ret[0].(users.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveUsers indicates an expected call of RetrieveUsers.
+func (mr *MockUserRepositoryMockRecorder) RetrieveUsers(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveUsers", reflect.TypeOf((*MockUserRepository)(nil).RetrieveUsers), ctx, filter)
+}
@@ -0,0 +1,30 @@
+package users
+
+type UserResponse struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+type UsersResponse struct {
+	Users []UserResponse `json:"users"`
+	Meta  MetaResponse   `json:"meta"`
+}
+
+type MetaResponse struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// UserRequest is the body of POST /users
+type UserRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// UserPatchRequest is the body of PATCH /users/:id, omitted fields are left unchanged
+type UserPatchRequest struct {
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
+}
@@ -0,0 +1,155 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/authentication"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/httpx"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/users"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=presenter.go --destination mocks/presenter.go --package mocks
+
+// userErrors answers the errors of the user repository
+var userErrors = httpx.Errors{
+	Resource: "user",
+	Conflict: "user still owns rentals or has bookings or reviews",
+	Invalid:  "invalid user",
+	Query:    users.ErrInvalidFilter,
+}
+
+type UserRepository interface {
+	RetrieveUserByID(ctx context.Context, id int) (users.Model, error)
+	RetrieveUsers(ctx context.Context, filter users.Filter) (users.Page, error)
+	CreateUser(ctx context.Context, input users.Input) (users.Model, error)
+	PatchUser(ctx context.Context, id int, patch users.Patch) (users.Model, error)
+	DeleteUser(ctx context.Context, id int) error
+}
+
+type Presenter struct {
+	userRepository UserRepository
+}
+
+// NewPresenter is a constructor function
+func NewPresenter(userRepository UserRepository) *Presenter {
+	return &Presenter{
+		userRepository: userRepository,
+	}
+}
+
+// RetrieveUserByID retrieves a user by a given id
+func (p *Presenter) RetrieveUserByID(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := p.userRepository.RetrieveUserByID(ctx, id)
+	if err != nil {
+		logrus.Error("failed to retrieve user by id from repository: ", err)
+		ctx.JSON(userErrors.Response(err, "failed to retrieve user by id"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toUserResponse(user))
+}
+
+// RetrieveUsers retrieves a page of users ordered by id
+func (p *Presenter) RetrieveUsers(ctx *gin.Context) {
+	filter, err := users.ParseFilter(ctx.Request.URL.Query())
+	if err != nil {
+		logrus.Error("failed to parse users filter: ", err)
+		ctx.JSON(userErrors.Response(err, "invalid query parameters"))
+		return
+	}
+
+	page, err := p.userRepository.RetrieveUsers(ctx, filter)
+	if err != nil {
+		logrus.Error("failed to retrieve users from repository: ", err)
+		ctx.JSON(userErrors.Response(err, "failed to retrieve users"))
+		return
+	}
+
+	usersResponse := make([]UserResponse, 0, len(page.Users))
+	for _, user := range page.Users {
+		usersResponse = append(usersResponse, toUserResponse(user))
+	}
+
+	ctx.JSON(http.StatusOK, UsersResponse{
+		Users: usersResponse,
+		Meta:  MetaResponse{Total: page.Total, Limit: filter.Limit, Offset: filter.Offset},
+	})
+}
+
+// CreateUser creates a user from the request body, only a service may create users since a credential of
+// a user acts for that user alone
+func (p *Presenter) CreateUser(ctx *gin.Context) {
+	if !authentication.AuthorizeService(ctx) {
+		return
+	}
+
+	var request UserRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	user, err := p.userRepository.CreateUser(ctx, users.Input{FirstName: request.FirstName, LastName: request.LastName})
+	if err != nil {
+		logrus.Error("failed to create user in repository: ", err)
+		ctx.JSON(userErrors.Response(err, "failed to create user"))
+		return
+	}
+
+	ctx.Header("Location", fmt.Sprintf("/users/%d", user.ID))
+	ctx.JSON(http.StatusCreated, toUserResponse(user))
+}
+
+// PatchUser changes only the fields present in the request body of a user by a given id
+func (p *Presenter) PatchUser(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok || !authentication.AuthorizeUser(ctx, id) {
+		return
+	}
+
+	var request UserPatchRequest
+	if !httpx.DecodeBody(ctx, &request) {
+		return
+	}
+
+	user, err := p.userRepository.PatchUser(ctx, id, users.Patch{FirstName: request.FirstName, LastName: request.LastName})
+	if err != nil {
+		logrus.Error("failed to patch user in repository: ", err)
+		ctx.JSON(userErrors.Response(err, "failed to patch user"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toUserResponse(user))
+}
+
+// DeleteUser deletes a user by a given id
+func (p *Presenter) DeleteUser(ctx *gin.Context) {
+	id, ok := httpx.ParseID(ctx)
+	if !ok || !authentication.AuthorizeUser(ctx, id) {
+		return
+	}
+
+	if err := p.userRepository.DeleteUser(ctx, id); err != nil {
+		logrus.Error("failed to delete user from repository: ", err)
+		ctx.JSON(userErrors.Response(err, "failed to delete user"))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func toUserResponse(user users.Model) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}
+}
@@ -0,0 +1,206 @@
+package users_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/users"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/users/mocks"
+	"github.com/nvasilev98/rentals/pkg/api"
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	u "github.com/nvasilev98/rentals/pkg/repository/postgres/users"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Presenter", func() {
+	var (
+		gomockCtrl   *gomock.Controller
+		mockUserRepo *mocks.MockUserRepository
+		presenter    *users.Presenter
+		recorder     *httptest.ResponseRecorder
+		mockContext  *gin.Context
+	)
+
+	BeforeEach(func() {
+		gomockCtrl, _ = gomock.WithContext(context.Background(), GinkgoT())
+		mockUserRepo = mocks.NewMockUserRepository(gomockCtrl)
+		presenter = users.NewPresenter(mockUserRepo)
+		recorder = httptest.NewRecorder()
+		mockContext, _ = gin.CreateTestContext(recorder)
+	})
+
+	AfterEach(func() {
+		gomockCtrl.Finish()
+	})
+
+	When("id parameter is not numeric", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/users/abc", nil)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "abc"}}
+		})
+
+		It("should return http.StatusBadRequest without calling the repository", func() {
+			presenter.RetrieveUserByID(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	DescribeTable("retrieving user by id from repository fails with a known error class",
+		func(repoErr error, expectedStatus int, expectedCode string) {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/users/1", nil)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "1"}}
+			mockUserRepo.EXPECT().RetrieveUserByID(gomock.Any(), 1).Return(u.Model{}, repoErr)
+
+			presenter.RetrieveUserByID(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(expectedStatus))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(expectedCode))
+		},
+		Entry("not found", postgres.ErrNotFound, http.StatusNotFound, api.CodeNotFound),
+		Entry("unavailable", postgres.ErrUnavailable, http.StatusServiceUnavailable, api.CodeServiceUnavailable),
+		Entry("timeout", postgres.ErrTimeout, http.StatusGatewayTimeout, api.CodeTimeout),
+	)
+
+	When("retrieving users", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/users?limit=2", nil)
+		})
+
+		It("should return the page with its meta", func() {
+			mockUserRepo.EXPECT().RetrieveUsers(gomock.Any(), u.Filter{Limit: 2}).
+				Return(u.Page{Users: []u.Model{{ID: 1, FirstName: "John", LastName: "Smith"}}, Total: 5}, nil)
+
+			presenter.RetrieveUsers(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			usersResp := users.UsersResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &usersResp)).To(Succeed())
+			Expect(usersResp.Users).To(Equal([]users.UserResponse{{ID: 1, FirstName: "John", LastName: "Smith"}}))
+			Expect(usersResp.Meta).To(Equal(users.MetaResponse{Total: 5, Limit: 2}))
+		})
+	})
+
+	When("retrieving users with invalid query parameters", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodGet, "/users?limit=0", nil)
+		})
+
+		It("should return http.StatusBadRequest listing the invalid parameters", func() {
+			presenter.RetrieveUsers(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp.Error.Code).To(Equal(api.CodeValidationFailed))
+			Expect(errResp.Error.Details).To(HaveLen(1))
+			Expect(errResp.Error.Details[0].Field).To(Equal("limit"))
+		})
+	})
+
+	When("creating a user", func() {
+		BeforeEach(func() {
+			body := `{"first_name":"Ada","last_name":"Lovelace"}`
+			mockContext.Request, _ = http.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+		})
+
+		It("should return http.StatusCreated with the location of the new user", func() {
+			mockUserRepo.EXPECT().CreateUser(gomock.Any(), u.Input{FirstName: "Ada", LastName: "Lovelace"}).
+				Return(u.Model{ID: 6, FirstName: "Ada", LastName: "Lovelace"}, nil)
+
+			presenter.CreateUser(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusCreated))
+			Expect(recorder.Header().Get("Location")).To(Equal("/users/6"))
+		})
+	})
+
+	When("a user creates a user", func() {
+		BeforeEach(func() {
+			userID := 3
+			body := `{"first_name":"Ada","last_name":"Lovelace"}`
+			request, _ := http.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+			mockContext.Request = request.WithContext(auth.WithPrincipal(request.Context(),
+				auth.Principal{Subject: "3", Method: auth.MethodJWT, UserID: &userID}))
+		})
+
+		It("should return http.StatusForbidden without calling the repository", func() {
+			presenter.CreateUser(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusForbidden))
+
+			errResp := api.ErrorResponse{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &errResp)).To(Succeed())
+			Expect(errResp).To(Equal(api.NewForbiddenResponse()))
+		})
+	})
+
+	When("a service creates a user", func() {
+		BeforeEach(func() {
+			body := `{"first_name":"Ada","last_name":"Lovelace"}`
+			request, _ := http.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+			mockContext.Request = request.WithContext(auth.WithPrincipal(request.Context(),
+				auth.Principal{Subject: "api-key:1", Method: auth.MethodAPIKey}))
+		})
+
+		It("should return http.StatusCreated", func() {
+			mockUserRepo.EXPECT().CreateUser(gomock.Any(), u.Input{FirstName: "Ada", LastName: "Lovelace"}).
+				Return(u.Model{ID: 6, FirstName: "Ada", LastName: "Lovelace"}, nil)
+
+			presenter.CreateUser(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusCreated))
+		})
+	})
+
+	When("patching a user", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodPatch, "/users/2", strings.NewReader(`{"last_name":"Doe-Smith"}`))
+			mockContext.Params = []gin.Param{{Key: "id", Value: "2"}}
+		})
+
+		It("should pass only the fields present in the body", func() {
+			mockUserRepo.EXPECT().PatchUser(gomock.Any(), 2, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ int, patch u.Patch) (u.Model, error) {
+					Expect(patch.FirstName).To(BeNil())
+					Expect(*patch.LastName).To(Equal("Doe-Smith"))
+					return u.Model{ID: 2, FirstName: "Jane", LastName: "Doe-Smith"}, nil
+				})
+
+			presenter.PatchUser(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+		})
+	})
+
+	When("deleting a user who still owns rentals or has bookings or reviews", func() {
+		BeforeEach(func() {
+			mockContext.Request, _ = http.NewRequest(http.MethodDelete, "/users/2", nil)
+			mockContext.Params = []gin.Param{{Key: "id", Value: "2"}}
+		})
+
+		It("should return http.StatusConflict", func() {
+			mockUserRepo.EXPECT().DeleteUser(gomock.Any(), 2).Return(postgres.ErrConflict)
+
+			presenter.DeleteUser(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusConflict))
+		})
+	})
+
+	When("deleting another user than the credentials act for", func() {
+		BeforeEach(func() {
+			userID := 3
+			request, _ := http.NewRequest(http.MethodDelete, "/users/2", nil)
+			mockContext.Request = request.WithContext(auth.WithPrincipal(request.Context(),
+				auth.Principal{Subject: "api-key:1", Method: auth.MethodAPIKey, UserID: &userID}))
+			mockContext.Params = []gin.Param{{Key: "id", Value: "2"}}
+		})
+
+		It("should return http.StatusForbidden without calling the repository", func() {
+			presenter.DeleteUser(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusForbidden))
+		})
+	})
+})
@@ -1,79 +1,58 @@
 package main
 
 import (
-	"context"
 	"fmt"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"sort"
+	"strings"
 
-	"github.com/gin-gonic/gin"
-	"github.com/nvasilev98/rentals/cmd/rentals/env"
-	"github.com/nvasilev98/rentals/cmd/rentals/internal/rentals"
-	"github.com/nvasilev98/rentals/pkg/repository/postgres"
-	r "github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
 	"github.com/sirupsen/logrus"
 )
 
-const timeout = 1000000
+type command struct {
+	usage string
+	run   func(args []string) error
+}
 
-func main() {
-	appConfig, err := env.LoadAppConfig()
-	if err != nil {
-		logrus.Fatal(err)
-	}
+// commands lists the lifecycle tasks of the rentals binary, serve runs when no command is given
+var commands = map[string]command{
+	"serve":        {usage: "start the http server", run: serve},
+	"migrate":      {usage: "apply, revert or list schema migrations: migrate up | down [steps] | status", run: migrate},
+	"seed":         {usage: "load fixtures into the database: seed [-file fixtures/sample-data.sql]", run: seed},
+	"check-config": {usage: "validate the configuration and test database connectivity", run: checkConfig},
+	"rates":        {usage: "load exchange rates into the database: rates [-file fixtures/exchange-rates.csv]", run: rates},
+	"apikeys":      {usage: "create or revoke api keys: apikeys create -name <name> [-user <id>] | revoke -id <id>", run: apiKeys},
+}
 
-	dbConfig, err := postgres.LoadDBConfig()
-	if err != nil {
-		logrus.Fatal(err)
+func main() {
+	name, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		name, args = os.Args[1], os.Args[2:]
 	}
 
-	dbClient, err := postgres.Connect(dbConfig)
-	if err != nil {
-		logrus.Fatal(err)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage())
+		os.Exit(2)
 	}
 
-	rentalsRepository, err := r.NewRepository(dbClient)
-	if err != nil {
+	if err := cmd.run(args); err != nil {
 		logrus.Fatal(err)
 	}
+}
 
-	handler := gin.Default()
-	presenter := rentals.NewPresenter(rentalsRepository)
-
-	handler.GET("/rentals/:id", presenter.RetrieveRentalByID)
-	handler.GET("/rentals", presenter.RetrieveRentals)
-
-	httpServer := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", appConfig.Host, appConfig.Port),
-		Handler: handler,
-	}
-
-	go func() {
-		if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
-			if err != nil {
-				logrus.Fatal(err)
-			}
-		}
-	}()
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan,
-		syscall.SIGINT,
-		syscall.SIGTERM,
-		syscall.SIGQUIT)
-	<-sigChan
-	signal.Stop(sigChan)
-
-	shutdownCtx, shutdownCancelFunc := context.WithTimeout(context.Background(), timeout)
-	defer shutdownCancelFunc()
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		logrus.Fatal("failed to gracefully shutdown http server")
+func usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	if err := rentalsRepository.Close(); err != nil {
-		logrus.Fatal(err)
+	var builder strings.Builder
+	builder.WriteString("usage: rentals <command> [arguments]\n\ncommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&builder, "  %-14s %s\n", name, commands[name].usage)
 	}
 
+	return builder.String()
 }
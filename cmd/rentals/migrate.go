@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/sirupsen/logrus"
+)
+
+const migrateUsage = "usage: rentals migrate [up | down [steps] | status]"
+
+// migrate applies, reverts or lists schema migrations, it applies every pending migration without arguments
+func migrate(args []string) error {
+	direction, steps, err := parseMigrateArgs(args)
+	if err != nil {
+		return err
+	}
+
+	dbClient, err := connectDB()
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	migrator, err := postgres.NewMigrator(dbClient)
+	if err != nil {
+		return err
+	}
+
+	if direction == "status" {
+		return printMigrationStatus(migrator)
+	}
+
+	if direction == "down" {
+		reverted, err := migrator.Down(context.Background(), steps)
+		for _, migration := range reverted {
+			logrus.Infof("reverted migration %d_%s", migration.Version, migration.Name)
+		}
+
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		logrus.Infof("applied migration %d_%s", migration.Version, migration.Name)
+	}
+
+	if err == nil && len(applied) == 0 {
+		logrus.Info("schema is up to date")
+	}
+
+	return err
+}
+
+func parseMigrateArgs(args []string) (string, int, error) {
+	if len(args) == 0 {
+		return "up", 0, nil
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		return "up", 0, nil
+	case args[0] == "status" && len(args) == 1:
+		return "status", 0, nil
+	case args[0] == "down" && len(args) == 1:
+		return "down", 1, nil
+	case args[0] == "down" && len(args) == 2:
+		steps, err := strconv.Atoi(args[1])
+		if err != nil || steps <= 0 {
+			return "", 0, fmt.Errorf("steps must be a positive integer, %s", migrateUsage)
+		}
+
+		return "down", steps, nil
+	default:
+		return "", 0, errors.New(migrateUsage)
+	}
+}
+
+func printMigrationStatus(migrator *postgres.Migrator) error {
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		name, appliedAt := status.Name, "pending"
+		if name == "" {
+			name = "unknown to this binary"
+		}
+
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, name, appliedAt)
+	}
+
+	return writer.Flush()
+}
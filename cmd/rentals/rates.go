@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/currencies"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultRates = "fixtures/exchange-rates.csv"
+
+// rates loads exchange rates from a csv file into a migrated database
+func rates(args []string) error {
+	flags := flag.NewFlagSet("rates", flag.ExitOnError)
+	file := flags.String("file", defaultRates, "csv file with a currency,minor_units,rate_per_usd header")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ratesFile, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("failed to open exchange rates: %w", err)
+	}
+	defer ratesFile.Close()
+
+	exchangeRates, err := currencies.ReadCSV(ratesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read exchange rates from %s: %w", *file, err)
+	}
+
+	dbClient, err := connectDB()
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	if err := currencies.NewRepository(dbClient).UpsertRates(context.Background(), exchangeRates); err != nil {
+		return err
+	}
+
+	logrus.Infof("loaded %d exchange rates from %s", len(exchangeRates), *file)
+	return nil
+}
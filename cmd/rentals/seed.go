@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultFixtures = "fixtures/sample-data.sql"
+
+// seed loads fixtures into a migrated database
+func seed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	file := flags.String("file", defaultFixtures, "sql file with the fixtures to load")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	fixtures, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	dbClient, err := connectDB()
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	if err := postgres.Seed(context.Background(), dbClient, string(fixtures)); err != nil {
+		return err
+	}
+
+	logrus.Infof("loaded fixtures from %s", *file)
+	return nil
+}
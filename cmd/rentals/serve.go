@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nvasilev98/rentals/cmd/rentals/env"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/amenities"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/authentication"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/bookings"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/pricing"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/rentals"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/reviews"
+	"github.com/nvasilev98/rentals/cmd/rentals/internal/users"
+	"github.com/nvasilev98/rentals/pkg/auth"
+	"github.com/nvasilev98/rentals/pkg/repository/cache"
+	"github.com/nvasilev98/rentals/pkg/repository/memory"
+	a "github.com/nvasilev98/rentals/pkg/repository/postgres/amenities"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/apikeys"
+	b "github.com/nvasilev98/rentals/pkg/repository/postgres/bookings"
+	p "github.com/nvasilev98/rentals/pkg/repository/postgres/pricing"
+	r "github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+	rv "github.com/nvasilev98/rentals/pkg/repository/postgres/reviews"
+	u "github.com/nvasilev98/rentals/pkg/repository/postgres/users"
+	"github.com/sirupsen/logrus"
+)
+
+const timeout = 1000000
+
+// serve starts the http server and blocks until the process is asked to stop
+func serve(args []string) error {
+	if err := flag.NewFlagSet("serve", flag.ExitOnError).Parse(args); err != nil {
+		return err
+	}
+
+	appConfig, err := loadAppConfig()
+	if err != nil {
+		return err
+	}
+
+	handler := gin.Default()
+	closeRepositories, err := registerRoutes(handler, appConfig)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", appConfig.Host, appConfig.Port),
+		Handler: handler,
+	}
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		}
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan,
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGQUIT)
+	<-sigChan
+	signal.Stop(sigChan)
+
+	shutdownCtx, shutdownCancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer shutdownCancelFunc()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to gracefully shutdown http server: %w", err)
+	}
+
+	return closeRepositories()
+}
+
+// registerRoutes registers the handlers backed by the configured repository and returns a func releasing it
+func registerRoutes(handler *gin.Engine, appConfig env.AppConfig) (func() error, error) {
+	if appConfig.Repository == env.RepositoryMemory {
+		return registerMemoryRoutes(handler, appConfig)
+	}
+
+	return registerPostgresRoutes(handler, appConfig)
+}
+
+// registerMemoryRoutes serves only the rentals from the seed file, nothing is persisted across restarts
+func registerMemoryRoutes(handler *gin.Engine, appConfig env.AppConfig) (func() error, error) {
+	seedFile := appConfig.SeedFile
+	seed, err := memory.ReadSeedFile(seedFile)
+	if err != nil {
+		return nil, err
+	}
+
+	rentalsRepository, err := memory.NewRentalRepository(seed)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Warnf("serving %d rentals from %s in memory, only the rentals routes are available", len(seed.Rentals), seedFile)
+
+	if err := useAuthentication(handler, appConfig, nil); err != nil {
+		return nil, err
+	}
+
+	registerRentalRoutes(handler, rentals.NewPresenter(rentalsRepository), authentication.NewAuthorizer(rentalsRepository))
+	return rentalsRepository.Close, nil
+}
+
+func registerPostgresRoutes(handler *gin.Engine, appConfig env.AppConfig) (func() error, error) {
+	dbClient, err := connectDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rentalsRepository, err := r.NewRepository(dbClient)
+	if err != nil {
+		dbClient.Close()
+		return nil, err
+	}
+
+	usersRepository, err := u.NewRepository(dbClient)
+	if err != nil {
+		rentalsRepository.Close()
+		dbClient.Close()
+		return nil, err
+	}
+
+	if err := useAuthentication(handler, appConfig, apikeys.NewRepository(dbClient)); err != nil {
+		usersRepository.Close()
+		rentalsRepository.Close()
+		dbClient.Close()
+		return nil, err
+	}
+
+	var presenter *rentals.Presenter
+	if appConfig.CacheSize > 0 {
+		cachedRentals := cache.NewRentalRepository(rentalsRepository, cache.NewLRU(appConfig.CacheSize), appConfig.CacheTTL)
+		handler.Use(invalidateOnWrite(cachedRentals))
+		presenter = rentals.NewPresenter(cachedRentals)
+	} else {
+		presenter = rentals.NewPresenter(rentalsRepository)
+	}
+	usersPresenter := users.NewPresenter(usersRepository)
+	bookingsPresenter := bookings.NewPresenter(b.NewRepository(dbClient))
+	pricingPresenter := pricing.NewPresenter(p.NewRepository(dbClient))
+	reviewsPresenter := reviews.NewPresenter(rv.NewRepository(dbClient))
+	amenitiesPresenter := amenities.NewPresenter(a.NewRepository(dbClient))
+
+	// owners are read around the cache, a stale owner must not authorize a write
+	authorizer := authentication.NewAuthorizer(rentalsRepository)
+	registerRentalRoutes(handler, presenter, authorizer)
+	handler.POST("/rentals/:id/bookings", bookingsPresenter.CreateBooking)
+	handler.GET("/rentals/:id/availability", bookingsPresenter.RetrieveAvailability)
+	handler.GET("/rentals/:id/quote", pricingPresenter.RetrieveQuote)
+	handler.GET("/rentals/:id/seasons", pricingPresenter.RetrieveSeasons)
+	handler.PUT("/rentals/:id/seasons", authorizer.RequireRentalOwner, pricingPresenter.ReplaceSeasons)
+	handler.POST("/rentals/:id/reviews", reviewsPresenter.CreateReview)
+	handler.GET("/rentals/:id/reviews", reviewsPresenter.RetrieveReviews)
+	handler.PUT("/rentals/:id/amenities", authorizer.RequireRentalOwner, amenitiesPresenter.ReplaceRentalAmenities)
+
+	handler.GET("/amenities", amenitiesPresenter.RetrieveAmenities)
+	handler.POST("/amenities", authentication.RequireService, amenitiesPresenter.CreateAmenity)
+	handler.GET("/amenities/:id", amenitiesPresenter.RetrieveAmenityByID)
+	handler.PATCH("/amenities/:id", authentication.RequireService, amenitiesPresenter.PatchAmenity)
+	handler.DELETE("/amenities/:id", authentication.RequireService, amenitiesPresenter.DeleteAmenity)
+
+	handler.GET("/users", usersPresenter.RetrieveUsers)
+	handler.POST("/users", usersPresenter.CreateUser)
+	handler.GET("/users/:id", usersPresenter.RetrieveUserByID)
+	handler.PATCH("/users/:id", usersPresenter.PatchUser)
+	handler.DELETE("/users/:id", usersPresenter.DeleteUser)
+
+	return func() error {
+		defer dbClient.Close()
+		if err := rentalsRepository.Close(); err != nil {
+			return err
+		}
+
+		return usersRepository.Close()
+	}, nil
+}
+
+// useAuthentication requires credentials on every route registered afterwards when AUTH_ENABLED is true,
+// apiKeys is nil when there is no database to look api keys up in
+func useAuthentication(handler *gin.Engine, appConfig env.AppConfig, apiKeys authentication.APIKeyRepository) error {
+	if !appConfig.AuthEnabled {
+		logrus.Warn("authentication is disabled, every route is anonymous")
+		return nil
+	}
+
+	var verifier authentication.TokenVerifier
+	if appConfig.JWKSFile != "" {
+		keys, err := auth.ReadJWKSFile(appConfig.JWKSFile)
+		if err != nil {
+			return err
+		}
+
+		verifier = auth.NewVerifier(keys, appConfig.JWTIssuer, appConfig.JWTAudience)
+	}
+
+	handler.Use(authentication.NewMiddleware(verifier, apiKeys).Authenticate)
+	return nil
+}
+
+// invalidateOnWrite drops the cached rentals on every successful write, since users, reviews, amenities
+// and bookings are read together with rentals but written around the cache. It happens before the response
+// is sent, so a client never reads its own write from a stale cache.
+func invalidateOnWrite(cachedRentals *cache.RentalRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead {
+			ctx.Next()
+			return
+		}
+
+		writer := &invalidatingWriter{ResponseWriter: ctx.Writer, invalidate: func() {
+			cachedRentals.Invalidate(ctx.Request.Context())
+		}}
+		ctx.Writer = writer
+		ctx.Next()
+
+		// a response without a body, such as 204, is only sent by gin after the handlers return
+		writer.beforeSend()
+	}
+}
+
+// invalidatingWriter calls invalidate once, before the response is sent, unless its status is an error
+type invalidatingWriter struct {
+	gin.ResponseWriter
+	invalidate func()
+	done       bool
+}
+
+func (w *invalidatingWriter) beforeSend() {
+	if w.done {
+		return
+	}
+
+	w.done = true
+	if w.Status() < http.StatusBadRequest {
+		w.invalidate()
+	}
+}
+
+func (w *invalidatingWriter) WriteHeaderNow() {
+	w.beforeSend()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *invalidatingWriter) Write(data []byte) (int, error) {
+	w.beforeSend()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *invalidatingWriter) WriteString(s string) (int, error) {
+	w.beforeSend()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *invalidatingWriter) Flush() {
+	w.beforeSend()
+	w.ResponseWriter.Flush()
+}
+
+func registerRentalRoutes(handler *gin.Engine, presenter *rentals.Presenter, authorizer *authentication.Authorizer) {
+	handler.GET("/rentals/:id", presenter.RetrieveRentalByID)
+	handler.GET("/rentals", presenter.RetrieveRentals)
+	handler.POST("/rentals", presenter.CreateRental)
+	handler.PUT("/rentals/:id", authorizer.RequireRentalOwner, presenter.UpdateRental)
+	handler.PATCH("/rentals/:id", authorizer.RequireRentalOwner, presenter.PatchRental)
+	handler.DELETE("/rentals/:id", authorizer.RequireRentalOwner, presenter.DeleteRental)
+	handler.GET("/users/:id/rentals", presenter.RetrieveUserRentals)
+}
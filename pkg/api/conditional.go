@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotModified evaluates the If-None-Match and If-Modified-Since headers of a GET or HEAD request against
+// the validators of the selected representation, as in RFC 9110 section 13.2.2. If-Modified-Since is
+// ignored when If-None-Match is sent or lastModified is zero.
+func NotModified(request *http.Request, etag string, lastModified time.Time) bool {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := request.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return MatchETag(ifNoneMatch, etag, false)
+	}
+
+	ifModifiedSince := request.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+
+	// http dates have a resolution of a second
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// MatchETag reports whether an If-Match or If-None-Match header lists etag or is "*". The strong comparison
+// of If-Match fails for weak tags, the weak comparison of If-None-Match ignores the W/ prefix.
+func MatchETag(header, etag string, strong bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strong {
+			if !isWeak(candidate) && !isWeak(etag) && candidate == etag {
+				return true
+			}
+
+			continue
+		}
+
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetValidators sets the ETag and, unless it is zero, the Last-Modified header of a response
+func SetValidators(header http.Header, etag string, lastModified time.Time) {
+	header.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+func isWeak(etag string) bool {
+	return strings.HasPrefix(etag, "W/")
+}
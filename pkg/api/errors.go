@@ -1,15 +1,70 @@
 package api
 
+// Machine-readable error codes, clients may rely on them not changing
+const (
+	CodeValidationFailed   = "validation_failed"
+	CodeInvalidParameter   = "invalid_parameter"
+	CodeOutOfRange         = "out_of_range"
+	CodeUnknownParameter   = "unknown_parameter"
+	CodeInvalidBody        = "invalid_body"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodePreconditionFailed = "precondition_failed"
+	CodeServiceUnavailable = "service_unavailable"
+	CodeTimeout            = "timeout"
+	CodeInternal           = "internal_error"
+)
+
 type Error struct {
-	Message string `json:"message"`
+	Code    string  `json:"code,omitempty"`
+	Message string  `json:"message"`
+	Field   string  `json:"field,omitempty"`
+	Details []Error `json:"details,omitempty"`
 }
 
 type ErrorResponse struct {
 	Error Error `json:"error"`
 }
 
-func NewErrorResponse(message string) ErrorResponse {
+func NewErrorResponse(code, message string) ErrorResponse {
 	return ErrorResponse{
-		Error: Error{message},
+		Error: Error{Code: code, Message: message},
+	}
+}
+
+// BearerChallenge is the WWW-Authenticate header of every 401 response
+const BearerChallenge = `Bearer realm="rentals"`
+
+// NewUnauthorizedResponse is the body of every 401 response, it does not tell a missing credential from
+// an invalid one
+func NewUnauthorizedResponse() ErrorResponse {
+	return NewErrorResponse(CodeUnauthorized, "missing or invalid credentials")
+}
+
+// NewForbiddenResponse is the body of every 403 response, the credentials are valid but may not act for the
+// user the request is about
+func NewForbiddenResponse() ErrorResponse {
+	return NewErrorResponse(CodeForbidden, "credentials may not act for this user")
+}
+
+// NewValidationErrorResponse builds an error response listing every invalid field
+func NewValidationErrorResponse(message string, details []Error) ErrorResponse {
+	return ErrorResponse{
+		Error: Error{
+			Code:    CodeValidationFailed,
+			Message: message,
+			Details: details,
+		},
+	}
+}
+
+// NewFieldError describes a problem with a single request field
+func NewFieldError(code, field, message string) Error {
+	return Error{
+		Code:    code,
+		Message: message,
+		Field:   field,
 	}
 }
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// APIKeyPrefix starts every api key, so keys are told apart from JWTs and found by secret scanners
+const APIKeyPrefix = "rk_"
+
+// apiKeyBytes is the entropy of an api key, enough for a single unsalted sha256 to be safe to store
+const apiKeyBytes = 32
+
+// GenerateAPIKey returns a new random api key, only its hash is meant to be stored
+func GenerateAPIKey() (string, error) {
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	return APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashAPIKey returns the hex sha256 an api key is stored and looked up by
+func HashAPIKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:])
+}
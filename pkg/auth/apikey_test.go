@@ -0,0 +1,24 @@
+package auth_test
+
+import (
+	"github.com/nvasilev98/rentals/pkg/auth"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("API keys", func() {
+	It("should generate distinct prefixed keys", func() {
+		first, err := auth.GenerateAPIKey()
+		Expect(err).ToNot(HaveOccurred())
+		second, err := auth.GenerateAPIKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(first).To(HavePrefix(auth.APIKeyPrefix))
+		Expect(first).To(HaveLen(len(auth.APIKeyPrefix) + 43))
+		Expect(first).ToNot(Equal(second))
+	})
+
+	It("should hash a key to its hex sha256", func() {
+		Expect(auth.HashAPIKey("test")).To(Equal("9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"))
+	})
+})
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Algorithms accepted in the alg header of a JWT, none and every other algorithm are rejected
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+const (
+	minSecretBytes = 32
+	minRSABits     = 2048
+)
+
+// ErrInvalidKeySet is returned when a JWKS document has no usable signing key
+var ErrInvalidKeySet = errors.New("invalid key set")
+
+// Key verifies the signatures of a single algorithm, an oct key those of HS256 and an RSA key those of RS256
+type Key struct {
+	ID        string
+	Algorithm string
+	secret    []byte
+	public    *rsa.PublicKey
+}
+
+// KeySet holds the keys of a JWKS document, RFC 7517
+type KeySet struct {
+	Keys []Key
+}
+
+type jwk struct {
+	KeyType   string `json:"kty"`
+	ID        string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	K         string `json:"k"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// ReadJWKSFile reads a JWKS document from a local file
+func ReadJWKSFile(path string) (KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return KeySet{}, fmt.Errorf("failed to read jwks file: %w", err)
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return KeySet{}, fmt.Errorf("jwks file %s: %w", path, err)
+	}
+
+	return keys, nil
+}
+
+// ParseJWKS parses the oct and RSA signing keys of a JWKS document. Encryption keys and keys of other
+// algorithms are skipped, malformed or weak keys fail the whole document.
+func ParseJWKS(data []byte) (KeySet, error) {
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return KeySet{}, fmt.Errorf("%w: %v", ErrInvalidKeySet, err)
+	}
+
+	var keys KeySet
+	for i, raw := range document.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			continue
+		}
+
+		key, ok, err := parseKey(raw)
+		if err != nil {
+			return KeySet{}, fmt.Errorf("%w: key %d: %v", ErrInvalidKeySet, i, err)
+		}
+
+		if ok {
+			keys.Keys = append(keys.Keys, key)
+		}
+	}
+
+	if len(keys.Keys) == 0 {
+		return KeySet{}, fmt.Errorf("%w: no %s or %s signing key", ErrInvalidKeySet, AlgorithmHS256, AlgorithmRS256)
+	}
+
+	return keys, nil
+}
+
+// parseKey returns ok false for a key of another type or algorithm
+func parseKey(raw jwk) (Key, bool, error) {
+	switch raw.KeyType {
+	case "oct":
+		if raw.Algorithm != "" && raw.Algorithm != AlgorithmHS256 {
+			return Key{}, false, nil
+		}
+
+		secret, err := base64.RawURLEncoding.DecodeString(raw.K)
+		if err != nil {
+			return Key{}, false, fmt.Errorf("invalid k: %v", err)
+		}
+
+		if len(secret) < minSecretBytes {
+			return Key{}, false, fmt.Errorf("secret must have at least %d bytes", minSecretBytes)
+		}
+
+		return Key{ID: raw.ID, Algorithm: AlgorithmHS256, secret: secret}, true, nil
+	case "RSA":
+		if raw.Algorithm != "" && raw.Algorithm != AlgorithmRS256 {
+			return Key{}, false, nil
+		}
+
+		modulus, err := base64.RawURLEncoding.DecodeString(raw.N)
+		if err != nil {
+			return Key{}, false, fmt.Errorf("invalid n: %v", err)
+		}
+
+		exponent, err := base64.RawURLEncoding.DecodeString(raw.E)
+		if err != nil {
+			return Key{}, false, fmt.Errorf("invalid e: %v", err)
+		}
+
+		if len(exponent) == 0 || len(exponent) > 4 {
+			return Key{}, false, fmt.Errorf("invalid e")
+		}
+
+		public := &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(new(big.Int).SetBytes(exponent).Int64())}
+		if public.N.BitLen() < minRSABits {
+			return Key{}, false, fmt.Errorf("modulus must have at least %d bits", minRSABits)
+		}
+
+		if public.E < 3 || public.E%2 == 0 {
+			return Key{}, false, fmt.Errorf("invalid exponent %d", public.E)
+		}
+
+		return Key{ID: raw.ID, Algorithm: AlgorithmRS256, public: public}, true, nil
+	default:
+		return Key{}, false, nil
+	}
+}
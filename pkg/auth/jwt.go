@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// leeway tolerates the clock skew between the issuer and the server when exp and nbf are checked
+const leeway = 30 * time.Second
+
+// ErrInvalidToken is returned when a JWT is malformed, not signed by a known key, expired or not meant for us
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier verifies HS256 and RS256 JWTs against a key set, RFC 7519
+type Verifier struct {
+	keys     KeySet
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewVerifier is a constructor function, the iss and aud claims are only checked when issuer and audience are set
+func NewVerifier(keys KeySet, issuer, audience string) *Verifier {
+	return &Verifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	Scope     string   `json:"scope"`
+}
+
+// audience is a single string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings")
+	}
+
+	*a = multiple
+	return nil
+}
+
+// Verify checks the signature and the sub, exp, nbf, iss and aud claims of a compact JWT.
+// Every failure is an ErrInvalidToken, the wrapped message tells why.
+func (v *Verifier) Verify(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("%w: expected 3 parts, got %d", ErrInvalidToken, len(parts))
+	}
+
+	var tokenHeader header
+	if err := decodePart(parts[0], &tokenHeader); err != nil {
+		return Principal{}, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+
+	if !v.verifySignature(tokenHeader, parts[0]+"."+parts[1], signature) {
+		return Principal{}, fmt.Errorf("%w: no %q key with id %q verifies the signature",
+			ErrInvalidToken, tokenHeader.Algorithm, tokenHeader.KeyID)
+	}
+
+	var tokenClaims claims
+	if err := decodePart(parts[1], &tokenClaims); err != nil {
+		return Principal{}, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+
+	if err := v.checkClaims(tokenClaims); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return Principal{Subject: tokenClaims.Subject, Method: MethodJWT, UserID: userID(tokenClaims.Subject),
+		Scopes: strings.Fields(tokenClaims.Scope)}, nil
+}
+
+// userID parses a sub that is the decimal id of a user, other subjects are not users of the service
+func userID(subject string) *int {
+	id, err := strconv.Atoi(subject)
+	if err != nil || id <= 0 || strconv.Itoa(id) != subject {
+		return nil
+	}
+
+	return &id
+}
+
+// verifySignature tries the keys of the alg header, only the key with the kid header when it is set.
+// The algorithm of a key is fixed by its type, so an RSA public key is never used as an HS256 secret.
+func (v *Verifier) verifySignature(tokenHeader header, signingInput string, signature []byte) bool {
+	for _, key := range v.keys.Keys {
+		if key.Algorithm != tokenHeader.Algorithm || (tokenHeader.KeyID != "" && key.ID != tokenHeader.KeyID) {
+			continue
+		}
+
+		if key.verify(signingInput, signature) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *Verifier) checkClaims(tokenClaims claims) error {
+	now := v.now()
+	if tokenClaims.Subject == "" {
+		return errors.New("sub is missing")
+	}
+
+	if tokenClaims.ExpiresAt == nil {
+		return errors.New("exp is missing")
+	}
+
+	if !now.Add(-leeway).Before(toTime(*tokenClaims.ExpiresAt)) {
+		return errors.New("token is expired")
+	}
+
+	if tokenClaims.NotBefore != nil && now.Add(leeway).Before(toTime(*tokenClaims.NotBefore)) {
+		return errors.New("token is not valid yet")
+	}
+
+	if v.issuer != "" && tokenClaims.Issuer != v.issuer {
+		return fmt.Errorf("unexpected iss %q", tokenClaims.Issuer)
+	}
+
+	if v.audience != "" && !contains(tokenClaims.Audience, v.audience) {
+		return fmt.Errorf("aud does not include %q", v.audience)
+	}
+
+	return nil
+}
+
+func (k Key) verify(signingInput string, signature []byte) bool {
+	switch k.Algorithm {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(mac.Sum(nil), signature)
+	case AlgorithmRS256:
+		digest := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(k.public, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+func decodePart(part string, target interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(decoded, target)
+}
+
+// toTime converts a NumericDate, seconds since the epoch that may have a fraction
+func toTime(seconds float64) time.Time {
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second)))
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,190 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/auth"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func encode(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	Expect(err).ToNot(HaveOccurred())
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func signHS256(header, claims map[string]interface{}, key string) string {
+	signingInput := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(header, claims map[string]interface{}, key *rsa.PrivateKey) string {
+	signingInput := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	Expect(err).ToNot(HaveOccurred())
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+var _ = Describe("JWT", func() {
+	var (
+		rsaKey   *rsa.PrivateKey
+		keys     auth.KeySet
+		verifier *auth.Verifier
+		claims   map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		var err error
+		if rsaKey == nil {
+			// generating a key takes long, every spec shares one
+			rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		keys, err = auth.ParseJWKS([]byte(fmt.Sprintf(`{"keys":[
+			{"kty":"oct","kid":"shared","alg":"HS256","k":%q},
+			{"kty":"RSA","kid":"rsa-1","use":"sig","n":%q,"e":%q},
+			{"kty":"RSA","kid":"encryption","use":"enc","n":"AQAB","e":"AQAB"},
+			{"kty":"EC","kid":"ec-1","crv":"P-256","x":"","y":""}
+		]}`, base64.RawURLEncoding.EncodeToString([]byte(secret)),
+			base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()))))
+		Expect(err).ToNot(HaveOccurred())
+
+		verifier = auth.NewVerifier(keys, "https://issuer.example", "rentals")
+		claims = map[string]interface{}{
+			"sub":   "user-42",
+			"iss":   "https://issuer.example",
+			"aud":   []string{"billing", "rentals"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "rentals:read rentals:write",
+		}
+	})
+
+	Context("ParseJWKS", func() {
+		It("should keep only the HS256 and RS256 signing keys", func() {
+			Expect(keys.Keys).To(HaveLen(2))
+			Expect(keys.Keys[0].Algorithm).To(Equal(auth.AlgorithmHS256))
+			Expect(keys.Keys[1].Algorithm).To(Equal(auth.AlgorithmRS256))
+		})
+
+		DescribeTable("should reject unusable key sets",
+			func(document string) {
+				_, err := auth.ParseJWKS([]byte(document))
+				Expect(err).To(MatchError(auth.ErrInvalidKeySet))
+			},
+			Entry("malformed json", `{"keys":`),
+			Entry("no signing keys", `{"keys":[{"kty":"EC"}]}`),
+			Entry("short secret", `{"keys":[{"kty":"oct","k":"c2hvcnQ"}]}`),
+			Entry("small modulus", `{"keys":[{"kty":"RSA","n":"AQAB","e":"AQAB"}]}`),
+		)
+
+		It("should read a key set from a file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "jwks.json")
+			Expect(os.WriteFile(path, []byte(fmt.Sprintf(`{"keys":[{"kty":"oct","k":%q}]}`,
+				base64.RawURLEncoding.EncodeToString([]byte(secret)))), 0o600)).To(Succeed())
+
+			fileKeys, err := auth.ReadJWKSFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileKeys.Keys).To(HaveLen(1))
+		})
+	})
+
+	Context("Verify", func() {
+		It("should verify an HS256 token and return its principal", func() {
+			principal, err := verifier.Verify(signHS256(map[string]interface{}{"alg": "HS256", "typ": "JWT"}, claims, secret))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(principal).To(Equal(auth.Principal{Subject: "user-42", Method: auth.MethodJWT,
+				Scopes: []string{"rentals:read", "rentals:write"}}))
+		})
+
+		It("should verify an RS256 token signed by the key of its kid", func() {
+			principal, err := verifier.Verify(signRS256(map[string]interface{}{"alg": "RS256", "kid": "rsa-1"}, claims, rsaKey))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(principal.Subject).To(Equal("user-42"))
+		})
+
+		DescribeTable("should take the user id from a sub that is one",
+			func(subject string, userID *int) {
+				claims["sub"] = subject
+				principal, err := verifier.Verify(signHS256(map[string]interface{}{"alg": "HS256"}, claims, secret))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(principal.UserID).To(Equal(userID))
+			},
+			Entry("user id", "42", intPtr(42)),
+			Entry("other subject", "user-42", nil),
+			Entry("leading zero", "042", nil),
+			Entry("zero", "0", nil),
+			Entry("negative", "-1", nil),
+		)
+
+		It("should accept a single string audience", func() {
+			claims["aud"] = "rentals"
+			_, err := verifier.Verify(signHS256(map[string]interface{}{"alg": "HS256"}, claims, secret))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		DescribeTable("should reject tokens with invalid claims",
+			func(name string, value interface{}) {
+				if value == nil {
+					delete(claims, name)
+				} else {
+					claims[name] = value
+				}
+
+				_, err := verifier.Verify(signHS256(map[string]interface{}{"alg": "HS256"}, claims, secret))
+				Expect(err).To(MatchError(auth.ErrInvalidToken))
+			},
+			Entry("missing sub", "sub", nil),
+			Entry("missing exp", "exp", nil),
+			Entry("expired", "exp", time.Now().Add(-time.Minute).Unix()),
+			Entry("not valid yet", "nbf", time.Now().Add(time.Minute).Unix()),
+			Entry("another issuer", "iss", "https://other.example"),
+			Entry("another audience", "aud", "billing"),
+		)
+
+		It("should reject a token signed with another secret", func() {
+			_, err := verifier.Verify(signHS256(map[string]interface{}{"alg": "HS256"}, claims, strings.Repeat("x", 32)))
+			Expect(err).To(MatchError(auth.ErrInvalidToken))
+		})
+
+		It("should reject a token whose kid names another key", func() {
+			_, err := verifier.Verify(signRS256(map[string]interface{}{"alg": "RS256", "kid": "rsa-2"}, claims, rsaKey))
+			Expect(err).To(MatchError(auth.ErrInvalidToken))
+		})
+
+		It("should reject unsigned tokens", func() {
+			_, err := verifier.Verify(encode(map[string]interface{}{"alg": "none"}) + "." + encode(claims) + ".")
+			Expect(err).To(MatchError(auth.ErrInvalidToken))
+		})
+
+		It("should not verify HS256 with the RSA public key as secret", func() {
+			publicKey := base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes())
+			_, err := verifier.Verify(signHS256(map[string]interface{}{"alg": "HS256", "kid": "rsa-1"}, claims, publicKey))
+			Expect(err).To(MatchError(auth.ErrInvalidToken))
+		})
+
+		It("should reject malformed tokens", func() {
+			_, err := verifier.Verify("not-a-token")
+			Expect(err).To(MatchError(auth.ErrInvalidToken))
+		})
+	})
+})
@@ -0,0 +1,50 @@
+// Package auth verifies the credentials of a request, JWT bearer tokens and api keys, independently of http
+package auth
+
+import "context"
+
+// Methods a principal is authenticated with
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// Principal is whom a request is authenticated as
+type Principal struct {
+	// Subject is the sub claim of a JWT, or api-key:<id> for an api key
+	Subject string
+	Method  string
+	// UserID is the user an api key belongs to, or the sub of a JWT when it is a user id.
+	// It is nil for api keys of services and JWTs of other subjects.
+	UserID *int
+	// Scopes are the space separated scope claim of a JWT, api keys have none
+	Scopes []string
+}
+
+// ActsFor reports whether the principal may act as a given user, it is that user or a service api key acting
+// for no user. JWTs of other subjects act for nobody.
+func (p Principal) ActsFor(userID int) bool {
+	if p.UserID != nil {
+		return *p.UserID == userID
+	}
+
+	return p.IsService()
+}
+
+// IsService reports whether the principal is an api key of a service acting for no user
+func (p Principal) IsService() bool {
+	return p.Method == MethodAPIKey && p.UserID == nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of an authenticated request, ok is false for anonymous ones
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
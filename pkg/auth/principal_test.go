@@ -0,0 +1,26 @@
+package auth_test
+
+import (
+	"github.com/nvasilev98/rentals/pkg/auth"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Principal", func() {
+	DescribeTable("should act only for its own user, or for any as a service",
+		func(principal auth.Principal, actsFor bool) {
+			Expect(principal.ActsFor(3)).To(Equal(actsFor))
+		},
+		Entry("jwt of the user", auth.Principal{Method: auth.MethodJWT, UserID: intPtr(3)}, true),
+		Entry("jwt of another user", auth.Principal{Method: auth.MethodJWT, UserID: intPtr(4)}, false),
+		Entry("jwt of another subject", auth.Principal{Method: auth.MethodJWT, Subject: "billing"}, false),
+		Entry("api key of the user", auth.Principal{Method: auth.MethodAPIKey, UserID: intPtr(3)}, true),
+		Entry("api key of another user", auth.Principal{Method: auth.MethodAPIKey, UserID: intPtr(4)}, false),
+		Entry("api key of a service", auth.Principal{Method: auth.MethodAPIKey}, true),
+	)
+})
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,115 @@
+package cache
+
+//go:generate mockgen --source=backend.go --destination mocks/backend.go --package mocks
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Backend stores encoded cache entries. LRU keeps them in process, a shared store such as Redis lets
+// several servers reuse each other's entries and invalidations.
+type Backend interface {
+	// Get returns the value of a live key, ok is false for missing and expired keys
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores the value for ttl, replacing the previous value of the key
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Incr increments the counter of the key and returns its new value, like the Redis INCR command.
+	// Counters never expire and Get returns them in decimal.
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// LRU is an in-process Backend holding at most capacity entries, the least recently used entry is evicted first
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	counters map[string]int64
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRU is a constructor function
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		counters: make(map[string]int64),
+	}
+}
+
+// Get returns the value of a live key and marks it as the most recently used
+func (l *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if counter, ok := l.counters[key]; ok {
+		return []byte(strconv.FormatInt(counter, 10)), true, nil
+	}
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !time.Now().Before(entry.expires) {
+		l.remove(element)
+		return nil, false, nil
+	}
+
+	l.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set stores the value and evicts the least recently used entry when the LRU is full
+func (l *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if element, ok := l.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+
+	return nil
+}
+
+// Incr increments a counter, counters do not count towards the capacity
+func (l *LRU) Incr(_ context.Context, key string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.counters[key]++
+	return l.counters[key], nil
+}
+
+// Len returns the number of entries, including expired ones that were not evicted yet
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+func (l *LRU) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.entries, element.Value.(*lruEntry).key)
+}
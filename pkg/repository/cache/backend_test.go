@@ -0,0 +1,76 @@
+package cache_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/cache"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LRU", func() {
+	var (
+		lru *cache.LRU
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		lru = cache.NewLRU(2)
+		ctx = context.Background()
+	})
+
+	get := func(key string) ([]byte, bool) {
+		value, ok, err := lru.Get(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+		return value, ok
+	}
+
+	It("should return a stored value until it expires", func() {
+		Expect(lru.Set(ctx, "a", []byte("1"), time.Minute)).To(Succeed())
+		Expect(lru.Set(ctx, "b", []byte("2"), time.Millisecond)).To(Succeed())
+		time.Sleep(5 * time.Millisecond)
+
+		value, ok := get("a")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal([]byte("1")))
+		_, ok = get("b")
+		Expect(ok).To(BeFalse())
+		Expect(lru.Len()).To(Equal(1))
+	})
+
+	It("should evict the least recently used entry when it is full", func() {
+		Expect(lru.Set(ctx, "a", []byte("1"), time.Minute)).To(Succeed())
+		Expect(lru.Set(ctx, "b", []byte("2"), time.Minute)).To(Succeed())
+		get("a")
+		Expect(lru.Set(ctx, "c", []byte("3"), time.Minute)).To(Succeed())
+
+		_, ok := get("b")
+		Expect(ok).To(BeFalse())
+		_, ok = get("a")
+		Expect(ok).To(BeTrue())
+		_, ok = get("c")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should replace the value of a stored key", func() {
+		Expect(lru.Set(ctx, "a", []byte("1"), time.Minute)).To(Succeed())
+		Expect(lru.Set(ctx, "a", []byte("2"), time.Minute)).To(Succeed())
+
+		value, _ := get("a")
+		Expect(value).To(Equal([]byte("2")))
+		Expect(lru.Len()).To(Equal(1))
+	})
+
+	It("should keep counters outside of the capacity", func() {
+		Expect(lru.Incr(ctx, "counter")).To(Equal(int64(1)))
+		Expect(lru.Incr(ctx, "counter")).To(Equal(int64(2)))
+		Expect(lru.Set(ctx, "a", []byte("1"), time.Minute)).To(Succeed())
+		Expect(lru.Set(ctx, "b", []byte("2"), time.Minute)).To(Succeed())
+
+		value, ok := get("counter")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal([]byte("2")))
+		Expect(lru.Len()).To(Equal(2))
+	})
+})
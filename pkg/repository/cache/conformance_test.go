@@ -0,0 +1,18 @@
+package cache_test
+
+import (
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/cache"
+	"github.com/nvasilev98/rentals/pkg/repository/conformance"
+	"github.com/nvasilev98/rentals/pkg/repository/memory"
+)
+
+var _ = conformance.DescribeRentalRepository("cached memory", func(seed memory.Seed) (conformance.RentalRepository, error) {
+	repository, err := memory.NewRentalRepository(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return cache.NewRentalRepository(repository, cache.NewLRU(100), time.Minute), nil
+})
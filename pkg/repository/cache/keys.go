@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+)
+
+// rentalKey identifies a single rental converted into currency
+func rentalKey(generation int64, id int, currency string) string {
+	return fmt.Sprintf("rentals:%d:id:%d:%s", generation, id, strings.ToUpper(currency))
+}
+
+// rentalsKey identifies a page of rentals, filters selecting the same page share a key however their
+// query parameters were ordered or cased
+func rentalsKey(generation int64, filter rentals.Filter) (string, error) {
+	normalized := filter
+	normalized.IDs = uniqueInts(filter.IDs)
+	normalized.Types = uniqueStrings(filter.Types, false)
+	normalized.Makes = uniqueStrings(filter.Makes, true)
+	normalized.Models = uniqueStrings(filter.Models, true)
+	normalized.Countries = uniqueStrings(filter.Countries, true)
+	normalized.States = uniqueStrings(filter.States, true)
+	normalized.Cities = uniqueStrings(filter.Cities, true)
+	normalized.ZIPs = uniqueStrings(filter.ZIPs, false)
+	normalized.Amenities = uniqueStrings(filter.Amenities, true)
+	normalized.Facets = uniqueStrings(filter.Facets, false)
+	normalized.Query = strings.Join(strings.Fields(filter.Query), " ")
+	normalized.Currency = strings.ToUpper(filter.Currency)
+	if normalized.AmenitiesMatch == "" {
+		normalized.AmenitiesMatch = rentals.AmenitiesMatchAll
+	}
+
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode filter: %w", err)
+	}
+
+	return fmt.Sprintf("rentals:%d:filter:%s", generation, encoded), nil
+}
+
+// uniqueInts sorts and deduplicates values of a filter that are matched regardless of their order
+func uniqueInts(values []int) []int {
+	if len(values) == 0 {
+		return nil
+	}
+
+	seen := make(map[int]bool, len(values))
+	result := make([]int, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Ints(result)
+
+	return result
+}
+
+func uniqueStrings(values []string, lower bool) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if lower {
+			value = strings.ToLower(value)
+		}
+
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
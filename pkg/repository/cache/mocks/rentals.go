@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rentals.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	rentals "github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+)
+
+// MockRentals is a mock of Rentals interface.
+type MockRentals struct {
+	ctrl     *gomock.Controller
+	recorder *MockRentalsMockRecorder
+}
+
+// MockRentalsMockRecorder is the mock recorder for MockRentals.
+type MockRentalsMockRecorder struct {
+	mock *MockRentals
+}
+
+// NewMockRentals creates a new mock instance.
+func NewMockRentals(ctrl *gomock.Controller) *MockRentals {
+	mock := &MockRentals{ctrl: ctrl}
+	mock.recorder = &MockRentalsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRentals) EXPECT() *MockRentalsMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockRentals) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockRentalsMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockRentals)(nil).Close))
+}
+
+// CreateRental mocks base method.
+func (m *MockRentals) CreateRental(ctx context.Context, input rentals.Input) (rentals.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRental", ctx, input)
+	ret0, _ := ret[0].(rentals.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRental indicates an expected call of CreateRental.
+func (mr *MockRentalsMockRecorder) CreateRental(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRental", reflect.TypeOf((*MockRentals)(nil).CreateRental), ctx, input)
+}
+
+// DeleteRental mocks base method.
+func (m *MockRentals) DeleteRental(ctx context.Context, id int, precondition rentals.Precondition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRental", ctx, id, precondition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRental indicates an expected call of DeleteRental.
+func (mr *MockRentalsMockRecorder) DeleteRental(ctx, id, precondition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRental", reflect.TypeOf((*MockRentals)(nil).DeleteRental), ctx, id, precondition)
+}
+
+// PatchRental mocks base method.
+func (m *MockRentals) PatchRental(ctx context.Context, id int, patch rentals.Patch, precondition rentals.Precondition) (rentals.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchRental", ctx, id, patch, precondition)
+	ret0, _ := ret[0].(rentals.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchRental indicates an expected call of PatchRental.
+func (mr *MockRentalsMockRecorder) PatchRental(ctx, id, patch, precondition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchRental", reflect.TypeOf((*MockRentals)(nil).PatchRental), ctx, id, patch, precondition)
+}
+
+// RetrieveRentalByID mocks base method.
+func (m *MockRentals) RetrieveRentalByID(ctx context.Context, id int, currency string) (rentals.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveRentalByID", ctx, id, currency)
+	ret0, _ := ret[0].(rentals.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveRentalByID indicates an expected call of RetrieveRentalByID.
+func (mr *MockRentalsMockRecorder) RetrieveRentalByID(ctx, id, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveRentalByID", reflect.TypeOf((*MockRentals)(nil).RetrieveRentalByID), ctx, id, currency)
+}
+
+// RetrieveRentals mocks base method.
+func (m *MockRentals) RetrieveRentals(ctx context.Context, filter rentals.Filter) (rentals.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveRentals", ctx, filter)
+	ret0, _ := ret[0].(rentals.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveRentals indicates an expected call of RetrieveRentals.
+func (mr *MockRentalsMockRecorder) RetrieveRentals(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveRentals", reflect.TypeOf((*MockRentals)(nil).RetrieveRentals), ctx, filter)
+}
+
+// UpdateRental mocks base method.
+func (m *MockRentals) UpdateRental(ctx context.Context, id int, input rentals.Input, precondition rentals.Precondition) (rentals.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRental", ctx, id, input, precondition)
+	ret0, _ := ret[0].(rentals.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRental indicates an expected call of UpdateRental.
+func (mr *MockRentalsMockRecorder) UpdateRental(ctx, id, input, precondition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRental", reflect.TypeOf((*MockRentals)(nil).UpdateRental), ctx, id, input, precondition)
+}
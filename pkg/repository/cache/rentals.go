@@ -0,0 +1,207 @@
+// Package cache decorates repositories with a read-through cache
+package cache
+
+//go:generate mockgen --source=rentals.go --destination mocks/rentals.go --package mocks
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+	"github.com/sirupsen/logrus"
+)
+
+// generationKey counts the invalidations, it is part of every key so an invalidation orphans all previous entries
+const generationKey = "rentals:generation"
+
+// Rentals is the repository whose reads are cached, such as rentals.Repository or memory.RentalRepository
+type Rentals interface {
+	RetrieveRentalByID(ctx context.Context, id int, currency string) (rentals.Model, error)
+	RetrieveRentals(ctx context.Context, filter rentals.Filter) (rentals.Page, error)
+	CreateRental(ctx context.Context, input rentals.Input) (rentals.Model, error)
+	UpdateRental(ctx context.Context, id int, input rentals.Input, precondition rentals.Precondition) (rentals.Model, error)
+	PatchRental(ctx context.Context, id int, patch rentals.Patch, precondition rentals.Precondition) (rentals.Model, error)
+	DeleteRental(ctx context.Context, id int, precondition rentals.Precondition) error
+	Close() error
+}
+
+// RentalRepository caches the rentals and pages read from the decorated repository for ttl.
+// Its own writes invalidate every entry, writes made around it have to call Invalidate.
+type RentalRepository struct {
+	rentals Rentals
+	backend Backend
+	ttl     time.Duration
+}
+
+// NewRentalRepository is a constructor function
+func NewRentalRepository(rentals Rentals, backend Backend, ttl time.Duration) *RentalRepository {
+	return &RentalRepository{
+		rentals: rentals,
+		backend: backend,
+		ttl:     ttl,
+	}
+}
+
+// RetrieveRentalByID returns a cached rental, errors such as postgres.ErrNotFound are not cached
+func (r *RentalRepository) RetrieveRentalByID(ctx context.Context, id int, currency string) (rentals.Model, error) {
+	generation, ok := r.generation(ctx)
+	if !ok {
+		return r.rentals.RetrieveRentalByID(ctx, id, currency)
+	}
+
+	var rental rentals.Model
+	key := rentalKey(generation, id, currency)
+	if r.load(ctx, key, &rental) {
+		return rental, nil
+	}
+
+	rental, err := r.rentals.RetrieveRentalByID(ctx, id, currency)
+	if err != nil {
+		return rentals.Model{}, err
+	}
+
+	r.store(ctx, key, rental)
+	return rental, nil
+}
+
+// RetrieveRentals returns a cached page of rentals matching the filter
+func (r *RentalRepository) RetrieveRentals(ctx context.Context, filter rentals.Filter) (rentals.Page, error) {
+	generation, ok := r.generation(ctx)
+	if !ok {
+		return r.rentals.RetrieveRentals(ctx, filter)
+	}
+
+	key, err := rentalsKey(generation, filter)
+	if err != nil {
+		logrus.Warnf("failed to build rentals cache key: %v", err)
+		return r.rentals.RetrieveRentals(ctx, filter)
+	}
+
+	var page rentals.Page
+	if r.load(ctx, key, &page) {
+		return page, nil
+	}
+
+	page, err = r.rentals.RetrieveRentals(ctx, filter)
+	if err != nil {
+		return rentals.Page{}, err
+	}
+
+	r.store(ctx, key, page)
+	return page, nil
+}
+
+// CreateRental creates a rental and invalidates the cache
+func (r *RentalRepository) CreateRental(ctx context.Context, input rentals.Input) (rentals.Model, error) {
+	rental, err := r.rentals.CreateRental(ctx, input)
+	if err != nil {
+		return rentals.Model{}, err
+	}
+
+	r.Invalidate(ctx)
+	return rental, nil
+}
+
+// UpdateRental replaces a rental and invalidates the cache
+func (r *RentalRepository) UpdateRental(ctx context.Context, id int, input rentals.Input,
+	precondition rentals.Precondition) (rentals.Model, error) {
+	rental, err := r.rentals.UpdateRental(ctx, id, input, precondition)
+	if err != nil {
+		return rentals.Model{}, err
+	}
+
+	r.Invalidate(ctx)
+	return rental, nil
+}
+
+// PatchRental patches a rental and invalidates the cache
+func (r *RentalRepository) PatchRental(ctx context.Context, id int, patch rentals.Patch,
+	precondition rentals.Precondition) (rentals.Model, error) {
+	rental, err := r.rentals.PatchRental(ctx, id, patch, precondition)
+	if err != nil {
+		return rentals.Model{}, err
+	}
+
+	r.Invalidate(ctx)
+	return rental, nil
+}
+
+// DeleteRental deletes a rental and invalidates the cache
+func (r *RentalRepository) DeleteRental(ctx context.Context, id int, precondition rentals.Precondition) error {
+	if err := r.rentals.DeleteRental(ctx, id, precondition); err != nil {
+		return err
+	}
+
+	r.Invalidate(ctx)
+	return nil
+}
+
+// Invalidate drops every cached rental and page, e.g. after a user was renamed or a review changed a rating.
+// The write has already succeeded by then, so a failing backend is only logged.
+func (r *RentalRepository) Invalidate(ctx context.Context) {
+	if _, err := r.backend.Incr(ctx, generationKey); err != nil {
+		logrus.Errorf("failed to invalidate rentals cache, entries stay stale for up to %s: %v", r.ttl, err)
+	}
+}
+
+// Close closes the decorated repository
+func (r *RentalRepository) Close() error {
+	return r.rentals.Close()
+}
+
+// generation returns the number of invalidations so far, ok is false when the cache has to be bypassed
+// because the backend failed
+func (r *RentalRepository) generation(ctx context.Context) (int64, bool) {
+	value, ok, err := r.backend.Get(ctx, generationKey)
+	if err != nil {
+		logrus.Warnf("failed to read rentals cache generation: %v", err)
+		return 0, false
+	}
+
+	if !ok {
+		return 0, true
+	}
+
+	generation, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		logrus.Warnf("failed to parse rentals cache generation %q: %v", value, err)
+		return 0, false
+	}
+
+	return generation, true
+}
+
+// load decodes a cached entry into target, a failing backend counts as a miss
+func (r *RentalRepository) load(ctx context.Context, key string, target interface{}) bool {
+	value, ok, err := r.backend.Get(ctx, key)
+	if err != nil {
+		logrus.Warnf("failed to read rentals cache: %v", err)
+		return false
+	}
+
+	if !ok {
+		return false
+	}
+
+	if err := json.Unmarshal(value, target); err != nil {
+		logrus.Warnf("failed to decode cached rentals %s: %v", key, err)
+		return false
+	}
+
+	return true
+}
+
+// store caches a value, entries are encoded so a shared backend can hold them and callers cannot modify them
+func (r *RentalRepository) store(ctx context.Context, key string, value interface{}) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		logrus.Warnf("failed to encode rentals %s for the cache: %v", key, err)
+		return
+	}
+
+	if err := r.backend.Set(ctx, key, encoded, r.ttl); err != nil {
+		logrus.Warnf("failed to write rentals cache: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nvasilev98/rentals/pkg/repository/cache"
+	"github.com/nvasilev98/rentals/pkg/repository/cache/mocks"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RentalRepository", func() {
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	rental := rentals.Model{ID: 1, Name: "Westfalia", PricePerDay: 16900, Currency: "USD",
+		Amenities: []string{"kitchen"}, Created: created, Updated: created}
+
+	var (
+		gomockCtrl  *gomock.Controller
+		mockRentals *mocks.MockRentals
+		repository  *cache.RentalRepository
+		ctx         context.Context
+	)
+
+	BeforeEach(func() {
+		gomockCtrl, _ = gomock.WithContext(context.Background(), GinkgoT())
+		mockRentals = mocks.NewMockRentals(gomockCtrl)
+		repository = cache.NewRentalRepository(mockRentals, cache.NewLRU(10), time.Minute)
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		gomockCtrl.Finish()
+	})
+
+	Context("RetrieveRentalByID", func() {
+		It("should read the rental once per currency", func() {
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(rental, nil).Times(1)
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "EUR").Return(rental, nil).Times(1)
+
+			for i := 0; i < 2; i++ {
+				cached, err := repository.RetrieveRentalByID(ctx, 1, "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cached).To(Equal(rental))
+			}
+			_, err := repository.RetrieveRentalByID(ctx, 1, "EUR")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not cache errors", func() {
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 9, "").Return(rentals.Model{}, postgres.ErrNotFound).Times(2)
+
+			for i := 0; i < 2; i++ {
+				_, err := repository.RetrieveRentalByID(ctx, 9, "")
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			}
+		})
+	})
+
+	Context("RetrieveRentals", func() {
+		page := rentals.Page{Rentals: []rentals.Model{rental}, Total: 1, NextCursor: "next"}
+
+		It("should share an entry between filters that only differ in order and case", func() {
+			mockRentals.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).Return(page, nil).Times(1)
+
+			cached, err := repository.RetrieveRentals(ctx, rentals.Filter{Makes: []string{"Volkswagen", "Ford"},
+				IDs: []int{2, 1}, Query: "pop  top", Limit: 10})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cached).To(Equal(page))
+
+			cached, err = repository.RetrieveRentals(ctx, rentals.Filter{Makes: []string{"ford", "VOLKSWAGEN", "ford"},
+				IDs: []int{1, 2}, Query: "pop top", Limit: 10, AmenitiesMatch: rentals.AmenitiesMatchAll})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cached).To(Equal(page))
+		})
+
+		It("should keep pages of different filters apart", func() {
+			mockRentals.EXPECT().RetrieveRentals(gomock.Any(), rentals.Filter{Limit: 10}).Return(page, nil).Times(1)
+			mockRentals.EXPECT().RetrieveRentals(gomock.Any(), rentals.Filter{Limit: 10, Offset: 10}).
+				Return(rentals.Page{Total: 1}, nil).Times(1)
+			mockRentals.EXPECT().RetrieveRentals(gomock.Any(),
+				rentals.Filter{Limit: 10, Sort: []rentals.SortKey{{Field: "price"}, {Field: "year"}}}).Return(page, nil).Times(1)
+			mockRentals.EXPECT().RetrieveRentals(gomock.Any(),
+				rentals.Filter{Limit: 10, Sort: []rentals.SortKey{{Field: "year"}, {Field: "price"}}}).Return(page, nil).Times(1)
+
+			for _, filter := range []rentals.Filter{
+				{Limit: 10},
+				{Limit: 10, Offset: 10},
+				{Limit: 10, Sort: []rentals.SortKey{{Field: "price"}, {Field: "year"}}},
+				{Limit: 10, Sort: []rentals.SortKey{{Field: "year"}, {Field: "price"}}},
+				{Limit: 10},
+			} {
+				_, err := repository.RetrieveRentals(ctx, filter)
+				Expect(err).ToNot(HaveOccurred())
+			}
+		})
+
+		It("should read the repository again once the entry expired", func() {
+			repository = cache.NewRentalRepository(mockRentals, cache.NewLRU(10), time.Millisecond)
+			mockRentals.EXPECT().RetrieveRentals(gomock.Any(), gomock.Any()).Return(page, nil).Times(2)
+
+			_, err := repository.RetrieveRentals(ctx, rentals.Filter{})
+			Expect(err).ToNot(HaveOccurred())
+			time.Sleep(5 * time.Millisecond)
+			_, err = repository.RetrieveRentals(ctx, rentals.Filter{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("invalidation", func() {
+		BeforeEach(func() {
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(rental, nil).Times(2)
+			_, err := repository.RetrieveRentalByID(ctx, 1, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_, err := repository.RetrieveRentalByID(ctx, 1, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should drop the entries after a rental was created", func() {
+			mockRentals.EXPECT().CreateRental(gomock.Any(), rentals.Input{Name: "Van"}).Return(rentals.Model{ID: 2}, nil)
+			_, err := repository.CreateRental(ctx, rentals.Input{Name: "Van"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should drop the entries after a rental was replaced", func() {
+			mockRentals.EXPECT().UpdateRental(gomock.Any(), 1, gomock.Any(), rentals.Precondition{}).Return(rental, nil)
+			_, err := repository.UpdateRental(ctx, 1, rentals.Input{}, rentals.Precondition{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should drop the entries after a rental was patched", func() {
+			mockRentals.EXPECT().PatchRental(gomock.Any(), 1, gomock.Any(), rentals.Precondition{}).Return(rental, nil)
+			_, err := repository.PatchRental(ctx, 1, rentals.Patch{}, rentals.Precondition{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should drop the entries after a rental was deleted", func() {
+			mockRentals.EXPECT().DeleteRental(gomock.Any(), 2, rentals.Precondition{}).Return(nil)
+			Expect(repository.DeleteRental(ctx, 2, rentals.Precondition{})).To(Succeed())
+		})
+
+		It("should drop the entries when asked to, e.g. after a user was renamed", func() {
+			repository.Invalidate(ctx)
+		})
+	})
+
+	It("should keep the entries when a write fails", func() {
+		mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(rental, nil).Times(1)
+		mockRentals.EXPECT().DeleteRental(gomock.Any(), 1, rentals.Precondition{}).Return(postgres.ErrConflict)
+
+		_, err := repository.RetrieveRentalByID(ctx, 1, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repository.DeleteRental(ctx, 1, rentals.Precondition{})).To(MatchError(postgres.ErrConflict))
+		_, err = repository.RetrieveRentalByID(ctx, 1, "")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	When("the backend fails", func() {
+		It("should read through to the repository", func() {
+			mockBackend := mocks.NewMockBackend(gomockCtrl)
+			repository = cache.NewRentalRepository(mockRentals, mockBackend, time.Minute)
+			mockBackend.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, false, errors.New("connection refused")).Times(2)
+			mockRentals.EXPECT().RetrieveRentalByID(gomock.Any(), 1, "").Return(rental, nil).Times(2)
+
+			for i := 0; i < 2; i++ {
+				cached, err := repository.RetrieveRentalByID(ctx, 1, "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cached).To(Equal(rental))
+			}
+		})
+
+		It("should still report a successful write", func() {
+			mockBackend := mocks.NewMockBackend(gomockCtrl)
+			repository = cache.NewRentalRepository(mockRentals, mockBackend, time.Minute)
+			mockRentals.EXPECT().DeleteRental(gomock.Any(), 1, rentals.Precondition{}).Return(nil)
+			mockBackend.EXPECT().Incr(gomock.Any(), "rentals:generation").Return(int64(0), errors.New("connection refused"))
+
+			Expect(repository.DeleteRental(ctx, 1, rentals.Precondition{})).To(Succeed())
+		})
+	})
+})
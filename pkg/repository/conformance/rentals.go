@@ -0,0 +1,371 @@
+// Package conformance holds the specs every rentals backend has to pass, so the Postgres and in-memory
+// repositories cannot drift apart in what a filter, sort or page means
+package conformance
+
+import (
+	"context"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/memory"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// RentalRepository is the contract of a rentals backend, the rentals presenter depends on the same methods
+type RentalRepository interface {
+	RetrieveRentalByID(ctx context.Context, id int, currency string) (rentals.Model, error)
+	RetrieveRentals(ctx context.Context, filter rentals.Filter) (rentals.Page, error)
+	CreateRental(ctx context.Context, input rentals.Input) (rentals.Model, error)
+	UpdateRental(ctx context.Context, id int, input rentals.Input, precondition rentals.Precondition) (rentals.Model, error)
+	PatchRental(ctx context.Context, id int, patch rentals.Patch, precondition rentals.Precondition) (rentals.Model, error)
+	DeleteRental(ctx context.Context, id int, precondition rentals.Precondition) error
+	Close() error
+}
+
+// Factory returns a repository holding exactly the users, exchange rates and rentals of the seed.
+// It is called before every spec, a factory that cannot run in the current environment should call Skip.
+type Factory func(seed memory.Seed) (RentalRepository, error)
+
+var created = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// Fixture is the data every spec starts from, prices and sleeps repeat so sorts have to break ties by id
+var Fixture = memory.Seed{
+	ExchangeRates: []memory.SeedExchangeRate{
+		{Currency: "USD", MinorUnits: 2, RatePerUSD: 1},
+		{Currency: "EUR", MinorUnits: 2, RatePerUSD: 0.92},
+	},
+	Users: []memory.SeedUser{
+		{ID: 1, FirstName: "John", LastName: "Smith", Updated: created},
+		{ID: 2, FirstName: "Jane", LastName: "Doe", Updated: created},
+	},
+	Rentals: []memory.SeedRental{
+		{ID: 1, Name: "Westfalia Pop-top", Type: "camper-van", VehicleMake: "Volkswagen", VehicleModel: "Westfalia",
+			VehicleYear: 1978, VehicleLength: 15, Sleeps: 4, PricePerDay: 16900, HomeCity: "Costa Mesa", HomeState: "CA",
+			HomeZIP: "92627", HomeCountry: "US", LAT: 33.64, LNG: -117.93, UserID: 1, Currency: "USD",
+			RatingAvg: 4.5, RatingCount: 2, Amenities: []string{"kitchen", "pets"}, Created: created, Updated: created},
+		{ID: 2, Name: "Vanagon Camper", Type: "camper-van", VehicleMake: "Volkswagen", VehicleModel: "Vanagon",
+			VehicleYear: 1989, VehicleLength: 15, Sleeps: 2, PricePerDay: 15000, HomeCity: "Portland", HomeState: "OR",
+			HomeZIP: "97202", HomeCountry: "US", LAT: 45.51, LNG: -122.68, UserID: 2, Currency: "USD",
+			RatingAvg: 3, RatingCount: 1, Amenities: []string{"kitchen"}, Created: created, Updated: created},
+		{ID: 3, Name: "Airstream Classic", Type: "trailer", VehicleMake: "Airstream", VehicleModel: "Classic",
+			VehicleYear: 2015, VehicleLength: 28, Sleeps: 6, PricePerDay: 12000, HomeCity: "San Diego", HomeState: "CA",
+			HomeZIP: "92037", HomeCountry: "US", LAT: 32.84, LNG: -117.27, UserID: 1, Currency: "USD",
+			Amenities: []string{}, Created: created, Updated: created},
+		{ID: 4, Name: "Sprinter Adventure Van", Type: "camper-van", VehicleMake: "Mercedes-Benz", VehicleModel: "Sprinter",
+			VehicleYear: 2019, VehicleLength: 20, Sleeps: 2, PricePerDay: 15000, HomeCity: "Los Angeles", HomeState: "CA",
+			HomeZIP: "90023", HomeCountry: "US", LAT: 34.02, LNG: -118.21, UserID: 2, Currency: "USD",
+			Amenities: []string{"pets"}, Created: created, Updated: created},
+		{ID: 5, Name: "Daisy", Type: "camper-van", VehicleMake: "Volkswagen", VehicleModel: "Campervan",
+			VehicleYear: 1979, VehicleLength: 4, Sleeps: 4, PricePerDay: 8000, HomeCity: "Bangor", HomeZIP: "BT23 7XE",
+			HomeCountry: "IE", LAT: 54.63, LNG: -5.67, UserID: 1, Currency: "EUR",
+			Amenities: []string{}, Created: created, Updated: created},
+	},
+}
+
+// DescribeRentalRepository registers the conformance specs for the repositories made by factory
+func DescribeRentalRepository(name string, factory Factory) bool {
+	return Describe(name+" RentalRepository conformance", func() {
+		var (
+			repository RentalRepository
+			ctx        context.Context
+		)
+
+		BeforeEach(func() {
+			var err error
+			repository, err = factory(Fixture)
+			Expect(err).ToNot(HaveOccurred())
+			ctx = context.Background()
+		})
+
+		AfterEach(func() {
+			Expect(repository.Close()).To(Succeed())
+		})
+
+		retrieveIDs := func(filter rentals.Filter) []int {
+			page, err := repository.RetrieveRentals(ctx, filter)
+			Expect(err).ToNot(HaveOccurred())
+			return ids(page)
+		}
+
+		Context("retrieving a rental", func() {
+			It("should return the rental together with its owner", func() {
+				rental, err := repository.RetrieveRentalByID(ctx, 1, "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.Name).To(Equal("Westfalia Pop-top"))
+				Expect(rental.FirstName).To(Equal("John"))
+				Expect(rental.LastName).To(Equal("Smith"))
+				Expect(rental.UserUpdated.Equal(created)).To(BeTrue())
+				Expect(rental.PricePerDay).To(Equal(16900))
+				Expect(rental.Currency).To(Equal("USD"))
+				Expect(rental.RatingAvg).To(Equal(4.5))
+				Expect(rental.RatingCount).To(Equal(2))
+				Expect(rental.Amenities).To(ConsistOf("kitchen", "pets"))
+				Expect(rental.Created.Equal(created)).To(BeTrue())
+			})
+
+			It("should convert the prices into the requested currency", func() {
+				rental, err := repository.RetrieveRentalByID(ctx, 5, "USD")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.PricePerDay).To(Equal(8696))
+				Expect(rental.Currency).To(Equal("USD"))
+			})
+
+			It("should reject a currency without an exchange rate", func() {
+				_, err := repository.RetrieveRentalByID(ctx, 1, "CAD")
+				Expect(err).To(MatchError(rentals.ErrInvalidFilter))
+			})
+
+			It("should return a not found error for a missing rental", func() {
+				_, err := repository.RetrieveRentalByID(ctx, 99, "")
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		Context("filtering", func() {
+			intPtr := func(value int) *int { return &value }
+			floatPtr := func(value float64) *float64 { return &value }
+
+			DescribeTable("should keep only the matching rentals ordered by id",
+				func(filter rentals.Filter, expected []int) {
+					Expect(retrieveIDs(filter)).To(Equal(expected))
+				},
+				Entry("no filter", rentals.Filter{}, []int{1, 2, 3, 4, 5}),
+				Entry("ids", rentals.Filter{IDs: []int{4, 2, 99}}, []int{2, 4}),
+				Entry("types", rentals.Filter{Types: []string{"trailer"}}, []int{3}),
+				Entry("makes ignoring case", rentals.Filter{Makes: []string{"volkswagen"}}, []int{1, 2, 5}),
+				Entry("states", rentals.Filter{States: []string{"CA"}}, []int{1, 3, 4}),
+				Entry("countries", rentals.Filter{Countries: []string{"IE"}}, []int{5}),
+				Entry("owner", rentals.Filter{UserID: intPtr(2)}, []int{2, 4}),
+				Entry("price range in the currency of every rental", rentals.Filter{PriceMin: intPtr(8000), PriceMax: intPtr(15000)},
+					[]int{2, 3, 4, 5}),
+				Entry("price range in a requested currency", rentals.Filter{PriceMax: intPtr(9000), Currency: "USD"}, []int{5}),
+				Entry("sleeps", rentals.Filter{SleepsMin: intPtr(4)}, []int{1, 3, 5}),
+				Entry("year range", rentals.Filter{YearMin: intPtr(1979), YearMax: intPtr(2015)}, []int{2, 3, 5}),
+				Entry("length range", rentals.Filter{LengthMin: floatPtr(15), LengthMax: floatPtr(20)}, []int{1, 2, 4}),
+				Entry("rating", rentals.Filter{RatingMin: floatPtr(3)}, []int{1, 2}),
+				Entry("every amenity", rentals.Filter{Amenities: []string{"kitchen", "pets"}}, []int{1}),
+				Entry("any amenity", rentals.Filter{Amenities: []string{"kitchen", "pets"},
+					AmenitiesMatch: rentals.AmenitiesMatchAny}, []int{1, 2, 4}),
+				Entry("text", rentals.Filter{Query: "airstream"}, []int{3}),
+				Entry("combined", rentals.Filter{States: []string{"CA"}, Types: []string{"camper-van"}, SleepsMin: intPtr(4)},
+					[]int{1}),
+			)
+		})
+
+		Context("sorting", func() {
+			DescribeTable("should order by the sort keys and break ties by id",
+				func(sort []rentals.SortKey, expected []int) {
+					Expect(retrieveIDs(rentals.Filter{Sort: sort})).To(Equal(expected))
+				},
+				Entry("price ascending", []rentals.SortKey{{Field: "price"}}, []int{5, 3, 2, 4, 1}),
+				Entry("price descending", []rentals.SortKey{{Field: "price", Descending: true}}, []int{1, 2, 4, 3, 5}),
+				Entry("sleeps then year", []rentals.SortKey{{Field: "sleeps", Descending: true}, {Field: "year"}},
+					[]int{3, 1, 5, 2, 4}),
+				Entry("name", []rentals.SortKey{{Field: "name"}}, []int{3, 5, 4, 2, 1}),
+			)
+		})
+
+		Context("paginating", func() {
+			It("should count every match while returning a page of them", func() {
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{Limit: 2, Offset: 1})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ids(page)).To(Equal([]int{2, 3}))
+				Expect(page.Total).To(Equal(5))
+			})
+
+			It("should return an empty page past the last match", func() {
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{Offset: 10})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Rentals).To(BeEmpty())
+				Expect(page.Total).To(Equal(5))
+			})
+
+			DescribeTable("should visit every rental once when following the cursors",
+				func(sort string, expected []int) {
+					query := map[string][]string{"sort": {sort}, "limit": {"2"}}
+					var visited []int
+					for pages := 0; pages < len(Fixture.Rentals); pages++ {
+						filter, err := rentals.ParseFilter(query)
+						Expect(err).ToNot(HaveOccurred())
+
+						page, err := repository.RetrieveRentals(ctx, filter)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(page.Total).To(Equal(len(Fixture.Rentals)))
+						visited = append(visited, ids(page)...)
+						if page.NextCursor == "" {
+							break
+						}
+						query["cursor"] = []string{page.NextCursor}
+					}
+
+					Expect(visited).To(Equal(expected))
+				},
+				Entry("by price with ties", "price", []int{5, 3, 2, 4, 1}),
+				Entry("by price descending with ties", "-price", []int{1, 2, 4, 3, 5}),
+				Entry("by two keys", "-sleeps,year", []int{3, 1, 5, 2, 4}),
+				Entry("by creation time", "created", []int{1, 2, 3, 4, 5}),
+			)
+		})
+
+		Context("searching by location", func() {
+			costaMesa := &rentals.Coordinates{LAT: 33.64, LNG: -117.93}
+
+			It("should keep the rentals within the radius and sort them by distance", func() {
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					Near:   costaMesa,
+					Radius: rentals.Distance{Value: 150, Unit: rentals.Kilometers},
+					Sort:   []rentals.SortKey{{Field: "distance"}},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ids(page)).To(Equal([]int{1, 4, 3}))
+				Expect(*page.Rentals[0].Distance).To(BeNumerically("<", 0.1))
+				Expect(*page.Rentals[1].Distance).To(BeNumerically("~", 49.5, 1))
+				Expect(*page.Rentals[2].Distance).To(BeNumerically("~", 108, 2))
+			})
+
+			It("should report distances in the unit of the radius", func() {
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					Near:   costaMesa,
+					Radius: rentals.Distance{Value: 50, Unit: rentals.Miles},
+					IDs:    []int{4},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ids(page)).To(Equal([]int{4}))
+				Expect(*page.Rentals[0].Distance).To(BeNumerically("~", 30.8, 1))
+			})
+
+			It("should keep the rentals inside a bounding box", func() {
+				Expect(retrieveIDs(rentals.Filter{
+					BBox: &rentals.BoundingBox{MinLNG: -119, MinLAT: 32, MaxLNG: -117, MaxLAT: 34},
+				})).To(Equal([]int{1, 3}))
+			})
+
+			It("should keep the rentals inside a polygon", func() {
+				Expect(retrieveIDs(rentals.Filter{
+					Within: &rentals.Polygon{Rings: [][][2]float64{{{-125, 40}, {-110, 40}, {-110, 50}, {-125, 50}, {-125, 40}}}},
+				})).To(Equal([]int{2}))
+			})
+		})
+
+		Context("writing rentals", func() {
+			input := rentals.Input{Name: "Roadtrek", Type: "camper-van", VehicleMake: "Roadtrek", VehicleYear: 2012,
+				Sleeps: 2, PricePerDay: 11000, HomeState: "WA", HomeCountry: "US", LAT: 47.6, LNG: -122.33, UserID: 2}
+
+			It("should create a rental that can be retrieved", func() {
+				rental, err := repository.CreateRental(ctx, input)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.ID).To(Equal(6))
+				Expect(rental.Currency).To(Equal("USD"))
+				Expect(rental.FirstName).To(Equal("Jane"))
+
+				retrieved, err := repository.RetrieveRentalByID(ctx, rental.ID, "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(retrieved.Name).To(Equal("Roadtrek"))
+				Expect(retrieveIDs(rentals.Filter{States: []string{"WA"}})).To(Equal([]int{6}))
+			})
+
+			It("should reject a rental of an unknown user", func() {
+				invalid := input
+				invalid.UserID = 99
+				_, err := repository.CreateRental(ctx, invalid)
+				Expect(err).To(MatchError(postgres.ErrInvalidInput))
+			})
+
+			It("should replace a rental", func() {
+				rental, err := repository.UpdateRental(ctx, 3, input, rentals.Precondition{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.ID).To(Equal(3))
+				Expect(rental.Name).To(Equal("Roadtrek"))
+				Expect(rental.Created.Equal(created)).To(BeTrue())
+			})
+
+			It("should patch only the given fields", func() {
+				name := "Renamed"
+				rental, err := repository.PatchRental(ctx, 1, rentals.Patch{Name: &name}, rentals.Precondition{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.Name).To(Equal(name))
+				Expect(rental.PricePerDay).To(Equal(16900))
+				Expect(rental.VehicleMake).To(Equal("Volkswagen"))
+			})
+
+			It("should delete a rental", func() {
+				Expect(repository.DeleteRental(ctx, 2, rentals.Precondition{})).To(Succeed())
+				_, err := repository.RetrieveRentalByID(ctx, 2, "")
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+				Expect(retrieveIDs(rentals.Filter{})).To(Equal([]int{1, 3, 4, 5}))
+			})
+
+			It("should return not found errors for a missing rental", func() {
+				name := "Renamed"
+				_, err := repository.UpdateRental(ctx, 99, input, rentals.Precondition{})
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+				_, err = repository.PatchRental(ctx, 99, rentals.Patch{Name: &name}, rentals.Precondition{})
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+				Expect(repository.DeleteRental(ctx, 99, rentals.Precondition{})).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		Context("writing rentals with a precondition", func() {
+			name := "Renamed"
+			stale := rentals.Precondition{Updated: created.Add(-time.Hour)}
+
+			It("should write a rental that was not updated since it was read", func() {
+				rental, err := repository.PatchRental(ctx, 1, rentals.Patch{Name: &name}, rentals.Precondition{Updated: created})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.Updated.After(created)).To(BeTrue())
+
+				_, err = repository.PatchRental(ctx, 1, rentals.Patch{Name: &name}, rentals.Precondition{Updated: created})
+				Expect(err).To(MatchError(postgres.ErrPreconditionFailed))
+				Expect(repository.DeleteRental(ctx, 1, rentals.Precondition{Updated: rental.Updated})).To(Succeed())
+			})
+
+			It("should leave a rental that was updated since it was read unchanged", func() {
+				_, err := repository.UpdateRental(ctx, 1, rentals.Input{Name: "Roadtrek", Type: "camper-van", UserID: 1}, stale)
+				Expect(err).To(MatchError(postgres.ErrPreconditionFailed))
+				Expect(repository.DeleteRental(ctx, 1, stale)).To(MatchError(postgres.ErrPreconditionFailed))
+
+				rental, err := repository.RetrieveRentalByID(ctx, 1, "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.Name).To(Equal("Westfalia Pop-top"))
+			})
+
+			It("should return not found errors for a missing rental", func() {
+				_, err := repository.PatchRental(ctx, 99, rentals.Patch{Name: &name}, stale)
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+				Expect(repository.DeleteRental(ctx, 99, stale)).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		Context("with a done context", func() {
+			It("should fail with context.Canceled once the context is canceled", func() {
+				canceledCtx, cancel := context.WithCancel(ctx)
+				cancel()
+
+				_, err := repository.RetrieveRentalByID(canceledCtx, 1, "")
+				Expect(err).To(MatchError(context.Canceled))
+				_, err = repository.RetrieveRentals(canceledCtx, rentals.Filter{})
+				Expect(err).To(MatchError(context.Canceled))
+				Expect(repository.DeleteRental(canceledCtx, 1, rentals.Precondition{})).To(MatchError(context.Canceled))
+			})
+
+			It("should fail with a timeout error once the deadline passed", func() {
+				deadlineCtx, cancel := context.WithDeadline(ctx, time.Now())
+				defer cancel()
+
+				_, err := repository.RetrieveRentals(deadlineCtx, rentals.Filter{})
+				Expect(err).To(MatchError(postgres.ErrTimeout))
+			})
+		})
+	})
+}
+
+func ids(page rentals.Page) []int {
+	result := make([]int, 0, len(page.Rentals))
+	for _, rental := range page.Rentals {
+		result = append(result, rental.ID)
+	}
+
+	return result
+}
@@ -0,0 +1,10 @@
+package memory_test
+
+import (
+	"github.com/nvasilev98/rentals/pkg/repository/conformance"
+	"github.com/nvasilev98/rentals/pkg/repository/memory"
+)
+
+var _ = conformance.DescribeRentalRepository("memory", func(seed memory.Seed) (conformance.RentalRepository, error) {
+	return memory.NewRentalRepository(seed)
+})
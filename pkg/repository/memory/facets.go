@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+)
+
+// facet counts the rentals matching the filter without the own filter of the facet, like the facet queries
+// of rentals.Repository. Histograms list every bucket, values of term facets are ordered by count.
+func (r *RentalRepository) facet(name string, filter rentals.Filter) []rentals.FacetBucket {
+	var (
+		value  func(rental rentals.Model) string
+		number func(rental rentals.Model) int
+		bounds []int
+	)
+	switch name {
+	case rentals.FacetType:
+		filter.Types = nil
+		value = func(rental rentals.Model) string { return rental.Type }
+	case rentals.FacetMake:
+		filter.Makes = nil
+		value = func(rental rentals.Model) string { return rental.VehicleMake }
+	case rentals.FacetState:
+		filter.States = nil
+		value = func(rental rentals.Model) string { return rental.HomeState }
+	case rentals.FacetPrice:
+		filter.PriceMin, filter.PriceMax = nil, nil
+		number, bounds = func(rental rentals.Model) int { return rental.PricePerDay }, rentals.PriceBucketBounds
+	case rentals.FacetSleeps:
+		filter.SleepsMin = nil
+		number, bounds = func(rental rentals.Model) int { return rental.Sleeps }, rentals.SleepsBucketBounds
+	default:
+		return make([]rentals.FacetBucket, 0)
+	}
+
+	matching := r.match(filter)
+	if bounds != nil {
+		counts := make(map[int]int)
+		for _, rental := range matching {
+			// like width_bucket, the bucket of a value is the number of bounds not above it
+			counts[sort.SearchInts(bounds, number(rental)+1)]++
+		}
+
+		return rentals.Histogram(bounds, counts)
+	}
+
+	counts := make(map[string]int)
+	for _, rental := range matching {
+		counts[value(rental)]++
+	}
+
+	buckets := make([]rentals.FacetBucket, 0, len(counts))
+	for bucketValue, count := range counts {
+		buckets = append(buckets, rentals.FacetBucket{Value: bucketValue, Count: count})
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+
+		return buckets[i].Value < buckets[j].Value
+	})
+
+	return buckets
+}
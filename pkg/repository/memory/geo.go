@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"math"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+)
+
+// earthRadius is the mean radius of the earth in meters, distances are great-circle distances on a sphere
+// and differ from the spheroidal ones of PostGIS by a fraction of a percent
+const earthRadius = 6371008.8
+
+// distanceMeters returns the haversine distance between a point and the location of a rental
+func distanceMeters(point rentals.Coordinates, rental rentals.Model) float64 {
+	lat1, lng1 := radians(point.LAT), radians(point.LNG)
+	lat2, lng2 := radians(float64(rental.LAT)), radians(float64(rental.LNG))
+
+	h := math.Pow(math.Sin((lat2-lat1)/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin((lng2-lng1)/2), 2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// boundingBoxCovers reports whether the location of a rental lies within a viewport, edges included
+func boundingBoxCovers(box rentals.BoundingBox, rental rentals.Model) bool {
+	lat, lng := float64(rental.LAT), float64(rental.LNG)
+	return lat >= box.MinLAT && lat <= box.MaxLAT && lng >= box.MinLNG && lng <= box.MaxLNG
+}
+
+// polygonCovers reports whether the location of a rental lies within the exterior ring of a polygon and outside its holes
+func polygonCovers(polygon rentals.Polygon, rental rentals.Model) bool {
+	if len(polygon.Rings) == 0 {
+		return false
+	}
+
+	point := [2]float64{float64(rental.LNG), float64(rental.LAT)}
+	if !ringContains(polygon.Rings[0], point) {
+		return false
+	}
+
+	for _, hole := range polygon.Rings[1:] {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ringContains casts a ray from the point and counts the edges of the ring it crosses
+func ringContains(ring [][2]float64, point [2]float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > point[1]) != (b[1] > point[1]) &&
+			point[0] < (b[0]-a[0])*(point[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
@@ -0,0 +1,249 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+)
+
+// match returns the rentals matching every condition of the filter, viewed through the filter.
+// The cursor is not a condition here, it only moves the start of the page.
+func (r *RentalRepository) match(filter rentals.Filter) []rentals.Model {
+	var query *textQuery
+	if filter.Query != "" {
+		query = newTextQuery(filter.Query)
+	}
+
+	matching := make([]rentals.Model, 0)
+	for _, stored := range r.rentals {
+		if query != nil && !query.matches(stored) {
+			continue
+		}
+
+		rental := r.view(stored, filter)
+		if matches(rental, filter) {
+			matching = append(matching, rental)
+		}
+	}
+
+	return matching
+}
+
+// matches tests a viewed rental, prices are already in the currency of the filter
+func matches(rental rentals.Model, filter rentals.Filter) bool {
+	switch {
+	case filter.PriceMin != nil && rental.PricePerDay < *filter.PriceMin,
+		filter.PriceMax != nil && rental.PricePerDay > *filter.PriceMax,
+		len(filter.IDs) > 0 && !containsInt(filter.IDs, rental.ID),
+		len(filter.Types) > 0 && !containsString(filter.Types, rental.Type),
+		filter.SleepsMin != nil && rental.Sleeps < *filter.SleepsMin,
+		filter.YearMin != nil && rental.VehicleYear < *filter.YearMin,
+		filter.YearMax != nil && rental.VehicleYear > *filter.YearMax,
+		filter.LengthMin != nil && exactFloat32(rental.VehicleLength) < *filter.LengthMin,
+		filter.LengthMax != nil && exactFloat32(rental.VehicleLength) > *filter.LengthMax,
+		filter.RatingMin != nil && rental.RatingAvg < *filter.RatingMin,
+		len(filter.Amenities) > 0 && !matchesAmenities(rental.Amenities, filter),
+		len(filter.Makes) > 0 && !containsFold(filter.Makes, rental.VehicleMake),
+		len(filter.Models) > 0 && !containsFold(filter.Models, rental.VehicleModel),
+		len(filter.Countries) > 0 && !containsFold(filter.Countries, rental.HomeCountry),
+		len(filter.States) > 0 && !containsFold(filter.States, rental.HomeState),
+		len(filter.Cities) > 0 && !containsFold(filter.Cities, rental.HomeCity),
+		len(filter.ZIPs) > 0 && !containsString(filter.ZIPs, rental.HomeZIP),
+		filter.UserID != nil && rental.UserID != *filter.UserID:
+		return false
+	}
+
+	if filter.Near != nil && distanceMeters(*filter.Near, rental) > filter.Radius.Value*filter.Radius.Unit.Meters() {
+		return false
+	}
+
+	if filter.BBox != nil && !boundingBoxCovers(*filter.BBox, rental) {
+		return false
+	}
+
+	if filter.Within != nil && !polygonCovers(*filter.Within, rental) {
+		return false
+	}
+
+	return true
+}
+
+func matchesAmenities(amenities []string, filter rentals.Filter) bool {
+	found := 0
+	for _, slug := range filter.Amenities {
+		if containsString(amenities, slug) {
+			found++
+		}
+	}
+
+	if filter.AmenitiesMatch == rentals.AmenitiesMatchAny {
+		return found > 0
+	}
+
+	return found == len(filter.Amenities)
+}
+
+// sortRentals orders rentals by the sort keys of the filter with the id as a tie-breaker, like ORDER BY ..., r.id
+func sortRentals(matching []rentals.Model, filter rentals.Filter) {
+	sort.SliceStable(matching, func(i, j int) bool {
+		return compareRentals(matching[i], matching[j], filter) < 0
+	})
+}
+
+func compareRentals(a, b rentals.Model, filter rentals.Filter) int {
+	left := rentals.NewCursor(filter, a)
+	right := rentals.NewCursor(filter, b)
+	for i, key := range filter.Sort {
+		if result := directed(compareValues(left.Values[i], right.Values[i]), key.Descending); result != 0 {
+			return result
+		}
+	}
+
+	return compareInts(a.ID, b.ID)
+}
+
+// afterCursor drops the rentals placed up to the cursor in the order of the sort keys, like the keyset condition
+func afterCursor(sorted []rentals.Model, filter rentals.Filter) []rentals.Model {
+	for i, rental := range sorted {
+		if compareToCursor(rental, filter) > 0 {
+			return sorted[i:]
+		}
+	}
+
+	return nil
+}
+
+func compareToCursor(rental rentals.Model, filter rentals.Filter) int {
+	values := rentals.NewCursor(filter, rental).Values
+	for i, key := range filter.Sort {
+		if i >= len(filter.Cursor.Values) {
+			break
+		}
+
+		if result := directed(compareValues(values[i], filter.Cursor.Values[i]), key.Descending); result != 0 {
+			return result
+		}
+	}
+
+	return compareInts(rental.ID, filter.Cursor.ID)
+}
+
+func directed(result int, descending bool) int {
+	if descending {
+		return -result
+	}
+
+	return result
+}
+
+// compareValues compares sort values of rentals with each other or with the values of a decoded cursor,
+// where numbers are float64 and times are RFC 3339 strings
+func compareValues(a, b interface{}) int {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return compareFloats(x, y)
+		}
+	}
+
+	if x, ok := toTime(a); ok {
+		if y, ok := toTime(b); ok {
+			return compareTimes(x, y)
+		}
+	}
+
+	x, _ := a.(string)
+	y, _ := b.(string)
+	return strings.Compare(x, y)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case int:
+		return float64(number), true
+	case float32:
+		return exactFloat32(number), true
+	case float64:
+		return number, true
+	default:
+		return 0, false
+	}
+}
+
+func toTime(value interface{}) (time.Time, bool) {
+	switch moment := value.(type) {
+	case time.Time:
+		return moment, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, moment)
+		return parsed, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// exactFloat32 widens a float32 to the float64 with the same shortest decimal representation,
+// so 123.3 stays 123.3 like in the numeric columns of the rentals table
+func exactFloat32(value float32) float64 {
+	widened, _ := strconv.ParseFloat(strconv.FormatFloat(float64(value), 'g', -1, 32), 64)
+	return widened
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareInts(a, b int) int {
+	return compareFloats(float64(a), float64(b))
+}
+
+func containsInt(values []int, value int) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsFold matches free text such as make or city regardless of its case
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+
+	return false
+}
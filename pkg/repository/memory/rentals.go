@@ -0,0 +1,436 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/currencies"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+)
+
+// RentalRepository keeps rentals in memory with the filter, sort, pagination and geographic semantics of
+// the Postgres rentals.Repository. It is meant for local development and tests, nothing is persisted.
+type RentalRepository struct {
+	mu      sync.RWMutex
+	rentals map[int]rentals.Model
+	users   map[int]SeedUser
+	rates   map[string]currencies.ExchangeRate
+	lastID  int
+	now     func() time.Time
+}
+
+// NewRentalRepository is a constructor function, it fails when the seed references unknown users or currencies
+func NewRentalRepository(seed Seed) (*RentalRepository, error) {
+	r := &RentalRepository{
+		rentals: make(map[int]rentals.Model, len(seed.Rentals)),
+		users:   make(map[int]SeedUser, len(seed.Users)),
+		rates:   make(map[string]currencies.ExchangeRate, len(seed.ExchangeRates)),
+		now:     func() time.Time { return time.Now().UTC().Truncate(time.Microsecond) },
+	}
+
+	for _, rate := range seed.ExchangeRates {
+		if !currencies.ValidCode(rate.Currency) || rate.RatePerUSD <= 0 ||
+			rate.MinorUnits < 0 || rate.MinorUnits > currencies.MaxMinorUnits {
+			return nil, fmt.Errorf("invalid exchange rate of %q in seed", rate.Currency)
+		}
+
+		r.rates[rate.Currency] = currencies.ExchangeRate{Currency: rate.Currency, MinorUnits: rate.MinorUnits,
+			RatePerUSD: rate.RatePerUSD}
+	}
+
+	for _, user := range seed.Users {
+		r.users[user.ID] = user
+	}
+
+	for _, seeded := range seed.Rentals {
+		if seeded.ID <= 0 {
+			return nil, fmt.Errorf("rental id %d in seed must be a positive integer", seeded.ID)
+		}
+
+		if _, ok := r.rentals[seeded.ID]; ok {
+			return nil, fmt.Errorf("rental %d is repeated in seed", seeded.ID)
+		}
+
+		rental := fromSeed(seeded)
+		if err := r.checkReferences(rental.UserID, rental.Currency); err != nil {
+			return nil, fmt.Errorf("rental %d in seed: %w", seeded.ID, err)
+		}
+
+		r.rentals[rental.ID] = rental
+		if rental.ID > r.lastID {
+			r.lastID = rental.ID
+		}
+	}
+
+	return r, nil
+}
+
+func fromSeed(seeded SeedRental) rentals.Model {
+	if seeded.Currency == "" {
+		seeded.Currency = currencies.DefaultCurrency
+	}
+
+	amenities := append([]string{}, seeded.Amenities...)
+	sort.Strings(amenities)
+
+	return rentals.Model{
+		ID:                      seeded.ID,
+		Name:                    seeded.Name,
+		Description:             seeded.Description,
+		Type:                    seeded.Type,
+		VehicleMake:             seeded.VehicleMake,
+		VehicleModel:            seeded.VehicleModel,
+		VehicleYear:             seeded.VehicleYear,
+		VehicleLength:           seeded.VehicleLength,
+		Sleeps:                  seeded.Sleeps,
+		PrimaryImageURL:         seeded.PrimaryImageURL,
+		PricePerDay:             seeded.PricePerDay,
+		WeeklyDiscountPercent:   seeded.WeeklyDiscountPercent,
+		MonthlyDiscountPercent:  seeded.MonthlyDiscountPercent,
+		WeekendSurchargePercent: seeded.WeekendSurchargePercent,
+		CleaningFee:             seeded.CleaningFee,
+		ServiceFeePercent:       seeded.ServiceFeePercent,
+		Currency:                seeded.Currency,
+		RatingAvg:               seeded.RatingAvg,
+		RatingCount:             seeded.RatingCount,
+		HomeCity:                seeded.HomeCity,
+		HomeState:               seeded.HomeState,
+		HomeZIP:                 seeded.HomeZIP,
+		HomeCountry:             seeded.HomeCountry,
+		LAT:                     seeded.LAT,
+		LNG:                     seeded.LNG,
+		UserID:                  seeded.UserID,
+		Created:                 seeded.Created.UTC(),
+		Updated:                 seeded.Updated.UTC(),
+		Amenities:               amenities,
+	}
+}
+
+// RetrieveRentalByID retrieves rental by a given id, its prices are converted to a given currency unless it is empty
+func (r *RentalRepository) RetrieveRentalByID(ctx context.Context, id int, currency string) (rentals.Model, error) {
+	if err := checkContext(ctx); err != nil {
+		return rentals.Model{}, err
+	}
+
+	if id <= 0 {
+		return rentals.Model{}, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if currency != "" {
+		if err := r.checkCurrency(currency); err != nil {
+			return rentals.Model{}, err
+		}
+	}
+
+	rental, ok := r.rentals[id]
+	if !ok {
+		return rentals.Model{}, fmt.Errorf("%w: rental %d does not exist", postgres.ErrNotFound, id)
+	}
+
+	return r.view(rental, rentals.Filter{Currency: currency}), nil
+}
+
+// RetrieveRentals retrieves a page of rentals matching a given filter together with the total count
+// and the requested facets. Every rental is available, bookings are not kept in memory.
+func (r *RentalRepository) RetrieveRentals(ctx context.Context, filter rentals.Filter) (rentals.Page, error) {
+	if err := checkContext(ctx); err != nil {
+		return rentals.Page{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if filter.Currency != "" {
+		if err := r.checkCurrency(filter.Currency); err != nil {
+			return rentals.Page{}, err
+		}
+	}
+
+	matching := r.match(filter)
+	sortRentals(matching, filter)
+
+	page := rentals.Page{Total: len(matching), Rentals: make([]rentals.Model, 0)}
+	if filter.Cursor != nil {
+		matching = afterCursor(matching, filter)
+	}
+
+	if filter.Offset > 0 {
+		if filter.Offset >= len(matching) {
+			matching = nil
+		} else {
+			matching = matching[filter.Offset:]
+		}
+	}
+
+	if filter.Limit > 0 && len(matching) > filter.Limit {
+		matching = matching[:filter.Limit]
+		page.NextCursor = rentals.NewCursor(filter, matching[filter.Limit-1]).Encode()
+	}
+
+	page.Rentals = append(page.Rentals, matching...)
+
+	if len(filter.Facets) > 0 {
+		page.Facets = make(map[string][]rentals.FacetBucket, len(filter.Facets))
+		for _, name := range filter.Facets {
+			page.Facets[name] = r.facet(name, filter)
+		}
+	}
+
+	return page, nil
+}
+
+// CreateRental validates and stores a new rental, created and updated are set to the current time
+func (r *RentalRepository) CreateRental(ctx context.Context, input rentals.Input) (rentals.Model, error) {
+	if err := checkContext(ctx); err != nil {
+		return rentals.Model{}, err
+	}
+
+	if err := input.Validate(); err != nil {
+		return rentals.Model{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rental := rentals.Model{Amenities: make([]string, 0)}
+	applyPatch(&rental, input.ToPatch())
+	if err := r.checkReferences(rental.UserID, rental.Currency); err != nil {
+		return rentals.Model{}, err
+	}
+
+	r.lastID++
+	rental.ID = r.lastID
+	rental.Created = r.now()
+	rental.Updated = rental.Created
+	r.rentals[rental.ID] = rental
+
+	return r.view(rental, rentals.Filter{}), nil
+}
+
+// UpdateRental validates and replaces every writable field of a rental by a given id
+func (r *RentalRepository) UpdateRental(ctx context.Context, id int, input rentals.Input,
+	precondition rentals.Precondition) (rentals.Model, error) {
+	if err := input.Validate(); err != nil {
+		return rentals.Model{}, err
+	}
+
+	return r.updateRental(ctx, id, input.ToPatch(), precondition)
+}
+
+// PatchRental validates and changes only the fields set by the patch of a rental by a given id
+func (r *RentalRepository) PatchRental(ctx context.Context, id int, patch rentals.Patch,
+	precondition rentals.Precondition) (rentals.Model, error) {
+	if err := patch.Validate(); err != nil {
+		return rentals.Model{}, err
+	}
+
+	return r.updateRental(ctx, id, patch, precondition)
+}
+
+func (r *RentalRepository) updateRental(ctx context.Context, id int, patch rentals.Patch,
+	precondition rentals.Precondition) (rentals.Model, error) {
+	if err := checkContext(ctx); err != nil {
+		return rentals.Model{}, err
+	}
+
+	if id <= 0 {
+		return rentals.Model{}, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rental, ok := r.rentals[id]
+	if !ok {
+		return rentals.Model{}, fmt.Errorf("%w: rental %d does not exist", postgres.ErrNotFound, id)
+	}
+
+	if err := checkPrecondition(rental, precondition); err != nil {
+		return rentals.Model{}, err
+	}
+
+	applyPatch(&rental, patch)
+	if err := r.checkReferences(rental.UserID, rental.Currency); err != nil {
+		return rentals.Model{}, err
+	}
+
+	rental.Updated = r.now()
+	r.rentals[id] = rental
+
+	return r.view(rental, rentals.Filter{}), nil
+}
+
+// DeleteRental deletes a rental by a given id
+func (r *RentalRepository) DeleteRental(ctx context.Context, id int, precondition rentals.Precondition) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	if id <= 0 {
+		return fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rental, ok := r.rentals[id]
+	if !ok {
+		return fmt.Errorf("%w: rental %d does not exist", postgres.ErrNotFound, id)
+	}
+
+	if err := checkPrecondition(rental, precondition); err != nil {
+		return err
+	}
+
+	delete(r.rentals, id)
+	return nil
+}
+
+// Close releases nothing, it lets the repository replace rentals.Repository
+func (r *RentalRepository) Close() error {
+	return nil
+}
+
+// checkContext fails like a query would when the context is already done
+func checkContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return postgres.Classify(fmt.Errorf("failed to query rentals: %w", err))
+	}
+
+	return nil
+}
+
+// checkPrecondition fails like a conditional write of the rentals table that matched no row
+func checkPrecondition(rental rentals.Model, precondition rentals.Precondition) error {
+	if !precondition.IsZero() && !rental.Updated.Equal(precondition.Updated) {
+		return fmt.Errorf("%w: rental %d was updated since it was read", postgres.ErrPreconditionFailed, rental.ID)
+	}
+
+	return nil
+}
+
+// checkReferences fails with postgres.ErrInvalidInput where the rentals table would violate a foreign key
+func (r *RentalRepository) checkReferences(userID int, currency string) error {
+	if _, ok := r.users[userID]; !ok {
+		return fmt.Errorf("%w: user %d does not exist", postgres.ErrInvalidInput, userID)
+	}
+
+	if _, ok := r.rates[currency]; !ok {
+		return fmt.Errorf("%w: currency %s has no exchange rate", postgres.ErrInvalidInput, currency)
+	}
+
+	return nil
+}
+
+// checkCurrency fails with a *postgres.ValidationError when a given currency is malformed or has no exchange rate
+func (r *RentalRepository) checkCurrency(currency string) error {
+	if !currencies.ValidCode(currency) {
+		return &postgres.ValidationError{
+			Errors: []postgres.FieldError{{Field: "currency", Reason: postgres.ReasonInvalidValue,
+				Message: "must be an ISO 4217 code such as USD"}},
+			Kind: rentals.ErrInvalidFilter,
+		}
+	}
+
+	if _, ok := r.rates[currency]; !ok {
+		return &postgres.ValidationError{
+			Errors: []postgres.FieldError{{Field: "currency", Reason: postgres.ReasonInvalidValue, Message: "has no exchange rate"}},
+			Kind:   rentals.ErrInvalidFilter,
+		}
+	}
+
+	return nil
+}
+
+// view copies a stored rental the way it is read, with its owner, its prices in the currency of the filter
+// and its distance and relevance when the filter searches for them
+func (r *RentalRepository) view(rental rentals.Model, filter rentals.Filter) rentals.Model {
+	user := r.users[rental.UserID]
+	rental.FirstName, rental.LastName, rental.UserUpdated = user.FirstName, user.LastName, user.Updated
+	rental.Amenities = append(make([]string, 0, len(rental.Amenities)), rental.Amenities...)
+
+	if filter.Currency != "" {
+		rental.PricePerDay = r.convert(rental.PricePerDay, rental.Currency, filter.Currency)
+		rental.CleaningFee = r.convert(rental.CleaningFee, rental.Currency, filter.Currency)
+		rental.Currency = filter.Currency
+	}
+
+	if filter.Near != nil {
+		distance := distanceMeters(*filter.Near, rental) / filter.Radius.Unit.Meters()
+		rental.Distance = &distance
+	}
+
+	if filter.Query != "" {
+		relevance := newTextQuery(filter.Query).relevance(rental)
+		rental.Relevance = &relevance
+	}
+
+	return rental
+}
+
+// convert mirrors the convert_price function of the schema, rounding halves away from zero
+func (r *RentalRepository) convert(amount int, source, target string) int {
+	if source == target {
+		return amount
+	}
+
+	s, t := r.rates[source], r.rates[target]
+	return int(math.Round(float64(amount) * (t.RatePerUSD * math.Pow10(t.MinorUnits)) /
+		(s.RatePerUSD * math.Pow10(s.MinorUnits))))
+}
+
+// applyPatch sets the fields of a rental that the patch sets
+func applyPatch(rental *rentals.Model, patch rentals.Patch) {
+	setString := func(field *string, value *string) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	setInt := func(field *int, value *int) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	setFloat := func(field *float64, value *float64) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	setFloat32 := func(field *float32, value *float32) {
+		if value != nil {
+			*field = *value
+		}
+	}
+
+	setString(&rental.Name, patch.Name)
+	setString(&rental.Description, patch.Description)
+	setString(&rental.Type, patch.Type)
+	setString(&rental.VehicleMake, patch.VehicleMake)
+	setString(&rental.VehicleModel, patch.VehicleModel)
+	setInt(&rental.VehicleYear, patch.VehicleYear)
+	setFloat32(&rental.VehicleLength, patch.VehicleLength)
+	setInt(&rental.Sleeps, patch.Sleeps)
+	setString(&rental.PrimaryImageURL, patch.PrimaryImageURL)
+	setInt(&rental.PricePerDay, patch.PricePerDay)
+	setFloat(&rental.WeeklyDiscountPercent, patch.WeeklyDiscountPercent)
+	setFloat(&rental.MonthlyDiscountPercent, patch.MonthlyDiscountPercent)
+	setFloat(&rental.WeekendSurchargePercent, patch.WeekendSurchargePercent)
+	setInt(&rental.CleaningFee, patch.CleaningFee)
+	setFloat(&rental.ServiceFeePercent, patch.ServiceFeePercent)
+	setString(&rental.Currency, patch.Currency)
+	setString(&rental.HomeCity, patch.HomeCity)
+	setString(&rental.HomeState, patch.HomeState)
+	setString(&rental.HomeZIP, patch.HomeZIP)
+	setString(&rental.HomeCountry, patch.HomeCountry)
+	setFloat32(&rental.LAT, patch.LAT)
+	setFloat32(&rental.LNG, patch.LNG)
+	setInt(&rental.UserID, patch.UserID)
+}
@@ -0,0 +1,240 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/memory"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RentalRepository", func() {
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	seed := memory.Seed{
+		ExchangeRates: []memory.SeedExchangeRate{
+			{Currency: "USD", MinorUnits: 2, RatePerUSD: 1},
+			{Currency: "EUR", MinorUnits: 2, RatePerUSD: 0.92},
+		},
+		Users: []memory.SeedUser{{ID: 1, FirstName: "John", LastName: "Smith"}, {ID: 2, FirstName: "Jane", LastName: "Doe"}},
+		Rentals: []memory.SeedRental{
+			{ID: 1, Name: "Westfalia Pop-top", Type: "camper-van", VehicleMake: "Volkswagen", PricePerDay: 16900,
+				Sleeps: 4, HomeState: "CA", LAT: 33.64, LNG: -117.93, UserID: 1, Amenities: []string{"pets", "kitchen"},
+				RatingAvg: 4.5, RatingCount: 2, Created: created},
+			{ID: 2, Name: "Vanagon Camper", Type: "camper-van", VehicleMake: "volkswagen", PricePerDay: 15000,
+				Sleeps: 2, HomeState: "OR", LAT: 45.51, LNG: -122.68, UserID: 2, Amenities: []string{"kitchen"}, Created: created},
+			{ID: 3, Name: "Airstream", Type: "trailer", VehicleMake: "Airstream", PricePerDay: 9000, Currency: "EUR",
+				Sleeps: 6, HomeState: "CA", LAT: 32.84, LNG: -117.27, UserID: 1, Created: created},
+		},
+	}
+
+	var (
+		repository *memory.RentalRepository
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+		repository, err = memory.NewRentalRepository(seed)
+		Expect(err).ToNot(HaveOccurred())
+		ctx = context.Background()
+	})
+
+	ids := func(page rentals.Page) []int {
+		result := make([]int, 0, len(page.Rentals))
+		for _, rental := range page.Rentals {
+			result = append(result, rental.ID)
+		}
+
+		return result
+	}
+
+	Context("NewRentalRepository", func() {
+		It("should load the sample data", func() {
+			sample, err := memory.ReadSeedFile("../../../fixtures/sample-data.json")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = memory.NewRentalRepository(sample)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should reject a rental of an unknown user", func() {
+			_, err := memory.NewRentalRepository(memory.Seed{
+				ExchangeRates: seed.ExchangeRates,
+				Rentals:       []memory.SeedRental{{ID: 1, UserID: 9}},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject unknown fields in a seed document", func() {
+			_, err := memory.ReadSeed(strings.NewReader(`{"rentals": [{"id": 1, "colour": "blue"}]}`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("RetrieveRentalByID", func() {
+		It("should return the rental together with its owner", func() {
+			rental, err := repository.RetrieveRentalByID(ctx, 1, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rental.FirstName).To(Equal("John"))
+			Expect(rental.Currency).To(Equal("USD"))
+			Expect(rental.Amenities).To(Equal([]string{"kitchen", "pets"}))
+		})
+
+		It("should convert the prices like convert_price", func() {
+			rental, err := repository.RetrieveRentalByID(ctx, 3, "USD")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rental.PricePerDay).To(Equal(9783))
+			Expect(rental.Currency).To(Equal("USD"))
+		})
+
+		It("should reject a currency without an exchange rate", func() {
+			_, err := repository.RetrieveRentalByID(ctx, 1, "CAD")
+			Expect(err).To(MatchError(rentals.ErrInvalidFilter))
+		})
+
+		It("should return a not found error for a missing rental", func() {
+			_, err := repository.RetrieveRentalByID(ctx, 9, "")
+			Expect(err).To(MatchError(postgres.ErrNotFound))
+		})
+
+		It("should return a timeout error when the context is done", func() {
+			deadlineCtx, cancel := context.WithDeadline(ctx, time.Now())
+			defer cancel()
+			_, err := repository.RetrieveRentalByID(deadlineCtx, 1, "")
+			Expect(err).To(MatchError(postgres.ErrTimeout))
+		})
+	})
+
+	Context("RetrieveRentals", func() {
+		It("should filter case-insensitively by make and order by id without a sort", func() {
+			page, err := repository.RetrieveRentals(ctx, rentals.Filter{Makes: []string{"VOLKSWAGEN"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids(page)).To(Equal([]int{1, 2}))
+			Expect(page.Total).To(Equal(2))
+		})
+
+		It("should compare prices in the requested currency", func() {
+			priceMax := 10000
+			page, err := repository.RetrieveRentals(ctx, rentals.Filter{Currency: "USD", PriceMax: &priceMax})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids(page)).To(Equal([]int{3}))
+		})
+
+		It("should keep rentals offering every listed amenity or any of them", func() {
+			page, err := repository.RetrieveRentals(ctx, rentals.Filter{Amenities: []string{"kitchen", "pets"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids(page)).To(Equal([]int{1}))
+
+			page, err = repository.RetrieveRentals(ctx, rentals.Filter{Amenities: []string{"kitchen", "pets"},
+				AmenitiesMatch: rentals.AmenitiesMatchAny})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids(page)).To(Equal([]int{1, 2}))
+		})
+
+		It("should search near a location and sort by distance", func() {
+			page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+				Near:   &rentals.Coordinates{LAT: 33.64, LNG: -117.93},
+				Radius: rentals.Distance{Value: 150, Unit: rentals.Kilometers},
+				Sort:   []rentals.SortKey{{Field: "distance", Descending: true}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids(page)).To(Equal([]int{3, 1}))
+			Expect(*page.Rentals[0].Distance).To(BeNumerically("~", 108, 2))
+			Expect(*page.Rentals[1].Distance).To(BeNumerically("<", 0.01))
+		})
+
+		It("should search by text ranking names above descriptions", func() {
+			page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+				Query: "camp -westfalia",
+				Sort:  []rentals.SortKey{{Field: "relevance", Descending: true}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids(page)).To(Equal([]int{2}))
+			Expect(*page.Rentals[0].Relevance).To(BeNumerically(">", 0))
+		})
+
+		It("should page through the rentals with cursors in the order of the sort keys", func() {
+			filter := rentals.Filter{Sort: []rentals.SortKey{{Field: "sleeps", Descending: true}}, Limit: 2}
+			page, err := repository.RetrieveRentals(ctx, filter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids(page)).To(Equal([]int{3, 1}))
+			Expect(page.NextCursor).ToNot(BeEmpty())
+
+			parsed, err := rentals.ParseFilter(map[string][]string{"sort": {"-sleeps"}, "limit": {"2"},
+				"cursor": {page.NextCursor}})
+			Expect(err).ToNot(HaveOccurred())
+			page, err = repository.RetrieveRentals(ctx, parsed)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids(page)).To(Equal([]int{2}))
+			Expect(page.Total).To(Equal(3))
+			Expect(page.NextCursor).To(BeEmpty())
+		})
+
+		It("should count facets without their own filter", func() {
+			page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+				Types:  []string{"trailer"},
+				Facets: []string{rentals.FacetType, rentals.FacetSleeps},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids(page)).To(Equal([]int{3}))
+			Expect(page.Facets[rentals.FacetType]).To(Equal([]rentals.FacetBucket{
+				{Value: "camper-van", Count: 2}, {Value: "trailer", Count: 1}}))
+
+			sleeps := page.Facets[rentals.FacetSleeps]
+			Expect(sleeps).To(HaveLen(len(rentals.SleepsBucketBounds) + 1))
+			Expect(sleeps[0].Count).To(Equal(0))
+			Expect(sleeps[3].Count).To(Equal(1))
+		})
+	})
+
+	Context("writing rentals", func() {
+		input := rentals.Input{Name: "Westfalia", Type: "camper-van", PricePerDay: 12000, LAT: 45.5, LNG: -122.6, UserID: 2}
+
+		It("should create a rental with the next id", func() {
+			rental, err := repository.CreateRental(ctx, input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rental.ID).To(Equal(4))
+			Expect(rental.Currency).To(Equal("USD"))
+			Expect(rental.LastName).To(Equal("Doe"))
+			Expect(rental.Created).ToNot(BeZero())
+		})
+
+		It("should reject a rental of an unknown user", func() {
+			invalid := input
+			invalid.UserID = 9
+			_, err := repository.CreateRental(ctx, invalid)
+			Expect(err).To(MatchError(postgres.ErrInvalidInput))
+		})
+
+		It("should return a validation error for an invalid rental", func() {
+			_, err := repository.CreateRental(ctx, rentals.Input{})
+			var validationErr *postgres.ValidationError
+			Expect(errors.As(err, &validationErr)).To(BeTrue())
+		})
+
+		It("should patch only the given fields", func() {
+			name := "Renamed"
+			rental, err := repository.PatchRental(ctx, 1, rentals.Patch{Name: &name}, rentals.Precondition{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rental.Name).To(Equal(name))
+			Expect(rental.PricePerDay).To(Equal(16900))
+			Expect(rental.Updated.After(created)).To(BeTrue())
+		})
+
+		It("should reject a patch of a rental updated since it was read", func() {
+			name := "Renamed"
+			_, err := repository.PatchRental(ctx, 1, rentals.Patch{Name: &name}, rentals.Precondition{Updated: created})
+			Expect(err).To(MatchError(postgres.ErrPreconditionFailed))
+		})
+
+		It("should delete a rental once", func() {
+			Expect(repository.DeleteRental(ctx, 2, rentals.Precondition{})).To(Succeed())
+			Expect(repository.DeleteRental(ctx, 2, rentals.Precondition{})).To(MatchError(postgres.ErrNotFound))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+)
+
+// textQuery approximates websearch_to_tsquery over the search vector of the rentals table: every word must
+// start a word of the name, make, model or description of a rental and words prefixed with a minus must not.
+// Prefixes stand in for english stemming, so camper matches campers.
+type textQuery struct {
+	include []string
+	exclude []string
+}
+
+// weights of the fields of a rental, like the A, B and C weights ts_rank uses for the search vector
+var fieldWeights = []struct {
+	text   func(rental rentals.Model) string
+	weight float64
+}{
+	{func(rental rentals.Model) string { return rental.Name }, 1},
+	{func(rental rentals.Model) string { return rental.VehicleMake + " " + rental.VehicleModel }, 0.4},
+	{func(rental rentals.Model) string { return rental.Description }, 0.2},
+}
+
+func newTextQuery(q string) *textQuery {
+	query := &textQuery{}
+	for _, term := range strings.Fields(q) {
+		excluded := strings.HasPrefix(term, "-")
+		for _, word := range words(term) {
+			if excluded {
+				query.exclude = append(query.exclude, word)
+			} else {
+				query.include = append(query.include, word)
+			}
+		}
+	}
+
+	return query
+}
+
+func (q *textQuery) matches(rental rentals.Model) bool {
+	if len(q.include) == 0 {
+		return false
+	}
+
+	for _, word := range q.include {
+		if q.weight(word, rental) == 0 {
+			return false
+		}
+	}
+
+	for _, word := range q.exclude {
+		if q.weight(word, rental) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// relevance is the mean weight of the best field every word is found in, higher values match q better
+func (q *textQuery) relevance(rental rentals.Model) float64 {
+	if len(q.include) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, word := range q.include {
+		total += q.weight(word, rental)
+	}
+
+	return total / float64(len(q.include))
+}
+
+func (q *textQuery) weight(word string, rental rentals.Model) float64 {
+	for _, field := range fieldWeights {
+		for _, candidate := range words(field.text(rental)) {
+			if strings.HasPrefix(candidate, word) {
+				return field.weight
+			}
+		}
+	}
+
+	return 0
+}
+
+// words splits text into lowercase runs of letters and digits
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
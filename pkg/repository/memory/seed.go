@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Seed is the JSON document a RentalRepository starts from, see fixtures/sample-data.json
+type Seed struct {
+	ExchangeRates []SeedExchangeRate `json:"exchange_rates"`
+	Users         []SeedUser         `json:"users"`
+	Rentals       []SeedRental       `json:"rentals"`
+}
+
+// SeedExchangeRate mirrors a row of the exchange_rates table
+type SeedExchangeRate struct {
+	Currency   string  `json:"currency"`
+	MinorUnits int     `json:"minor_units"`
+	RatePerUSD float64 `json:"rate_per_usd"`
+}
+
+type SeedUser struct {
+	ID        int       `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Updated   time.Time `json:"updated"`
+}
+
+// SeedRental mirrors a row of the rentals table, prices are in the minor unit of the currency
+type SeedRental struct {
+	ID                      int       `json:"id"`
+	Name                    string    `json:"name"`
+	Description             string    `json:"description"`
+	Type                    string    `json:"type"`
+	VehicleMake             string    `json:"make"`
+	VehicleModel            string    `json:"model"`
+	VehicleYear             int       `json:"year"`
+	VehicleLength           float32   `json:"length"`
+	Sleeps                  int       `json:"sleeps"`
+	PrimaryImageURL         string    `json:"primary_image_url"`
+	PricePerDay             int       `json:"price_per_day"`
+	WeeklyDiscountPercent   float64   `json:"weekly_discount_percent"`
+	MonthlyDiscountPercent  float64   `json:"monthly_discount_percent"`
+	WeekendSurchargePercent float64   `json:"weekend_surcharge_percent"`
+	CleaningFee             int       `json:"cleaning_fee"`
+	ServiceFeePercent       float64   `json:"service_fee_percent"`
+	Currency                string    `json:"currency"`
+	HomeCity                string    `json:"city"`
+	HomeState               string    `json:"state"`
+	HomeZIP                 string    `json:"zip"`
+	HomeCountry             string    `json:"country"`
+	LAT                     float32   `json:"lat"`
+	LNG                     float32   `json:"lng"`
+	UserID                  int       `json:"user_id"`
+	RatingAvg               float64   `json:"rating_avg"`
+	RatingCount             int       `json:"rating_count"`
+	Amenities               []string  `json:"amenities"`
+	Created                 time.Time `json:"created"`
+	Updated                 time.Time `json:"updated"`
+}
+
+// ReadSeed decodes a seed document rejecting unknown fields, so typos in hand written seeds are not silently ignored
+func ReadSeed(reader io.Reader) (Seed, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	var seed Seed
+	if err := decoder.Decode(&seed); err != nil {
+		return Seed{}, fmt.Errorf("failed to decode seed: %w", err)
+	}
+
+	return seed, nil
+}
+
+// ReadSeedFile reads a seed document from a file
+func ReadSeedFile(path string) (Seed, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Seed{}, fmt.Errorf("failed to open seed file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadSeed(file)
+}
@@ -0,0 +1,239 @@
+package amenities
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository is a constructor function
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// RetrieveAmenities retrieves the whole amenity catalog ordered by slug
+func (r *Repository) RetrieveAmenities(ctx context.Context) ([]Model, error) {
+	rows, err := r.db.QueryContext(ctx, selectAmenitiesOrdered)
+	if err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to execute select amenities query: %w", err))
+	}
+	defer rows.Close()
+
+	return scanAmenities(rows)
+}
+
+// RetrieveAmenityByID retrieves amenity by a given id from repository
+func (r *Repository) RetrieveAmenityByID(ctx context.Context, id int) (Model, error) {
+	if id <= 0 {
+		return Model{}, fmt.Errorf("%w: amenity id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	amenity, err := scanAmenity(r.db.QueryRowContext(ctx, selectAmenityByID, id))
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to scan row: %w", err))
+	}
+
+	return amenity, nil
+}
+
+// CreateAmenity validates and stores a new amenity, a slug already in the catalog fails with postgres.ErrConflict
+func (r *Repository) CreateAmenity(ctx context.Context, input Input) (Model, error) {
+	if err := input.Validate(); err != nil {
+		return Model{}, err
+	}
+
+	amenity, err := scanAmenity(r.db.QueryRowContext(ctx, insertAmenity, input.Slug, input.Name))
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to insert amenity: %w", err))
+	}
+
+	return amenity, nil
+}
+
+// PatchAmenity validates and changes only the fields set by the patch of an amenity by a given id
+func (r *Repository) PatchAmenity(ctx context.Context, id int, patch Patch) (Model, error) {
+	if id <= 0 {
+		return Model{}, fmt.Errorf("%w: amenity id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	if err := patch.Validate(); err != nil {
+		return Model{}, err
+	}
+
+	amenity, err := scanAmenity(r.db.QueryRowContext(ctx, patchAmenity, patch.Slug, patch.Name, id))
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to update amenity: %w", err))
+	}
+
+	return amenity, nil
+}
+
+// DeleteAmenity deletes an amenity by a given id, it is removed from every rental offering it
+func (r *Repository) DeleteAmenity(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: amenity id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	result, err := r.db.ExecContext(ctx, deleteAmenity, id)
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to delete amenity: %w", err))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to read deleted rows: %w", err))
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%w: amenity %d does not exist", postgres.ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// ReplaceRentalAmenities sets the amenities of a rental to exactly the given slugs and returns them ordered by slug.
+// Slugs missing from the catalog fail with a *postgres.ValidationError on the amenities field and nothing is changed.
+func (r *Repository) ReplaceRentalAmenities(ctx context.Context, rentalID int, slugs []string) ([]Model, error) {
+	if rentalID <= 0 {
+		return nil, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	slugs = uniqueSlugs(slugs)
+	for _, slug := range slugs {
+		if !ValidSlug(slug) {
+			return nil, &postgres.ValidationError{
+				Errors: []postgres.FieldError{{Field: "amenities", Reason: postgres.ReasonInvalidValue,
+					Message: fmt.Sprintf("%q is not a valid amenity slug", slug)}},
+				Kind: ErrInvalidAmenity,
+			}
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, rentalExists, rentalID).Scan(&exists); err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to check rental existence: %w", err))
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%w: rental %d does not exist", postgres.ErrNotFound, rentalID)
+	}
+
+	rows, err := tx.QueryContext(ctx, selectAmenitiesBySlug, pq.Array(slugs))
+	if err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to execute select amenities query: %w", err))
+	}
+	defer rows.Close()
+
+	amenities, err := scanAmenities(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(amenities) != len(slugs) {
+		return nil, &postgres.ValidationError{
+			Errors: []postgres.FieldError{{Field: "amenities", Reason: postgres.ReasonInvalidValue,
+				Message: "unknown amenities: " + strings.Join(missingSlugs(slugs, amenities), ", ")}},
+			Kind: ErrInvalidAmenity,
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteRentalAmenities, rentalID); err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to delete rental amenities: %w", err))
+	}
+
+	if len(amenities) > 0 {
+		ids := make([]int64, len(amenities))
+		for i, amenity := range amenities {
+			ids[i] = int64(amenity.ID)
+		}
+
+		if _, err := tx.ExecContext(ctx, insertRentalAmenities, rentalID, pq.Array(ids)); err != nil {
+			return nil, postgres.Classify(fmt.Errorf("failed to insert rental amenities: %w", err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return amenities, nil
+}
+
+// uniqueSlugs returns the slugs sorted and without duplicates
+func uniqueSlugs(slugs []string) []string {
+	unique := make([]string, 0, len(slugs))
+	seen := make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		if !seen[slug] {
+			seen[slug] = true
+			unique = append(unique, slug)
+		}
+	}
+
+	sort.Strings(unique)
+	return unique
+}
+
+func missingSlugs(slugs []string, found []Model) []string {
+	known := make(map[string]bool, len(found))
+	for _, amenity := range found {
+		known[amenity.Slug] = true
+	}
+
+	var missing []string
+	for _, slug := range slugs {
+		if !known[slug] {
+			missing = append(missing, slug)
+		}
+	}
+
+	return missing
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAmenity(row scanner) (Model, error) {
+	var amenity Model
+	if err := row.Scan(&amenity.ID, &amenity.Slug, &amenity.Name); err != nil {
+		return Model{}, err
+	}
+
+	return amenity, nil
+}
+
+func scanAmenities(rows *sql.Rows) ([]Model, error) {
+	amenities := make([]Model, 0)
+	for rows.Next() {
+		amenity, err := scanAmenity(rows)
+		if err != nil {
+			return nil, postgres.Classify(fmt.Errorf("failed to scan a row: %w", err))
+		}
+
+		amenities = append(amenities, amenity)
+	}
+
+	if rows.Err() != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed while iterating over rows: %w", rows.Err()))
+	}
+
+	return amenities, nil
+}
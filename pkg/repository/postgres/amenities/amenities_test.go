@@ -0,0 +1,241 @@
+package amenities_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/amenities"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	expectedSelectAmenities = `SELECT id, slug, name FROM amenities ORDER BY slug`
+	expectedSelectAmenity   = `SELECT id, slug, name FROM amenities WHERE id = $1`
+	expectedInsertAmenity   = `INSERT INTO amenities (slug, name) VALUES ($1, $2) RETURNING id, slug, name`
+	expectedPatchAmenity    = `UPDATE amenities SET slug = COALESCE($1, slug), name = COALESCE($2, name)
+							WHERE id = $3 RETURNING id, slug, name`
+	expectedDeleteAmenity         = `DELETE FROM amenities WHERE id = $1`
+	expectedRentalExists          = `SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`
+	expectedSelectAmenitiesBySlug = `SELECT id, slug, name FROM amenities WHERE slug = ANY($1) ORDER BY slug`
+	expectedDeleteRentalAmenities = `DELETE FROM rental_amenities WHERE rental_id = $1`
+	expectedInsertRentalAmenities = `INSERT INTO rental_amenities (rental_id, amenity_id) SELECT $1, unnest($2::integer[])`
+)
+
+var amenityColumns = []string{"id", "slug", "name"}
+
+var _ = Describe("Amenities", func() {
+	var (
+		repository *amenities.Repository
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		repository = amenities.NewRepository(dbClient)
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("RetrieveAmenities", func() {
+		When("the catalog has amenities", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectAmenities)).
+					WillReturnRows(mock.NewRows(amenityColumns).AddRow(1, "kitchen", "Kitchen").AddRow(2, "pets", "Pet friendly"))
+			})
+
+			It("should return all of them", func() {
+				catalog, err := repository.RetrieveAmenities(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(catalog).To(Equal([]amenities.Model{{ID: 1, Slug: "kitchen", Name: "Kitchen"},
+					{ID: 2, Slug: "pets", Name: "Pet friendly"}}))
+			})
+		})
+
+		When("the query fails", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectAmenities)).WillReturnError(errors.New("err"))
+			})
+
+			It("should return an error", func() {
+				_, err := repository.RetrieveAmenities(ctx)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Context("RetrieveAmenityByID", func() {
+		When("the amenity does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectAmenity)).WithArgs(9).
+					WillReturnRows(mock.NewRows(amenityColumns))
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.RetrieveAmenityByID(ctx, 9)
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+	})
+
+	Context("CreateAmenity", func() {
+		When("the slug is new", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedInsertAmenity)).WithArgs("pet-friendly", "Pet friendly").
+					WillReturnRows(mock.NewRows(amenityColumns).AddRow(3, "pet-friendly", "Pet friendly"))
+			})
+
+			It("should store the amenity", func() {
+				amenity, err := repository.CreateAmenity(ctx, amenities.Input{Slug: "pet-friendly", Name: "Pet friendly"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(amenity).To(Equal(amenities.Model{ID: 3, Slug: "pet-friendly", Name: "Pet friendly"}))
+			})
+		})
+
+		When("the slug is already in the catalog", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedInsertAmenity)).WithArgs("kitchen", "Kitchen").
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "amenities_slug_key"})
+			})
+
+			It("should return a conflict error", func() {
+				_, err := repository.CreateAmenity(ctx, amenities.Input{Slug: "kitchen", Name: "Kitchen"})
+				Expect(err).To(MatchError(postgres.ErrConflict))
+			})
+		})
+
+		When("the amenity is invalid", func() {
+			It("should report every invalid field without querying the database", func() {
+				_, err := repository.CreateAmenity(ctx, amenities.Input{Slug: "Pet Friendly"})
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr).To(MatchError(amenities.ErrInvalidAmenity))
+				Expect(validationErr.Errors).To(HaveLen(2))
+				Expect(validationErr.Errors[0].Field).To(Equal("slug"))
+				Expect(validationErr.Errors[1].Field).To(Equal("name"))
+			})
+		})
+	})
+
+	Context("PatchAmenity", func() {
+		When("only the name is set", func() {
+			name := "Full kitchen"
+
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedPatchAmenity)).WithArgs(nil, &name, 1).
+					WillReturnRows(mock.NewRows(amenityColumns).AddRow(1, "kitchen", name))
+			})
+
+			It("should keep the slug", func() {
+				amenity, err := repository.PatchAmenity(ctx, 1, amenities.Patch{Name: &name})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(amenity).To(Equal(amenities.Model{ID: 1, Slug: "kitchen", Name: name}))
+			})
+		})
+	})
+
+	Context("DeleteAmenity", func() {
+		When("the amenity exists", func() {
+			BeforeEach(func() {
+				mock.ExpectExec(regexp.QuoteMeta(expectedDeleteAmenity)).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+			})
+
+			It("should delete it", func() {
+				Expect(repository.DeleteAmenity(ctx, 1)).To(Succeed())
+			})
+		})
+
+		When("the amenity does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectExec(regexp.QuoteMeta(expectedDeleteAmenity)).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+			})
+
+			It("should return a not found error", func() {
+				Expect(repository.DeleteAmenity(ctx, 9)).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+	})
+
+	Context("ReplaceRentalAmenities", func() {
+		When("every slug is in the catalog", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectAmenitiesBySlug)).WithArgs(`{"kitchen","pets"}`).
+					WillReturnRows(mock.NewRows(amenityColumns).AddRow(1, "kitchen", "Kitchen").AddRow(2, "pets", "Pet friendly"))
+				mock.ExpectExec(regexp.QuoteMeta(expectedDeleteRentalAmenities)).WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(regexp.QuoteMeta(expectedInsertRentalAmenities)).WithArgs(1, "{1,2}").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			})
+
+			It("should replace the amenities of the rental, ignoring duplicates", func() {
+				replaced, err := repository.ReplaceRentalAmenities(ctx, 1, []string{"pets", "kitchen", "pets"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(replaced).To(HaveLen(2))
+				Expect(replaced[0].Slug).To(Equal("kitchen"))
+			})
+		})
+
+		When("the amenities are cleared", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectAmenitiesBySlug)).WithArgs("{}").
+					WillReturnRows(mock.NewRows(amenityColumns))
+				mock.ExpectExec(regexp.QuoteMeta(expectedDeleteRentalAmenities)).WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			})
+
+			It("should only delete the current ones", func() {
+				replaced, err := repository.ReplaceRentalAmenities(ctx, 1, nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(replaced).To(BeEmpty())
+			})
+		})
+
+		When("a slug is not in the catalog", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectAmenitiesBySlug)).WithArgs(`{"kitchen","sauna"}`).
+					WillReturnRows(mock.NewRows(amenityColumns).AddRow(1, "kitchen", "Kitchen"))
+				mock.ExpectRollback()
+			})
+
+			It("should return a validation error naming the unknown slugs", func() {
+				_, err := repository.ReplaceRentalAmenities(ctx, 1, []string{"kitchen", "sauna"})
+				Expect(err).To(MatchError(amenities.ErrInvalidAmenity))
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Errors).To(ConsistOf(postgres.FieldError{Field: "amenities",
+					Reason: postgres.ReasonInvalidValue, Message: "unknown amenities: sauna"}))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.ReplaceRentalAmenities(ctx, 1, []string{"kitchen"})
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+	})
+})
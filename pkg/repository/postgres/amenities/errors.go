@@ -0,0 +1,6 @@
+package amenities
+
+import "errors"
+
+// ErrInvalidAmenity is returned when the fields of a written amenity, or the amenities of a rental, are not valid
+var ErrInvalidAmenity = errors.New("invalid amenity")
@@ -0,0 +1,55 @@
+package amenities
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+// MaxSlugLength is the maximum length of an amenity slug
+const MaxSlugLength = 50
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidSlug reports whether s is a lowercase, dash separated amenity slug such as "pet-friendly"
+func ValidSlug(s string) bool {
+	return len(s) <= MaxSlugLength && slugPattern.MatchString(s)
+}
+
+// Input holds every writable field of an amenity, it is used when creating an amenity
+type Input struct {
+	Slug string
+	Name string
+}
+
+// Patch holds the fields of a partial update, nil fields are left unchanged
+type Patch struct {
+	Slug *string
+	Name *string
+}
+
+// Validate checks the fields set by the patch and returns every problem as a *postgres.ValidationError.
+// Field names follow the JSON representation of an amenity.
+func (p Patch) Validate() error {
+	var errs []postgres.FieldError
+	if p.Slug != nil && !ValidSlug(*p.Slug) {
+		errs = append(errs, postgres.FieldError{Field: "slug", Reason: postgres.ReasonInvalidValue,
+			Message: fmt.Sprintf("must be lowercase letters and digits separated by dashes, at most %d characters", MaxSlugLength)})
+	}
+
+	if p.Name != nil && *p.Name == "" {
+		errs = append(errs, postgres.FieldError{Field: "name", Reason: postgres.ReasonInvalidValue, Message: "must not be empty"})
+	}
+
+	if len(errs) > 0 {
+		return &postgres.ValidationError{Errors: errs, Kind: ErrInvalidAmenity}
+	}
+
+	return nil
+}
+
+// Validate checks every field of the input and returns every problem as a *postgres.ValidationError
+func (i Input) Validate() error {
+	return Patch{Slug: &i.Slug, Name: &i.Name}.Validate()
+}
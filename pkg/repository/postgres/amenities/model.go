@@ -0,0 +1,8 @@
+package amenities
+
+// Model is an entry of the amenity catalog, Slug is how clients refer to it when filtering rentals
+type Model struct {
+	ID   int
+	Slug string
+	Name string
+}
@@ -0,0 +1,25 @@
+package amenities
+
+const amenityColumns = `id, slug, name`
+
+const selectAmenities = `SELECT ` + amenityColumns + ` FROM amenities`
+
+const selectAmenitiesOrdered = selectAmenities + ` ORDER BY slug`
+
+const selectAmenityByID = selectAmenities + ` WHERE id = $1`
+
+const insertAmenity = `INSERT INTO amenities (slug, name) VALUES ($1, $2) RETURNING ` + amenityColumns
+
+// patchAmenity keeps a column unchanged when its argument is NULL
+const patchAmenity = `UPDATE amenities SET slug = COALESCE($1, slug), name = COALESCE($2, name)
+							WHERE id = $3 RETURNING ` + amenityColumns
+
+const deleteAmenity = `DELETE FROM amenities WHERE id = $1`
+
+const rentalExists = `SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`
+
+const selectAmenitiesBySlug = selectAmenities + ` WHERE slug = ANY($1) ORDER BY slug`
+
+const deleteRentalAmenities = `DELETE FROM rental_amenities WHERE rental_id = $1`
+
+const insertRentalAmenities = `INSERT INTO rental_amenities (rental_id, amenity_id) SELECT $1, unnest($2::integer[])`
@@ -0,0 +1,94 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository is a constructor function
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// RetrieveActiveKeyByHash retrieves the api key whose secret hashes to a given hash, see auth.HashAPIKey.
+// Revoked keys are not found.
+func (r *Repository) RetrieveActiveKeyByHash(ctx context.Context, hash string) (Model, error) {
+	key, err := scanKey(r.db.QueryRowContext(ctx, selectActiveKeyByHash, hash))
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to scan row: %w", err))
+	}
+
+	return key, nil
+}
+
+// CreateKey stores the hash of a new api key, the secret itself is never stored
+func (r *Repository) CreateKey(ctx context.Context, name string, userID *int, hash string) (Model, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Model{}, fmt.Errorf("%w: api key name must not be empty", postgres.ErrInvalidInput)
+	}
+
+	if hash == "" {
+		return Model{}, fmt.Errorf("%w: api key hash must not be empty", postgres.ErrInvalidInput)
+	}
+
+	key, err := scanKey(r.db.QueryRowContext(ctx, insertKey, name, hash, userID))
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to insert api key: %w", err))
+	}
+
+	return key, nil
+}
+
+// RevokeKey stops an api key from authenticating, revoking a key twice fails with postgres.ErrNotFound
+func (r *Repository) RevokeKey(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: api key id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	result, err := r.db.ExecContext(ctx, revokeKey, id)
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to revoke api key: %w", err))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to read affected rows: %w", err))
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%w: api key %d does not exist or is revoked", postgres.ErrNotFound, id)
+	}
+
+	return nil
+}
+
+func scanKey(row *sql.Row) (Model, error) {
+	var key Model
+	var userID sql.NullInt64
+	var revoked sql.NullTime
+	if err := row.Scan(&key.ID, &key.Name, &userID, &key.Created, &revoked); err != nil {
+		return Model{}, err
+	}
+
+	if userID.Valid {
+		id := int(userID.Int64)
+		key.UserID = &id
+	}
+
+	if revoked.Valid {
+		key.Revoked = &revoked.Time
+	}
+
+	return key, nil
+}
@@ -0,0 +1,102 @@
+package apikeys_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/apikeys"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const hash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+var columns = []string{"id", "name", "user_id", "created", "revoked"}
+
+var _ = Describe("API keys", func() {
+	var (
+		repository *apikeys.Repository
+		ctx        context.Context
+		created    = time.Date(2022, 2, 3, 4, 5, 6, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		repository = apikeys.NewRepository(dbClient)
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("RetrieveActiveKeyByHash", func() {
+		When("an active key has the hash", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, user_id, created, revoked FROM api_keys WHERE key_hash = $1 AND revoked IS NULL`)).
+					WithArgs(hash).
+					WillReturnRows(mock.NewRows(columns).AddRow(4, "ci", 3, created, nil))
+			})
+
+			It("should return the key and its user", func() {
+				key, err := repository.RetrieveActiveKeyByHash(ctx, hash)
+				Expect(err).ToNot(HaveOccurred())
+				userID := 3
+				Expect(key).To(Equal(apikeys.Model{ID: 4, Name: "ci", UserID: &userID, Created: created}))
+			})
+		})
+
+		When("no active key has the hash", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`FROM api_keys WHERE key_hash = $1 AND revoked IS NULL`)).
+					WithArgs(hash).
+					WillReturnError(sql.ErrNoRows)
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.RetrieveActiveKeyByHash(ctx, hash)
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+	})
+
+	Context("CreateKey", func() {
+		When("the key belongs to no user", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO api_keys (name, key_hash, user_id) VALUES ($1, $2, $3) RETURNING id, name, user_id, created, revoked`)).
+					WithArgs("billing", hash, nil).
+					WillReturnRows(mock.NewRows(columns).AddRow(5, "billing", nil, created, nil))
+			})
+
+			It("should store only the hash", func() {
+				key, err := repository.CreateKey(ctx, " billing ", nil, hash)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(key).To(Equal(apikeys.Model{ID: 5, Name: "billing", Created: created}))
+			})
+		})
+
+		It("should reject an empty name without querying", func() {
+			_, err := repository.CreateKey(ctx, " ", nil, hash)
+			Expect(err).To(MatchError(postgres.ErrInvalidInput))
+		})
+	})
+
+	Context("RevokeKey", func() {
+		It("should revoke an active key", func() {
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE api_keys SET revoked = now() WHERE id = $1 AND revoked IS NULL`)).
+				WithArgs(4).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			Expect(repository.RevokeKey(ctx, 4)).To(Succeed())
+		})
+
+		It("should return a not found error for a revoked key", func() {
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE api_keys SET revoked = now()`)).
+				WithArgs(4).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			Expect(repository.RevokeKey(ctx, 4)).To(MatchError(postgres.ErrNotFound))
+		})
+	})
+})
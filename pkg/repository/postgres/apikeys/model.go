@@ -0,0 +1,12 @@
+package apikeys
+
+import "time"
+
+// Model is an api key without its secret, UserID is nil for keys of services that act on behalf of no user
+type Model struct {
+	ID      int
+	Name    string
+	UserID  *int
+	Created time.Time
+	Revoked *time.Time
+}
@@ -0,0 +1,10 @@
+package apikeys
+
+const apiKeyColumns = `id, name, user_id, created, revoked`
+
+// selectActiveKeyByHash skips revoked keys, so they authenticate like unknown ones
+const selectActiveKeyByHash = `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1 AND revoked IS NULL`
+
+const insertKey = `INSERT INTO api_keys (name, key_hash, user_id) VALUES ($1, $2, $3) RETURNING ` + apiKeyColumns
+
+const revokeKey = `UPDATE api_keys SET revoked = now() WHERE id = $1 AND revoked IS NULL`
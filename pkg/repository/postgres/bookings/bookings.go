@@ -0,0 +1,95 @@
+package bookings
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository is a constructor function
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// CreateBooking validates and stores a new booking of a rental.
+// A booking that overlaps an existing booking of the same rental fails with postgres.ErrConflict.
+func (r *Repository) CreateBooking(ctx context.Context, input Input) (Model, error) {
+	if input.RentalID <= 0 {
+		return Model{}, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	if err := input.Validate(); err != nil {
+		return Model{}, err
+	}
+
+	var booking Model
+	err := r.db.QueryRowContext(ctx, insertBooking, input.RentalID, input.UserID, input.From, input.To).
+		Scan(&booking.ID, &booking.RentalID, &booking.UserID, &booking.From, &booking.To, &booking.Created)
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to insert booking: %w", err))
+	}
+
+	return booking, nil
+}
+
+// RetrieveAvailability retrieves the booked and the free periods of a rental within a window.
+// Both queries run in a single read-only transaction, so they observe the same snapshot.
+func (r *Repository) RetrieveAvailability(ctx context.Context, rentalID int, window Window) (Availability, error) {
+	if rentalID <= 0 {
+		return Availability{}, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return Availability{}, postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, rentalExists, rentalID).Scan(&exists); err != nil {
+		return Availability{}, postgres.Classify(fmt.Errorf("failed to check rental existence: %w", err))
+	}
+
+	if !exists {
+		return Availability{}, fmt.Errorf("%w: rental %d does not exist", postgres.ErrNotFound, rentalID)
+	}
+
+	rows, err := tx.QueryContext(ctx, selectBookedPeriods, rentalID, window.From, window.To)
+	if err != nil {
+		return Availability{}, postgres.Classify(fmt.Errorf("failed to execute select booked periods query: %w", err))
+	}
+	defer rows.Close()
+
+	booked := make([]Period, 0)
+	for rows.Next() {
+		var period Period
+		if err := rows.Scan(&period.From, &period.To); err != nil {
+			return Availability{}, postgres.Classify(fmt.Errorf("failed to scan a row: %w", err))
+		}
+
+		booked = append(booked, clip(period, window))
+	}
+
+	if rows.Err() != nil {
+		return Availability{}, postgres.Classify(fmt.Errorf("failed while iterating over rows: %w", rows.Err()))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Availability{}, postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return Availability{
+		RentalID: rentalID,
+		Window:   window,
+		Booked:   booked,
+		Free:     freePeriods(window, booked),
+	}, nil
+}
@@ -0,0 +1,201 @@
+package bookings_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/bookings"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	expectedInsertBooking = `INSERT INTO bookings (rental_id, user_id, period)
+							SELECT $1, $2, daterange($3::date, $4::date)
+							WHERE EXISTS (SELECT 1 FROM rentals WHERE id = $1)
+							RETURNING id, rental_id, user_id, lower(period), upper(period), created`
+	expectedSelectBookedPeriods = `SELECT lower(period), upper(period) FROM bookings
+							WHERE rental_id = $1 AND period && daterange($2::date, $3::date)
+							ORDER BY lower(period)`
+)
+
+func date(day int) time.Time {
+	return time.Date(2024, 6, day, 0, 0, 0, 0, time.UTC)
+}
+
+// upcoming is the date a number of days from today in UTC, bookings cannot start in the past
+func upcoming(days int) time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day()+days, 0, 0, 0, 0, time.UTC)
+}
+
+var _ = Describe("Bookings", func() {
+	var (
+		repository *bookings.Repository
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		repository = bookings.NewRepository(dbClient)
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("CreateBooking", func() {
+		input := bookings.Input{RentalID: 1, UserID: 2, From: upcoming(1), To: upcoming(5)}
+		created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		When("the rental is free", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedInsertBooking)).
+					WithArgs(1, 2, upcoming(1), upcoming(5)).
+					WillReturnRows(mock.NewRows([]string{"id", "rental_id", "user_id", "lower", "upper", "created"}).
+						AddRow(9, 1, 2, upcoming(1), upcoming(5), created))
+			})
+
+			It("should store the booking", func() {
+				booking, err := repository.CreateBooking(ctx, input)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(booking).To(Equal(bookings.Model{ID: 9, RentalID: 1, UserID: 2, From: upcoming(1), To: upcoming(5), Created: created}))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedInsertBooking)).
+					WithArgs(1, 2, upcoming(1), upcoming(5)).
+					WillReturnRows(mock.NewRows([]string{"id"}))
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.CreateBooking(ctx, input)
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		When("the booking overlaps an existing one", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedInsertBooking)).
+					WithArgs(1, 2, upcoming(1), upcoming(5)).
+					WillReturnError(&pq.Error{Code: "23P01", Constraint: "bookings_no_overlap"})
+			})
+
+			It("should return a conflict error", func() {
+				_, err := repository.CreateBooking(ctx, input)
+				Expect(err).To(MatchError(postgres.ErrConflict))
+			})
+		})
+
+		DescribeTable("invalid input",
+			func(input bookings.Input) {
+				_, err := repository.CreateBooking(ctx, input)
+				Expect(err).To(MatchError(bookings.ErrInvalidBooking))
+			},
+			Entry("non-positive user id", bookings.Input{RentalID: 1, From: upcoming(1), To: upcoming(5)}),
+			Entry("missing dates", bookings.Input{RentalID: 1, UserID: 2}),
+			Entry("to before from", bookings.Input{RentalID: 1, UserID: 2, From: upcoming(5), To: upcoming(1)}),
+			Entry("stay longer than the maximum", bookings.Input{RentalID: 1, UserID: 2, From: upcoming(1), To: upcoming(1).AddDate(1, 1, 0)}),
+			Entry("finished stay", bookings.Input{RentalID: 1, UserID: 2, From: date(1), To: date(2)}),
+		)
+
+		When("the stay starts before today", func() {
+			It("should reject from as out of range", func() {
+				_, err := repository.CreateBooking(ctx, bookings.Input{RentalID: 1, UserID: 2, From: upcoming(-1), To: upcoming(1)})
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Errors).To(Equal([]postgres.FieldError{
+					{Field: "from", Reason: postgres.ReasonOutOfRange, Message: "must not be before today"}}))
+			})
+		})
+
+		When("rental id is not positive", func() {
+			It("should return an invalid input error", func() {
+				_, err := repository.CreateBooking(ctx, bookings.Input{UserID: 2, From: upcoming(1), To: upcoming(5)})
+				Expect(err).To(MatchError(postgres.ErrInvalidInput))
+			})
+		})
+	})
+
+	Context("RetrieveAvailability", func() {
+		window := bookings.Window{From: date(1), To: date(20)}
+
+		When("the rental has bookings within the window", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`)).
+					WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectBookedPeriods)).
+					WithArgs(1, date(1), date(20)).
+					WillReturnRows(mock.NewRows([]string{"lower", "upper"}).
+						AddRow(time.Date(2024, 5, 28, 0, 0, 0, 0, time.UTC), date(3)).
+						AddRow(date(10), date(12)).
+						AddRow(date(12), date(15)))
+				mock.ExpectCommit()
+			})
+
+			It("should return the booked periods clipped to the window and the gaps between them", func() {
+				availability, err := repository.RetrieveAvailability(ctx, 1, window)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(availability.Available()).To(BeFalse())
+				Expect(availability.Booked).To(Equal([]bookings.Period{
+					{From: date(1), To: date(3)}, {From: date(10), To: date(12)}, {From: date(12), To: date(15)}}))
+				Expect(availability.Free).To(Equal([]bookings.Period{
+					{From: date(3), To: date(10)}, {From: date(15), To: date(20)}}))
+			})
+		})
+
+		When("the rental has no bookings within the window", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`)).
+					WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectBookedPeriods)).
+					WithArgs(1, date(1), date(20)).
+					WillReturnRows(mock.NewRows([]string{"lower", "upper"}))
+				mock.ExpectCommit()
+			})
+
+			It("should return the whole window as free", func() {
+				availability, err := repository.RetrieveAvailability(ctx, 1, window)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(availability.Available()).To(BeTrue())
+				Expect(availability.Free).To(Equal([]bookings.Period{{From: date(1), To: date(20)}}))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`)).
+					WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.RetrieveAvailability(ctx, 1, window)
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		When("beginning the transaction fails", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin().WillReturnError(errors.New("err"))
+			})
+
+			It("should return an error", func() {
+				_, err := repository.RetrieveAvailability(ctx, 1, window)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,10 @@
+package bookings
+
+import "errors"
+
+var (
+	// ErrInvalidWindow is returned when query parameters cannot be parsed into an availability window
+	ErrInvalidWindow = errors.New("invalid availability window")
+	// ErrInvalidBooking is returned when the fields of a booking are not valid
+	ErrInvalidBooking = errors.New("invalid booking")
+)
@@ -0,0 +1,57 @@
+package bookings
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+// Input holds the fields of a new booking
+type Input struct {
+	RentalID int
+	UserID   int
+	From     time.Time
+	To       time.Time
+}
+
+// Validate checks every field of the input and returns every problem as a *postgres.ValidationError.
+// Field names follow the JSON representation of a booking. A booking starts today (UTC) at the earliest,
+// so a finished stay cannot be booked after the fact.
+func (i Input) Validate() error {
+	var errs []postgres.FieldError
+	if i.UserID <= 0 {
+		errs = append(errs, postgres.FieldError{Field: "user_id", Reason: postgres.ReasonInvalidValue, Message: "must be a positive integer"})
+	}
+
+	if i.From.IsZero() {
+		errs = append(errs, postgres.FieldError{Field: "from", Reason: postgres.ReasonInvalidValue, Message: "is required"})
+	} else if i.From.Before(today()) {
+		errs = append(errs, postgres.FieldError{Field: "from", Reason: postgres.ReasonOutOfRange, Message: "must not be before today"})
+	}
+
+	if i.To.IsZero() {
+		errs = append(errs, postgres.FieldError{Field: "to", Reason: postgres.ReasonInvalidValue, Message: "is required"})
+	}
+
+	if !i.From.IsZero() && !i.To.IsZero() {
+		if !i.To.After(i.From) {
+			errs = append(errs, postgres.FieldError{Field: "to", Reason: postgres.ReasonOutOfRange, Message: "must be after from"})
+		} else if nights(i.From, i.To) > MaxNights {
+			errs = append(errs, postgres.FieldError{Field: "to", Reason: postgres.ReasonOutOfRange,
+				Message: fmt.Sprintf("must be at most %d nights after from", MaxNights)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &postgres.ValidationError{Errors: errs, Kind: ErrInvalidBooking}
+	}
+
+	return nil
+}
+
+// today is the start of the current day in UTC, the time zone dates of bookings are in
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
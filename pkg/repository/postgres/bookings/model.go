@@ -0,0 +1,69 @@
+package bookings
+
+import "time"
+
+// DateLayout is the format of the dates accepted and returned by the bookings API
+const DateLayout = "2006-01-02"
+
+// Model is a booking of a rental, the rental is taken from the night of From up to but excluding To
+type Model struct {
+	ID       int
+	RentalID int
+	UserID   int
+	From     time.Time
+	To       time.Time
+	Created  time.Time
+}
+
+// Period is a half-open range of nights [From, To)
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// Availability describes which nights of a window are booked and which are free
+type Availability struct {
+	RentalID int
+	Window   Window
+	Booked   []Period
+	Free     []Period
+}
+
+// Available reports whether no night of the window is booked
+func (a Availability) Available() bool {
+	return len(a.Booked) == 0
+}
+
+// freePeriods returns the gaps of the window left by the booked periods, which must be sorted and must not overlap
+func freePeriods(window Window, booked []Period) []Period {
+	free := make([]Period, 0)
+	start := window.From
+	for _, period := range booked {
+		if period.From.After(start) {
+			free = append(free, Period{From: start, To: period.From})
+		}
+
+		if period.To.After(start) {
+			start = period.To
+		}
+	}
+
+	if window.To.After(start) {
+		free = append(free, Period{From: start, To: window.To})
+	}
+
+	return free
+}
+
+// clip limits a period to the nights of the window
+func clip(period Period, window Window) Period {
+	if period.From.Before(window.From) {
+		period.From = window.From
+	}
+
+	if period.To.After(window.To) {
+		period.To = window.To
+	}
+
+	return period
+}
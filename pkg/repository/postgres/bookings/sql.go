@@ -0,0 +1,15 @@
+package bookings
+
+const bookingColumns = `id, rental_id, user_id, lower(period), upper(period), created`
+
+// insertBooking inserts nothing when the rental does not exist, overlapping bookings are rejected by bookings_no_overlap
+const insertBooking = `INSERT INTO bookings (rental_id, user_id, period)
+							SELECT $1, $2, daterange($3::date, $4::date)
+							WHERE EXISTS (SELECT 1 FROM rentals WHERE id = $1)
+							RETURNING ` + bookingColumns
+
+const rentalExists = `SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`
+
+const selectBookedPeriods = `SELECT lower(period), upper(period) FROM bookings
+							WHERE rental_id = $1 AND period && daterange($2::date, $3::date)
+							ORDER BY lower(period)`
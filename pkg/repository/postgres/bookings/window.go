@@ -0,0 +1,88 @@
+package bookings
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+const (
+	// MaxNights is the longest stay a single booking may cover
+	MaxNights = 365
+	// MaxWindowDays is the longest window availability may be requested for
+	MaxWindowDays = 366
+)
+
+// Window is a half-open range of dates [From, To) that availability is requested for
+type Window struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseWindow parses and validates the from and to query parameters of an availability request.
+// All problems are collected and returned together as a *postgres.ValidationError.
+func ParseWindow(query map[string][]string) (Window, error) {
+	var errs []postgres.FieldError
+	fail := func(field string, reason postgres.Reason, message string) {
+		errs = append(errs, postgres.FieldError{Field: field, Reason: reason, Message: message})
+	}
+
+	date := func(key string) time.Time {
+		values, ok := query[key]
+		if !ok || len(values) == 0 {
+			fail(key, postgres.ReasonInvalidValue, "is required")
+			return time.Time{}
+		}
+
+		if len(values) > 1 {
+			fail(key, postgres.ReasonInvalidValue, "must be given once")
+			return time.Time{}
+		}
+
+		value, err := ParseDate(values[0])
+		if err != nil {
+			fail(key, postgres.ReasonInvalidValue, "must be a date in YYYY-MM-DD format")
+			return time.Time{}
+		}
+
+		return value
+	}
+
+	window := Window{From: date("from"), To: date("to")}
+	if !window.From.IsZero() && !window.To.IsZero() {
+		if !window.To.After(window.From) {
+			fail("to", postgres.ReasonOutOfRange, "must be after from")
+		} else if nights(window.From, window.To) > MaxWindowDays {
+			fail("to", postgres.ReasonOutOfRange, fmt.Sprintf("must be at most %d days after from", MaxWindowDays))
+		}
+	}
+
+	unknown := make([]string, 0)
+	for key := range query {
+		if key != "from" && key != "to" {
+			unknown = append(unknown, key)
+		}
+	}
+
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		fail(key, postgres.ReasonUnknownParameter, "is not a supported query parameter")
+	}
+
+	if len(errs) > 0 {
+		return Window{}, &postgres.ValidationError{Errors: errs, Kind: ErrInvalidWindow}
+	}
+
+	return window, nil
+}
+
+// ParseDate parses a calendar date in DateLayout as midnight UTC
+func ParseDate(value string) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, value, time.UTC)
+}
+
+func nights(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
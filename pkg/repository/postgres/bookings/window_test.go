@@ -0,0 +1,37 @@
+package bookings_test
+
+import (
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/bookings"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Window", func() {
+	When("from and to are valid dates", func() {
+		It("should parse them as midnight UTC", func() {
+			window, err := bookings.ParseWindow(map[string][]string{"from": {"2024-06-01"}, "to": {"2024-06-10"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(window).To(Equal(bookings.Window{
+				From: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+			}))
+		})
+	})
+
+	DescribeTable("malformed query parameters",
+		func(query map[string][]string) {
+			_, err := bookings.ParseWindow(query)
+			Expect(err).To(MatchError(bookings.ErrInvalidWindow))
+		},
+		Entry("missing from", map[string][]string{"to": {"2024-06-10"}}),
+		Entry("missing to", map[string][]string{"from": {"2024-06-01"}}),
+		Entry("from that is not a date", map[string][]string{"from": {"06/01/2024"}, "to": {"2024-06-10"}}),
+		Entry("to before from", map[string][]string{"from": {"2024-06-10"}, "to": {"2024-06-01"}}),
+		Entry("to equal to from", map[string][]string{"from": {"2024-06-01"}, "to": {"2024-06-01"}}),
+		Entry("window longer than the maximum", map[string][]string{"from": {"2024-01-01"}, "to": {"2025-06-01"}}),
+		Entry("unknown parameter", map[string][]string{"from": {"2024-06-01"}, "to": {"2024-06-10"}, "nights": {"3"}}),
+		Entry("repeated from", map[string][]string{"from": {"2024-06-01", "2024-06-05"}, "to": {"2024-06-10"}}),
+	)
+})
@@ -0,0 +1,71 @@
+package currencies
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+var csvHeader = []string{"currency", "minor_units", "rate_per_usd"}
+
+// ReadCSV reads exchange rates from a file with a currency,minor_units,rate_per_usd header.
+// Every invalid line is reported together as a *postgres.ValidationError, fields are named after the line, e.g. line 3.
+func ReadCSV(reader io.Reader) ([]ExchangeRate, error) {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRates, err)
+	}
+
+	if len(records) == 0 || strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		return nil, fmt.Errorf("%w: the first line must be %s", ErrInvalidRates, strings.Join(csvHeader, ","))
+	}
+
+	var errs []postgres.FieldError
+	rates := make([]ExchangeRate, 0, len(records)-1)
+	seen := make(map[string]bool)
+	for i, record := range records[1:] {
+		rate, err := parseRate(record)
+		if err == nil && seen[rate.Currency] {
+			err = fmt.Errorf("repeats currency %s", rate.Currency)
+		}
+
+		if err != nil {
+			errs = append(errs, postgres.FieldError{Field: fmt.Sprintf("line %d", i+2), Reason: postgres.ReasonInvalidValue, Message: err.Error()})
+			continue
+		}
+
+		seen[rate.Currency] = true
+		rates = append(rates, rate)
+	}
+
+	if len(errs) > 0 {
+		return nil, &postgres.ValidationError{Errors: errs, Kind: ErrInvalidRates}
+	}
+
+	return rates, nil
+}
+
+func parseRate(record []string) (ExchangeRate, error) {
+	currency := strings.TrimSpace(record[0])
+	if !ValidCode(currency) {
+		return ExchangeRate{}, errors.New("currency must be three upper case letters")
+	}
+
+	minorUnits, err := strconv.Atoi(strings.TrimSpace(record[1]))
+	if err != nil || minorUnits < 0 || minorUnits > MaxMinorUnits {
+		return ExchangeRate{}, fmt.Errorf("minor_units must be an integer between 0 and %d", MaxMinorUnits)
+	}
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+	if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+		return ExchangeRate{}, errors.New("rate_per_usd must be a positive number")
+	}
+
+	return ExchangeRate{Currency: currency, MinorUnits: minorUnits, RatePerUSD: rate}, nil
+}
@@ -0,0 +1,41 @@
+package currencies
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository is a constructor function
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// UpsertRates stores exchange rates in a single transaction, currencies missing from rates are left unchanged
+func (r *Repository) UpsertRates(ctx context.Context, rates []ExchangeRate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	for _, rate := range rates {
+		if _, err := tx.ExecContext(ctx, upsertRate, rate.Currency, rate.MinorUnits, rate.RatePerUSD); err != nil {
+			return postgres.Classify(fmt.Errorf("failed to upsert exchange rate of %s: %w", rate.Currency, err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package currencies_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/currencies"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const expectedUpsertRate = `INSERT INTO exchange_rates (currency, minor_units, rate_per_usd, updated) VALUES ($1, $2, $3, now())
+							ON CONFLICT (currency) DO UPDATE
+							SET minor_units = EXCLUDED.minor_units, rate_per_usd = EXCLUDED.rate_per_usd, updated = now()`
+
+var _ = Describe("Currencies", func() {
+	Context("ReadCSV", func() {
+		When("the file is valid", func() {
+			It("should read every rate", func() {
+				rates, err := currencies.ReadCSV(strings.NewReader("currency,minor_units,rate_per_usd\nEUR,2,0.92\nJPY,0,149.85\n"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rates).To(Equal([]currencies.ExchangeRate{
+					{Currency: "EUR", MinorUnits: 2, RatePerUSD: 0.92},
+					{Currency: "JPY", MinorUnits: 0, RatePerUSD: 149.85},
+				}))
+			})
+		})
+
+		When("the header is missing", func() {
+			It("should return an invalid rates error", func() {
+				_, err := currencies.ReadCSV(strings.NewReader("EUR,2,0.92\n"))
+				Expect(err).To(MatchError(currencies.ErrInvalidRates))
+			})
+		})
+
+		When("several lines are invalid", func() {
+			It("should report every one of them", func() {
+				_, err := currencies.ReadCSV(strings.NewReader(
+					"currency,minor_units,rate_per_usd\neur,2,0.92\nJPY,5,149.85\nGBP,2,0\nCAD,2,1.36\nCAD,2,1.37\n"))
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				fields := make([]string, 0)
+				for _, fieldErr := range validationErr.Errors {
+					fields = append(fields, fieldErr.Field)
+				}
+				Expect(fields).To(Equal([]string{"line 2", "line 3", "line 4", "line 6"}))
+			})
+		})
+	})
+
+	Context("UpsertRates", func() {
+		var repository *currencies.Repository
+
+		BeforeEach(func() {
+			repository = currencies.NewRepository(dbClient)
+		})
+
+		AfterEach(func() {
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+
+		When("every rate is stored", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(expectedUpsertRate)).
+					WithArgs("EUR", 2, 0.92).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(expectedUpsertRate)).
+					WithArgs("JPY", 0, 149.85).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("should commit them together", func() {
+				Expect(repository.UpsertRates(context.Background(), []currencies.ExchangeRate{
+					{Currency: "EUR", MinorUnits: 2, RatePerUSD: 0.92},
+					{Currency: "JPY", MinorUnits: 0, RatePerUSD: 149.85},
+				})).To(Succeed())
+			})
+		})
+
+		When("storing a rate fails", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(expectedUpsertRate)).
+					WithArgs("EUR", 2, 0.92).
+					WillReturnError(errors.New("err"))
+				mock.ExpectRollback()
+			})
+
+			It("should roll every rate back", func() {
+				err := repository.UpsertRates(context.Background(), []currencies.ExchangeRate{
+					{Currency: "EUR", MinorUnits: 2, RatePerUSD: 0.92},
+				})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
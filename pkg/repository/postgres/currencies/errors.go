@@ -0,0 +1,6 @@
+package currencies
+
+import "errors"
+
+// ErrInvalidRates is returned when exchange rates cannot be read from a file
+var ErrInvalidRates = errors.New("invalid exchange rates")
@@ -0,0 +1,30 @@
+package currencies
+
+// DefaultCurrency is the currency of rentals written without one
+const DefaultCurrency = "USD"
+
+// MaxMinorUnits is the largest number of decimal digits of a minor unit the exchange_rates table accepts
+const MaxMinorUnits = 4
+
+// ExchangeRate describes a currency, RatePerUSD is the number of major units one US dollar buys
+// and MinorUnits the number of decimal digits of the minor unit, e.g. 2 for cents and 0 for yen
+type ExchangeRate struct {
+	Currency   string
+	MinorUnits int
+	RatePerUSD float64
+}
+
+// ValidCode reports whether code looks like an ISO 4217 currency code, e.g. EUR
+func ValidCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+
+	for _, letter := range code {
+		if letter < 'A' || letter > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,6 @@
+package currencies
+
+// upsertRate keeps the minor units of known currencies in sync, since prices are stored in them
+const upsertRate = `INSERT INTO exchange_rates (currency, minor_units, rate_per_usd, updated) VALUES ($1, $2, $3, now())
+							ON CONFLICT (currency) DO UPDATE
+							SET minor_units = EXCLUDED.minor_units, rate_per_usd = EXCLUDED.rate_per_usd, updated = now()`
@@ -22,3 +22,12 @@ func LoadDBConfig() (Config, error) {
 	}
 	return config, nil
 }
+
+// Validate checks values that envconfig accepts but a connection cannot use
+func (c Config) Validate() error {
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("DB_PORT must be between 1 and 65535, got %d", c.Port)
+	}
+
+	return nil
+}
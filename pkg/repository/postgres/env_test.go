@@ -58,6 +58,18 @@ var _ = Describe("Env", func() {
 		})
 	})
 
+	When("port is out of range", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv(portEnv, "70000")).To(Succeed())
+		})
+
+		It("should load it but fail validation", func() {
+			dbConfig, err := postgres.LoadDBConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dbConfig.Validate()).ToNot(Succeed())
+		})
+	})
+
 	When("port is invalid", func() {
 		BeforeEach(func() {
 			Expect(os.Setenv(portEnv, "invalid-port")).To(Succeed())
@@ -83,5 +95,9 @@ var _ = Describe("Env", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(dbConfig).To(Equal(expectedDBConfig))
 		})
+
+		It("should be valid", func() {
+			Expect(expectedDBConfig.Validate()).To(Succeed())
+		})
 	})
 })
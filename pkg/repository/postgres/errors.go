@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidInput is returned when the database rejects the provided values
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrUnavailable is returned when the database cannot be reached
+	ErrUnavailable = errors.New("database unavailable")
+	// ErrTimeout is returned when a query does not complete in time
+	ErrTimeout = errors.New("database timeout")
+	// ErrConflict is returned when a change is not possible in the current state of other records
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed is returned when a conditional write finds the record changed since it was read
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+type classifiedError struct {
+	kind error
+	err  error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+func (e *classifiedError) Is(target error) bool {
+	return target == e.kind
+}
+
+// Classify marks an error with the matching repository error class, so callers
+// can test it with errors.Is while the original cause stays wrapped
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if kind := classOf(err); kind != nil {
+		return &classifiedError{kind: kind, err: err}
+	}
+
+	return err
+}
+
+func classOf(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return classOfCode(string(pqErr.Code))
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrTimeout
+		}
+
+		return ErrUnavailable
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return ErrUnavailable
+	}
+
+	return nil
+}
+
+// classOfCode maps a Postgres SQLSTATE code to a repository error class
+func classOfCode(code string) error {
+	switch {
+	case code == "57014":
+		// query_canceled, raised when statement_timeout is exceeded
+		return ErrTimeout
+	case strings.HasPrefix(code, "08"), strings.HasPrefix(code, "53"), strings.HasPrefix(code, "57P"):
+		// connection exceptions, insufficient resources and operator interventions
+		return ErrUnavailable
+	case code == "23P01":
+		// exclusion_violation, raised when a row overlaps an existing one such as a booking
+		return ErrConflict
+	case code == "23505":
+		// unique_violation, raised when a row duplicates an existing one such as a second review of a rental
+		return ErrConflict
+	case strings.HasPrefix(code, "22"), strings.HasPrefix(code, "23"):
+		// data exceptions and integrity constraint violations
+		return ErrInvalidInput
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+	DescribeTable("Classify",
+		func(cause error, expected error) {
+			err := postgres.Classify(fmt.Errorf("failed to query: %w", cause))
+			Expect(err).To(MatchError(expected))
+			Expect(err).To(MatchError(cause))
+		},
+		Entry("no rows", sql.ErrNoRows, postgres.ErrNotFound),
+		Entry("context deadline", context.DeadlineExceeded, postgres.ErrTimeout),
+		Entry("statement timeout", &pq.Error{Code: "57014"}, postgres.ErrTimeout),
+		Entry("connection failure", &pq.Error{Code: "08006"}, postgres.ErrUnavailable),
+		Entry("admin shutdown", &pq.Error{Code: "57P01"}, postgres.ErrUnavailable),
+		Entry("invalid text representation", &pq.Error{Code: "22P02"}, postgres.ErrInvalidInput),
+		Entry("exclusion violation", &pq.Error{Code: "23P01"}, postgres.ErrConflict),
+		Entry("unique violation", &pq.Error{Code: "23505"}, postgres.ErrConflict),
+	)
+
+	When("error does not belong to a known class", func() {
+		It("should return it unchanged", func() {
+			cause := errors.New("err")
+			Expect(postgres.Classify(cause)).To(Equal(cause))
+		})
+	})
+
+	When("error is nil", func() {
+		It("should return nil", func() {
+			Expect(postgres.Classify(nil)).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationsLockID identifies the advisory lock held while migrating, so concurrent deployments run one at a time
+const migrationsLockID = 7361804
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+							version integer PRIMARY KEY,
+							name text NOT NULL,
+							applied_at timestamp with time zone NOT NULL DEFAULT now()
+						)`
+
+// migrationFileName matches files such as 0001_create_users_and_rentals.up.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change together with the statements reverting it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration is applied, AppliedAt is nil for pending migrations
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator is a constructor function, it loads the migrations embedded in the binary
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations reads migration files from a directory and orders them by version
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s does not match <version>_<name>.<up|down>.sql", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both an up and a down file", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every pending migration in version order and returns the applied ones
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, versions map[int]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+
+			if err := runMigration(ctx, conn, migration.Up,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name); err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts up to steps of the most recently applied migrations and returns the reverted ones
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, versions map[int]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+
+			if err := runMigration(ctx, conn, migration.Down,
+				"DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+				return fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration in version order. Versions recorded in the database
+// but unknown to the binary are reported too, they come from a newer release.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn, versions map[int]time.Time) error {
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Migration: migration}
+			if appliedAt, ok := versions[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+				delete(versions, migration.Version)
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		for version, appliedAt := range versions {
+			appliedAt := appliedAt
+			statuses = append(statuses, MigrationStatus{Migration: Migration{Version: version}, AppliedAt: &appliedAt})
+		}
+
+		return nil
+	})
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, err
+}
+
+// withLock runs fn on a single connection holding the migrations advisory lock,
+// fn receives the versions applied so far together with the time they were applied
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, versions map[int]time.Time) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return Classify(fmt.Errorf("failed to acquire connection: %w", err))
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationsLockID); err != nil {
+		return Classify(fmt.Errorf("failed to acquire migrations lock: %w", err))
+	}
+	// the lock belongs to the session, release it even when ctx is already done
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationsLockID)
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return Classify(fmt.Errorf("failed to create migrations table: %w", err))
+	}
+
+	versions, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn, versions)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, Classify(fmt.Errorf("failed to select applied migrations: %w", err))
+	}
+	defer rows.Close()
+
+	versions := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, Classify(fmt.Errorf("failed to scan a row: %w", err))
+		}
+
+		versions[version] = appliedAt
+	}
+
+	if rows.Err() != nil {
+		return nil, Classify(fmt.Errorf("failed while iterating over rows: %w", rows.Err()))
+	}
+
+	return versions, nil
+}
+
+// runMigration executes the statements of a migration and records it in a single transaction
+func runMigration(ctx context.Context, conn *sql.Conn, statements, record string, recordArgs ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return Classify(fmt.Errorf("failed to execute statements: %w", err))
+	}
+
+	if _, err := tx.ExecContext(ctx, record, recordArgs...); err != nil {
+		return Classify(fmt.Errorf("failed to record migration: %w", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return nil
+}
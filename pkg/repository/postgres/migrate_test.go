@@ -0,0 +1,178 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migrator", func() {
+	appliedAt := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var (
+		dbClient *sql.DB
+		mock     sqlmock.Sqlmock
+		migrator *postgres.Migrator
+	)
+
+	BeforeEach(func() {
+		var err error
+		dbClient, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		migrator, err = postgres.NewMigrator(dbClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version, applied_at FROM schema_migrations")).
+			WillReturnRows(mock.NewRows([]string{"version", "applied_at"}).
+				AddRow(1, appliedAt).AddRow(2, appliedAt).AddRow(3, appliedAt))
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		mock.ExpectClose()
+		Expect(dbClient.Close()).To(Succeed())
+	})
+
+	When("migrating up", func() {
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE rentals ALTER COLUMN user_id SET NOT NULL")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(4, "add_rentals_owner_and_indexes").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("CREATE EXTENSION IF NOT EXISTS btree_gist")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(5, "create_bookings").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE rentals")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(6, "add_pricing_rules").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS exchange_rates")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(7, "add_currencies").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS reviews")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(8, "create_reviews").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS amenities")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(9, "create_amenities").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE users ADD COLUMN IF NOT EXISTS updated")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(10, "add_users_updated").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS api_keys")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(11, "create_api_keys").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("CREATE OR REPLACE FUNCTION reviews_sync_rental_rating()")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(12, "touch_rentals_updated").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		})
+
+		It("should apply only the pending migrations under the advisory lock", func() {
+			applied, err := migrator.Up(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applied).To(HaveLen(9))
+			Expect(applied[0].Version).To(Equal(4))
+			Expect(applied[1].Version).To(Equal(5))
+			Expect(applied[2].Version).To(Equal(6))
+			Expect(applied[3].Version).To(Equal(7))
+			Expect(applied[4].Version).To(Equal(8))
+			Expect(applied[5].Version).To(Equal(9))
+			Expect(applied[6].Version).To(Equal(10))
+			Expect(applied[7].Version).To(Equal(11))
+			Expect(applied[8].Version).To(Equal(12))
+		})
+	})
+
+	When("a migration fails", func() {
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE rentals")).WillReturnError(errors.New("err"))
+			mock.ExpectRollback()
+			mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		})
+
+		It("should roll it back and release the lock", func() {
+			applied, err := migrator.Up(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(applied).To(BeEmpty())
+		})
+	})
+
+	When("migrating down", func() {
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("DROP TRIGGER IF EXISTS rentals_sync_search_vector ON rentals")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = $1")).
+				WithArgs(3).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		})
+
+		It("should revert the most recently applied migration", func() {
+			reverted, err := migrator.Down(context.Background(), 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reverted).To(HaveLen(1))
+			Expect(reverted[0].Name).To(Equal("add_rentals_search_vector"))
+		})
+	})
+
+	When("listing the status of migrations", func() {
+		BeforeEach(func() {
+			mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		})
+
+		It("should tell applied migrations from pending ones", func() {
+			statuses, err := migrator.Status(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statuses).To(HaveLen(12))
+			Expect(*statuses[2].AppliedAt).To(Equal(appliedAt))
+			Expect(statuses[3].Name).To(Equal("add_rentals_owner_and_indexes"))
+			Expect(statuses[3].AppliedAt).To(BeNil())
+		})
+	})
+})
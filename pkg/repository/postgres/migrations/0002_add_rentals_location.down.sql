@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS rentals_sync_location ON rentals;
+DROP FUNCTION IF EXISTS rentals_sync_location();
+DROP INDEX IF EXISTS rentals_location_idx;
+ALTER TABLE rentals DROP COLUMN IF EXISTS location;
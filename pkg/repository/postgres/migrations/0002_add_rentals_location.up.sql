@@ -0,0 +1,25 @@
+CREATE EXTENSION IF NOT EXISTS postgis;
+
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS location geography(Point, 4326);
+
+CREATE INDEX IF NOT EXISTS rentals_location_idx ON rentals USING GIST (location);
+
+-- keeps the location column in sync with lat and lng on every write
+CREATE OR REPLACE FUNCTION rentals_sync_location() RETURNS trigger AS $$
+BEGIN
+    IF NEW.lat IS NULL OR NEW.lng IS NULL THEN
+        NEW.location := NULL;
+    ELSE
+        NEW.location := ST_SetSRID(ST_MakePoint(NEW.lng, NEW.lat), 4326)::geography;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rentals_sync_location ON rentals;
+CREATE TRIGGER rentals_sync_location
+    BEFORE INSERT OR UPDATE OF lat, lng ON rentals
+    FOR EACH ROW EXECUTE PROCEDURE rentals_sync_location();
+
+UPDATE rentals SET location = ST_SetSRID(ST_MakePoint(lng, lat), 4326)::geography
+    WHERE location IS NULL AND lat IS NOT NULL AND lng IS NOT NULL;
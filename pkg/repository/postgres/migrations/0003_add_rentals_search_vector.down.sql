@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS rentals_sync_search_vector ON rentals;
+DROP FUNCTION IF EXISTS rentals_sync_search_vector();
+DROP INDEX IF EXISTS rentals_search_vector_idx;
+ALTER TABLE rentals DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,22 @@
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE INDEX IF NOT EXISTS rentals_search_vector_idx ON rentals USING GIN (search_vector);
+
+-- keeps the full-text search vector in sync, names weigh more than make and model, which weigh more than descriptions
+CREATE OR REPLACE FUNCTION rentals_sync_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.vehicle_make, '') || ' ' || coalesce(NEW.vehicle_model, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rentals_sync_search_vector ON rentals;
+CREATE TRIGGER rentals_sync_search_vector
+    BEFORE INSERT OR UPDATE OF name, description, vehicle_make, vehicle_model ON rentals
+    FOR EACH ROW EXECUTE PROCEDURE rentals_sync_search_vector();
+
+-- fires the trigger for rentals written before it existed
+UPDATE rentals SET name = name WHERE search_vector IS NULL;
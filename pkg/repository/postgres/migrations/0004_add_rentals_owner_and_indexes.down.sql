@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS rentals_created_idx;
+DROP INDEX IF EXISTS rentals_type_idx;
+DROP INDEX IF EXISTS rentals_vehicle_year_idx;
+DROP INDEX IF EXISTS rentals_price_per_day_idx;
+DROP INDEX IF EXISTS rentals_user_id_idx;
+
+ALTER TABLE rentals DROP CONSTRAINT IF EXISTS rentals_user_id_fkey;
+ALTER TABLE rentals
+    ALTER COLUMN updated DROP NOT NULL,
+    ALTER COLUMN updated DROP DEFAULT,
+    ALTER COLUMN created DROP NOT NULL,
+    ALTER COLUMN created DROP DEFAULT;
+ALTER TABLE rentals ALTER COLUMN user_id DROP NOT NULL;
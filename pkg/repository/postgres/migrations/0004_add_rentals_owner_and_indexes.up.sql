@@ -0,0 +1,20 @@
+ALTER TABLE rentals ALTER COLUMN user_id SET NOT NULL;
+
+-- sort=created|updated and the keyset of a cursor compare on these, a NULL would fail every comparison
+UPDATE rentals SET created = COALESCE(created, updated, now()), updated = COALESCE(updated, created, now())
+    WHERE created IS NULL OR updated IS NULL;
+ALTER TABLE rentals
+    ALTER COLUMN created SET DEFAULT now(),
+    ALTER COLUMN created SET NOT NULL,
+    ALTER COLUMN updated SET DEFAULT now(),
+    ALTER COLUMN updated SET NOT NULL;
+
+ALTER TABLE rentals
+    ADD CONSTRAINT rentals_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+
+-- the foreign key and the most common filters and sort keys of GET /rentals
+CREATE INDEX IF NOT EXISTS rentals_user_id_idx ON rentals (user_id);
+CREATE INDEX IF NOT EXISTS rentals_price_per_day_idx ON rentals (price_per_day, id);
+CREATE INDEX IF NOT EXISTS rentals_vehicle_year_idx ON rentals (vehicle_year, id);
+CREATE INDEX IF NOT EXISTS rentals_type_idx ON rentals (type);
+CREATE INDEX IF NOT EXISTS rentals_created_idx ON rentals (created, id);
@@ -0,0 +1,14 @@
+-- btree_gist lets the exclusion constraint compare rental_id with = next to the range overlap
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- period is a half-open range of nights, [check-in, check-out)
+CREATE TABLE IF NOT EXISTS bookings (
+    id SERIAL PRIMARY KEY,
+    rental_id integer NOT NULL REFERENCES rentals (id) ON DELETE CASCADE,
+    user_id integer NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    period daterange NOT NULL CHECK (NOT isempty(period)),
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT bookings_no_overlap EXCLUDE USING GIST (rental_id WITH =, period WITH &&)
+);
+
+CREATE INDEX IF NOT EXISTS bookings_user_id_idx ON bookings (user_id);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS tax_rates;
+DROP TABLE IF EXISTS seasonal_prices;
+
+ALTER TABLE rentals
+    DROP COLUMN IF EXISTS service_fee_percent,
+    DROP COLUMN IF EXISTS cleaning_fee,
+    DROP COLUMN IF EXISTS weekend_surcharge_percent,
+    DROP COLUMN IF EXISTS monthly_discount_percent,
+    DROP COLUMN IF EXISTS weekly_discount_percent;
@@ -0,0 +1,26 @@
+-- percentages are stored as 12.50 for 12.5%, fees in the same minor unit as price_per_day
+ALTER TABLE rentals
+    ADD COLUMN IF NOT EXISTS weekly_discount_percent numeric(5,2) NOT NULL DEFAULT 0
+        CHECK (weekly_discount_percent BETWEEN 0 AND 100),
+    ADD COLUMN IF NOT EXISTS monthly_discount_percent numeric(5,2) NOT NULL DEFAULT 0
+        CHECK (monthly_discount_percent BETWEEN 0 AND 100),
+    ADD COLUMN IF NOT EXISTS weekend_surcharge_percent numeric(5,2) NOT NULL DEFAULT 0
+        CHECK (weekend_surcharge_percent >= 0),
+    ADD COLUMN IF NOT EXISTS cleaning_fee bigint NOT NULL DEFAULT 0
+        CHECK (cleaning_fee >= 0),
+    ADD COLUMN IF NOT EXISTS service_fee_percent numeric(5,2) NOT NULL DEFAULT 0
+        CHECK (service_fee_percent BETWEEN 0 AND 100);
+
+-- a seasonal price replaces price_per_day for the nights of its half-open period
+CREATE TABLE IF NOT EXISTS seasonal_prices (
+    id SERIAL PRIMARY KEY,
+    rental_id integer NOT NULL REFERENCES rentals (id) ON DELETE CASCADE,
+    period daterange NOT NULL CHECK (NOT isempty(period)),
+    price_per_day bigint NOT NULL CHECK (price_per_day >= 0),
+    CONSTRAINT seasonal_prices_no_overlap EXCLUDE USING GIST (rental_id WITH =, period WITH &&)
+);
+
+CREATE TABLE IF NOT EXISTS tax_rates (
+    home_state text PRIMARY KEY,
+    rate_percent numeric(5,2) NOT NULL CHECK (rate_percent BETWEEN 0 AND 100)
+);
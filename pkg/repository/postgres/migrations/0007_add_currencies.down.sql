@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS convert_price(bigint, char(3), char(3));
+ALTER TABLE rentals DROP COLUMN IF EXISTS currency;
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,32 @@
+-- rate_per_usd is the number of major units of the currency one US dollar buys,
+-- minor_units is the number of decimal digits of the minor unit, e.g. 2 for cents and 0 for yen
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency char(3) PRIMARY KEY CHECK (currency ~ '^[A-Z]{3}$'),
+    minor_units smallint NOT NULL CHECK (minor_units BETWEEN 0 AND 4),
+    rate_per_usd numeric(20,10) NOT NULL CHECK (rate_per_usd > 0),
+    updated timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- indicative rates of the currencies listings exist in, refresh them with the rates command
+INSERT INTO exchange_rates (currency, minor_units, rate_per_usd)
+VALUES ('USD', 2, 1), ('CAD', 2, 1.36), ('EUR', 2, 0.92), ('GBP', 2, 0.79), ('AUD', 2, 1.52)
+ON CONFLICT (currency) DO NOTHING;
+
+-- price_per_day, cleaning_fee and seasonal prices are in the minor unit of the currency of the rental
+ALTER TABLE rentals
+    ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'USD'
+        REFERENCES exchange_rates (currency) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+UPDATE rentals SET currency = CASE home_country
+    WHEN 'CA' THEN 'CAD' WHEN 'IE' THEN 'EUR' WHEN 'GB' THEN 'GBP' WHEN 'AU' THEN 'AUD' ELSE 'USD'
+END;
+
+-- convert_price converts an amount between the minor units of two currencies, rounding halves away from zero
+CREATE OR REPLACE FUNCTION convert_price(amount bigint, source char(3), target char(3)) RETURNS bigint AS $$
+    SELECT CASE
+        WHEN source = target THEN amount
+        ELSE round(amount * (t.rate_per_usd * 10::numeric ^ t.minor_units) / (s.rate_per_usd * 10::numeric ^ s.minor_units))::bigint
+    END
+    FROM exchange_rates s, exchange_rates t
+    WHERE s.currency = source AND t.currency = target
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS reviews_sync_rental_rating ON reviews;
+DROP FUNCTION IF EXISTS reviews_sync_rental_rating();
+DROP TABLE IF EXISTS reviews;
+
+DROP INDEX IF EXISTS rentals_rating_avg_idx;
+ALTER TABLE rentals
+    DROP COLUMN IF EXISTS rating_count,
+    DROP COLUMN IF EXISTS rating_avg;
@@ -0,0 +1,45 @@
+-- a review is left by a user for a rental, optionally for one of their completed bookings of it
+CREATE TABLE IF NOT EXISTS reviews (
+    id SERIAL PRIMARY KEY,
+    rental_id integer NOT NULL REFERENCES rentals (id) ON DELETE CASCADE,
+    user_id integer NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    booking_id integer REFERENCES bookings (id) ON DELETE SET NULL,
+    rating smallint NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment text NOT NULL DEFAULT '',
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT reviews_one_per_user UNIQUE (rental_id, user_id),
+    CONSTRAINT reviews_one_per_booking UNIQUE (booking_id)
+);
+
+CREATE INDEX IF NOT EXISTS reviews_rental_id_created_idx ON reviews (rental_id, created DESC, id DESC);
+
+-- rating_avg and rating_count are kept next to the other rental columns, so they can be filtered and sorted on
+ALTER TABLE rentals
+    ADD COLUMN IF NOT EXISTS rating_avg numeric(3,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_count integer NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS rentals_rating_avg_idx ON rentals (rating_avg);
+
+-- recomputes the aggregates of the rental a review is written for or deleted from
+CREATE OR REPLACE FUNCTION reviews_sync_rental_rating() RETURNS trigger AS $$
+DECLARE
+    target integer;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target := OLD.rental_id;
+    ELSE
+        target := NEW.rental_id;
+    END IF;
+
+    UPDATE rentals SET
+        rating_avg = coalesce((SELECT round(avg(rating), 2) FROM reviews WHERE rental_id = target), 0),
+        rating_count = (SELECT COUNT(*) FROM reviews WHERE rental_id = target)
+    WHERE id = target;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS reviews_sync_rental_rating ON reviews;
+CREATE TRIGGER reviews_sync_rental_rating
+    AFTER INSERT OR UPDATE OF rating OR DELETE ON reviews
+    FOR EACH ROW EXECUTE PROCEDURE reviews_sync_rental_rating();
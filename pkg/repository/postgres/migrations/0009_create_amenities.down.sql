@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rental_amenities;
+DROP TABLE IF EXISTS amenities;
@@ -0,0 +1,16 @@
+-- amenities is the catalog of features a rental can offer, slug is how clients refer to them in filters
+CREATE TABLE IF NOT EXISTS amenities (
+    id SERIAL PRIMARY KEY,
+    slug text NOT NULL CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    name text NOT NULL CHECK (name <> ''),
+    CONSTRAINT amenities_slug_key UNIQUE (slug)
+);
+
+-- deleting an amenity from the catalog removes it from every rental
+CREATE TABLE IF NOT EXISTS rental_amenities (
+    rental_id integer NOT NULL REFERENCES rentals (id) ON DELETE CASCADE,
+    amenity_id integer NOT NULL REFERENCES amenities (id) ON DELETE CASCADE,
+    PRIMARY KEY (rental_id, amenity_id)
+);
+
+CREATE INDEX IF NOT EXISTS rental_amenities_amenity_id_idx ON rental_amenities (amenity_id);
@@ -0,0 +1,2 @@
+-- versions the owner columns joined into every rental, so a renamed owner changes the ETag of their rentals
+ALTER TABLE users ADD COLUMN IF NOT EXISTS updated timestamp with time zone NOT NULL DEFAULT now();
@@ -0,0 +1,9 @@
+-- an api key is stored as the hex sha256 of its secret, the secret itself is shown once when the key is created
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name text NOT NULL CHECK (name <> ''),
+    key_hash text NOT NULL UNIQUE,
+    user_id integer REFERENCES users (id) ON DELETE CASCADE,
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    revoked timestamp with time zone
+);
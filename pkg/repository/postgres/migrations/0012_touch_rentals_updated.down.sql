@@ -0,0 +1,22 @@
+DROP TRIGGER IF EXISTS amenities_touch_rentals ON amenities;
+DROP FUNCTION IF EXISTS amenities_touch_rentals();
+DROP TRIGGER IF EXISTS rental_amenities_touch_rental ON rental_amenities;
+DROP FUNCTION IF EXISTS rental_amenities_touch_rental();
+
+CREATE OR REPLACE FUNCTION reviews_sync_rental_rating() RETURNS trigger AS $$
+DECLARE
+    target integer;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target := OLD.rental_id;
+    ELSE
+        target := NEW.rental_id;
+    END IF;
+
+    UPDATE rentals SET
+        rating_avg = coalesce((SELECT round(avg(rating), 2) FROM reviews WHERE rental_id = target), 0),
+        rating_count = (SELECT COUNT(*) FROM reviews WHERE rental_id = target)
+    WHERE id = target;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,51 @@
+-- the rating and the amenities are part of a rental, rentals.updated changes with them so that
+-- Last-Modified of the rental does not outlive them
+CREATE OR REPLACE FUNCTION reviews_sync_rental_rating() RETURNS trigger AS $$
+DECLARE
+    target integer;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target := OLD.rental_id;
+    ELSE
+        target := NEW.rental_id;
+    END IF;
+
+    UPDATE rentals SET
+        rating_avg = coalesce((SELECT round(avg(rating), 2) FROM reviews WHERE rental_id = target), 0),
+        rating_count = (SELECT COUNT(*) FROM reviews WHERE rental_id = target),
+        updated = now()
+    WHERE id = target;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- fires for rental_amenities rows removed by the cascade of a deleted amenity as well
+CREATE OR REPLACE FUNCTION rental_amenities_touch_rental() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE rentals SET updated = now() WHERE id = OLD.rental_id;
+    ELSE
+        UPDATE rentals SET updated = now() WHERE id = NEW.rental_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rental_amenities_touch_rental ON rental_amenities;
+CREATE TRIGGER rental_amenities_touch_rental
+    AFTER INSERT OR DELETE ON rental_amenities
+    FOR EACH ROW EXECUTE PROCEDURE rental_amenities_touch_rental();
+
+-- rentals list the slugs of their amenities, renaming one changes every rental offering it
+CREATE OR REPLACE FUNCTION amenities_touch_rentals() RETURNS trigger AS $$
+BEGIN
+    UPDATE rentals SET updated = now()
+    WHERE id IN (SELECT rental_id FROM rental_amenities WHERE amenity_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS amenities_touch_rentals ON amenities;
+CREATE TRIGGER amenities_touch_rentals
+    AFTER UPDATE OF slug ON amenities
+    FOR EACH ROW WHEN (OLD.slug IS DISTINCT FROM NEW.slug) EXECUTE PROCEDURE amenities_touch_rentals();
@@ -0,0 +1,10 @@
+package pricing
+
+import "errors"
+
+var (
+	// ErrInvalidTrip is returned when query parameters cannot be parsed into a trip
+	ErrInvalidTrip = errors.New("invalid trip")
+	// ErrInvalidSeasons is returned when the seasonal prices of a rental are not valid
+	ErrInvalidSeasons = errors.New("invalid seasonal prices")
+)
@@ -0,0 +1,49 @@
+package pricing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+// ValidateSeasons checks every seasonal price and that no two of them overlap.
+// Problems are returned together as a *postgres.ValidationError, fields are named after their index, e.g. seasons[1].to.
+func ValidateSeasons(seasons []Season) error {
+	var errs []postgres.FieldError
+	fail := func(index int, field string, reason postgres.Reason, message string) {
+		errs = append(errs, postgres.FieldError{Field: fmt.Sprintf("seasons[%d]%s", index, field), Reason: reason, Message: message})
+	}
+
+	valid := make([]int, 0, len(seasons))
+	for i, season := range seasons {
+		switch {
+		case season.From.IsZero():
+			fail(i, ".from", postgres.ReasonInvalidValue, "is required")
+		case season.To.IsZero():
+			fail(i, ".to", postgres.ReasonInvalidValue, "is required")
+		case !season.To.After(season.From):
+			fail(i, ".to", postgres.ReasonOutOfRange, "must be after from")
+		default:
+			valid = append(valid, i)
+		}
+
+		if season.PricePerDay < 0 {
+			fail(i, ".price_per_day", postgres.ReasonOutOfRange, "must be greater than or equal to 0")
+		}
+	}
+
+	sort.Slice(valid, func(a, b int) bool { return seasons[valid[a]].From.Before(seasons[valid[b]].From) })
+	for i := 1; i < len(valid); i++ {
+		previous, current := seasons[valid[i-1]], seasons[valid[i]]
+		if current.From.Before(previous.To) {
+			fail(valid[i], "", postgres.ReasonInvalidValue, fmt.Sprintf("overlaps seasons[%d]", valid[i-1]))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &postgres.ValidationError{Errors: errs, Kind: ErrInvalidSeasons}
+	}
+
+	return nil
+}
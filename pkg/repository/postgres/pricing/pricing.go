@@ -0,0 +1,158 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository is a constructor function
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// RetrieveQuote prices a trip in a rental by a given id.
+// The rules and the seasonal prices are read in a single read-only transaction, so they observe the same snapshot.
+func (r *Repository) RetrieveQuote(ctx context.Context, rentalID int, trip Trip) (Quote, error) {
+	if rentalID <= 0 {
+		return Quote{}, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return Quote{}, postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	var (
+		rules    Rules
+		currency string
+	)
+	err = tx.QueryRowContext(ctx, selectRules, rentalID).Scan(&rules.PricePerDay, &rules.WeeklyDiscountPercent,
+		&rules.MonthlyDiscountPercent, &rules.WeekendSurchargePercent, &rules.CleaningFee, &rules.ServiceFeePercent,
+		&rules.TaxRatePercent, &currency)
+	if err != nil {
+		return Quote{}, postgres.Classify(fmt.Errorf("failed to scan pricing rules: %w", err))
+	}
+
+	rules.Seasons, err = querySeasons(ctx, tx, selectTripSeasons, rentalID, trip.Start, trip.End)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Quote{}, postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	quote := rules.Quote(trip)
+	quote.RentalID = rentalID
+	quote.Currency = currency
+
+	return quote, nil
+}
+
+// RetrieveSeasons retrieves the seasonal prices of a rental by a given id ordered by their start
+func (r *Repository) RetrieveSeasons(ctx context.Context, rentalID int) ([]Season, error) {
+	if rentalID <= 0 {
+		return nil, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, rentalExists, rentalID).Scan(&exists); err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to check rental existence: %w", err))
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%w: rental %d does not exist", postgres.ErrNotFound, rentalID)
+	}
+
+	seasons, err := querySeasons(ctx, tx, selectSeasons, rentalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return seasons, nil
+}
+
+// ReplaceSeasons validates and replaces every seasonal price of a rental by a given id
+func (r *Repository) ReplaceSeasons(ctx context.Context, rentalID int, seasons []Season) ([]Season, error) {
+	if rentalID <= 0 {
+		return nil, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	if err := ValidateSeasons(seasons); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.QueryRowContext(ctx, lockRental, rentalID).Scan(&id); err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to lock rental: %w", err))
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteSeasons, rentalID); err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to delete seasonal prices: %w", err))
+	}
+
+	sorted := append([]Season{}, seasons...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].From.Before(sorted[b].From) })
+	for _, season := range sorted {
+		if _, err := tx.ExecContext(ctx, insertSeason, rentalID, season.From, season.To, season.PricePerDay); err != nil {
+			return nil, postgres.Classify(fmt.Errorf("failed to insert seasonal price: %w", err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return sorted, nil
+}
+
+func querySeasons(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]Season, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to execute select seasonal prices query: %w", err))
+	}
+	defer rows.Close()
+
+	seasons := make([]Season, 0)
+	for rows.Next() {
+		var season Season
+		if err := rows.Scan(&season.From, &season.To, &season.PricePerDay); err != nil {
+			return nil, postgres.Classify(fmt.Errorf("failed to scan a row: %w", err))
+		}
+
+		seasons = append(seasons, season)
+	}
+
+	if rows.Err() != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed while iterating over rows: %w", rows.Err()))
+	}
+
+	return seasons, nil
+}
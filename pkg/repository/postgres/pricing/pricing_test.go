@@ -0,0 +1,147 @@
+package pricing_test
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/pricing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	expectedSelectRules = `SELECT r.price_per_day, r.weekly_discount_percent, r.monthly_discount_percent,
+							r.weekend_surcharge_percent, r.cleaning_fee, r.service_fee_percent, COALESCE(t.rate_percent, 0), r.currency
+							FROM rentals r
+							LEFT JOIN tax_rates t
+							ON r.home_state = t.home_state
+							WHERE r.id = $1`
+	expectedSelectTripSeasons = `SELECT lower(period), upper(period), price_per_day FROM seasonal_prices
+							WHERE rental_id = $1 AND period && daterange($2::date, $3::date) ORDER BY lower(period)`
+	expectedInsertSeason = `INSERT INTO seasonal_prices (rental_id, period, price_per_day) VALUES ($1, daterange($2::date, $3::date), $4)`
+)
+
+var _ = Describe("Pricing", func() {
+	var (
+		repository *pricing.Repository
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		repository = pricing.NewRepository(dbClient)
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("RetrieveQuote", func() {
+		trip := pricing.Trip{Start: date(3), End: date(10)}
+
+		When("the rental exists", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRules)).
+					WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"price_per_day", "weekly_discount_percent", "monthly_discount_percent",
+						"weekend_surcharge_percent", "cleaning_fee", "service_fee_percent", "rate_percent", "currency"}).
+						AddRow(10000, []byte("10.00"), []byte("25.00"), []byte("20.00"), 5000, []byte("10.00"), []byte("5.00"), "CAD"))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectTripSeasons)).
+					WithArgs(1, date(3), date(10)).
+					WillReturnRows(mock.NewRows([]string{"lower", "upper", "price_per_day"}).AddRow(date(8), date(10), 15000))
+				mock.ExpectCommit()
+			})
+
+			It("should price the trip with the rules of the rental", func() {
+				quote, err := repository.RetrieveQuote(ctx, 1, trip)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(quote.RentalID).To(Equal(1))
+				Expect(quote.Currency).To(Equal("CAD"))
+				Expect(quote.Total).To(Equal(93608))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRules)).
+					WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"price_per_day"}))
+				mock.ExpectRollback()
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.RetrieveQuote(ctx, 1, trip)
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+	})
+
+	Context("ReplaceSeasons", func() {
+		When("the seasons are valid", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM rentals WHERE id = $1 FOR UPDATE`)).
+					WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM seasonal_prices WHERE rental_id = $1`)).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(regexp.QuoteMeta(expectedInsertSeason)).
+					WithArgs(1, date(1), date(5), 12000).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(expectedInsertSeason)).
+					WithArgs(1, date(20), date(25), 15000).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("should replace them ordered by their start", func() {
+				seasons, err := repository.ReplaceSeasons(ctx, 1, []pricing.Season{
+					{From: date(20), To: date(25), PricePerDay: 15000},
+					{From: date(1), To: date(5), PricePerDay: 12000},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(seasons[0].From).To(Equal(date(1)))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM rentals WHERE id = $1 FOR UPDATE`)).
+					WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.ReplaceSeasons(ctx, 1, nil)
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+	})
+
+	Context("RetrieveSeasons", func() {
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`)).
+				WithArgs(1).
+				WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT lower(period), upper(period), price_per_day FROM seasonal_prices
+							WHERE rental_id = $1 ORDER BY lower(period)`)).
+				WithArgs(1).
+				WillReturnRows(mock.NewRows([]string{"lower", "upper", "price_per_day"}).AddRow(date(1), date(5), 12000))
+			mock.ExpectCommit()
+		})
+
+		It("should return the seasonal prices of the rental", func() {
+			seasons, err := repository.RetrieveSeasons(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(seasons).To(Equal([]pricing.Season{{From: date(1), To: date(5), PricePerDay: 12000}}))
+		})
+	})
+})
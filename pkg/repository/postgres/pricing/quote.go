@@ -0,0 +1,169 @@
+package pricing
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+const (
+	// DateLayout is the format of the dates accepted and returned by the pricing API
+	DateLayout = "2006-01-02"
+	// MaxNights is the longest trip a quote can be requested for
+	MaxNights = 365
+)
+
+// Line item kinds of a quote, in the order they appear
+const (
+	ItemNights           = "nights"
+	ItemWeekendSurcharge = "weekend_surcharge"
+	ItemWeeklyDiscount   = "weekly_discount"
+	ItemMonthlyDiscount  = "monthly_discount"
+	ItemCleaningFee      = "cleaning_fee"
+	ItemServiceFee       = "service_fee"
+	ItemTax              = "tax"
+)
+
+// Trip is a half-open range of nights [Start, End)
+type Trip struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Nights returns the number of nights of the trip
+func (t Trip) Nights() int {
+	return int(t.End.Sub(t.Start).Hours() / 24)
+}
+
+// Quote is the itemized price of a trip, Total is the sum of the amounts of Items.
+// Amounts are in the minor unit of Currency, the currency of the rental.
+type Quote struct {
+	RentalID int
+	Currency string
+	Trip     Trip
+	Nights   []Night
+	Items    []LineItem
+	Total    int
+}
+
+// Night is the price of a single night including its weekend surcharge
+type Night struct {
+	Date     time.Time
+	Price    int
+	Seasonal bool
+	Weekend  bool
+}
+
+// LineItem is a single row of a quote, discounts have negative amounts
+type LineItem struct {
+	Kind        string
+	Description string
+	Amount      int
+}
+
+// Quote prices every night of the trip and applies discounts, fees and taxes in that order.
+// The service fee is charged on the discounted nights and the tax on everything before it.
+func (r Rules) Quote(trip Trip) Quote {
+	quote := Quote{Trip: trip, Nights: make([]Night, 0, trip.Nights()), Items: make([]LineItem, 0)}
+	add := func(kind, description string, amount int) {
+		if amount != 0 {
+			quote.Items = append(quote.Items, LineItem{Kind: kind, Description: description, Amount: amount})
+			quote.Total += amount
+		}
+	}
+
+	nights, surcharge, weekendNights := 0, 0, 0
+	for night := trip.Start; night.Before(trip.End); night = night.AddDate(0, 0, 1) {
+		price, seasonal := r.priceOf(night)
+		nights += price
+		weekend := isWeekend(night)
+		if weekend {
+			weekendNights++
+			surcharge += percentOf(price, r.WeekendSurchargePercent)
+			price += percentOf(price, r.WeekendSurchargePercent)
+		}
+
+		quote.Nights = append(quote.Nights, Night{Date: night, Price: price, Seasonal: seasonal, Weekend: weekend})
+	}
+
+	add(ItemNights, fmt.Sprintf("%d nights", trip.Nights()), nights)
+	add(ItemWeekendSurcharge, fmt.Sprintf("%g%% on %d weekend nights", r.WeekendSurchargePercent, weekendNights), surcharge)
+
+	discount := percentOf(nights+surcharge, r.discountPercent(trip.Nights()))
+	if trip.Nights() >= MonthNights {
+		add(ItemMonthlyDiscount, fmt.Sprintf("%g%% off stays of %d nights or more", r.MonthlyDiscountPercent, MonthNights), -discount)
+	} else {
+		add(ItemWeeklyDiscount, fmt.Sprintf("%g%% off stays of %d nights or more", r.WeeklyDiscountPercent, WeekNights), -discount)
+	}
+
+	add(ItemCleaningFee, "cleaning fee", r.CleaningFee)
+	serviceFee := percentOf(nights+surcharge-discount, r.ServiceFeePercent)
+	add(ItemServiceFee, fmt.Sprintf("%g%% service fee", r.ServiceFeePercent), serviceFee)
+	add(ItemTax, fmt.Sprintf("%g%% tax", r.TaxRatePercent), percentOf(quote.Total, r.TaxRatePercent))
+
+	return quote
+}
+
+// ParseTrip parses and validates the start and end query parameters of a quote request.
+// All problems are collected and returned together as a *postgres.ValidationError.
+func ParseTrip(query map[string][]string) (Trip, error) {
+	var errs []postgres.FieldError
+	fail := func(field string, reason postgres.Reason, message string) {
+		errs = append(errs, postgres.FieldError{Field: field, Reason: reason, Message: message})
+	}
+
+	date := func(key string) time.Time {
+		values, ok := query[key]
+		if !ok || len(values) == 0 {
+			fail(key, postgres.ReasonInvalidValue, "is required")
+			return time.Time{}
+		}
+
+		if len(values) > 1 {
+			fail(key, postgres.ReasonInvalidValue, "must be given once")
+			return time.Time{}
+		}
+
+		value, err := ParseDate(values[0])
+		if err != nil {
+			fail(key, postgres.ReasonInvalidValue, "must be a date in YYYY-MM-DD format")
+			return time.Time{}
+		}
+
+		return value
+	}
+
+	trip := Trip{Start: date("start"), End: date("end")}
+	if !trip.Start.IsZero() && !trip.End.IsZero() {
+		if !trip.End.After(trip.Start) {
+			fail("end", postgres.ReasonOutOfRange, "must be after start")
+		} else if trip.Nights() > MaxNights {
+			fail("end", postgres.ReasonOutOfRange, fmt.Sprintf("must be at most %d nights after start", MaxNights))
+		}
+	}
+
+	unknown := make([]string, 0)
+	for key := range query {
+		if key != "start" && key != "end" {
+			unknown = append(unknown, key)
+		}
+	}
+
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		fail(key, postgres.ReasonUnknownParameter, "is not a supported query parameter")
+	}
+
+	if len(errs) > 0 {
+		return Trip{}, &postgres.ValidationError{Errors: errs, Kind: ErrInvalidTrip}
+	}
+
+	return trip, nil
+}
+
+// ParseDate parses a calendar date in DateLayout as midnight UTC
+func ParseDate(value string) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, value, time.UTC)
+}
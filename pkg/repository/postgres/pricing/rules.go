@@ -0,0 +1,84 @@
+package pricing
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// WeekNights is the shortest stay the weekly discount applies to
+	WeekNights = 7
+	// MonthNights is the shortest stay the monthly discount applies to, four weeks so that
+	// every month has the same number of weekend nights
+	MonthNights = 28
+)
+
+// Rules holds everything a quote is computed from. Percentages are stored as 12.5 for 12.5%,
+// prices and fees are in the minor unit of the currency, e.g. cents.
+type Rules struct {
+	PricePerDay             int
+	WeeklyDiscountPercent   float64
+	MonthlyDiscountPercent  float64
+	WeekendSurchargePercent float64
+	CleaningFee             int
+	ServiceFeePercent       float64
+	TaxRatePercent          float64
+	Seasons                 []Season
+}
+
+// Season replaces the price per day for the nights of the half-open range [From, To)
+type Season struct {
+	From        time.Time
+	To          time.Time
+	PricePerDay int
+}
+
+// Week is the price of seven nights at the regular price, with the weekend surcharge and the weekly discount applied
+func (r Rules) Week() int {
+	return r.stay(WeekNights)
+}
+
+// Month is the price of MonthNights nights at the regular price, with the weekend surcharge and the monthly discount applied
+func (r Rules) Month() int {
+	return r.stay(MonthNights)
+}
+
+// stay prices a number of whole weeks of nights, which always contain two weekend nights per week
+func (r Rules) stay(nights int) int {
+	weekendNights := nights / WeekNights * 2
+	price := nights*r.PricePerDay + weekendNights*percentOf(r.PricePerDay, r.WeekendSurchargePercent)
+	return price - percentOf(price, r.discountPercent(nights))
+}
+
+// discountPercent picks the largest discount the length of the stay qualifies for
+func (r Rules) discountPercent(nights int) float64 {
+	switch {
+	case nights >= MonthNights:
+		return r.MonthlyDiscountPercent
+	case nights >= WeekNights:
+		return r.WeeklyDiscountPercent
+	default:
+		return 0
+	}
+}
+
+// priceOf returns the price of a night before the weekend surcharge and whether a season set it
+func (r Rules) priceOf(night time.Time) (int, bool) {
+	for _, season := range r.Seasons {
+		if !night.Before(season.From) && night.Before(season.To) {
+			return season.PricePerDay, true
+		}
+	}
+
+	return r.PricePerDay, false
+}
+
+// isWeekend reports whether a night falls on Friday or Saturday
+func isWeekend(night time.Time) bool {
+	return night.Weekday() == time.Friday || night.Weekday() == time.Saturday
+}
+
+// percentOf rounds to the nearest minor unit, halves away from zero
+func percentOf(amount int, percent float64) int {
+	return int(math.Round(float64(amount) * percent / 100))
+}
@@ -0,0 +1,111 @@
+package pricing_test
+
+import (
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/pricing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// June 1, 2024 is a Saturday
+func date(day int) time.Time {
+	return time.Date(2024, 6, day, 0, 0, 0, 0, time.UTC)
+}
+
+var _ = Describe("Rules", func() {
+	rules := pricing.Rules{
+		PricePerDay:             10000,
+		WeeklyDiscountPercent:   10,
+		MonthlyDiscountPercent:  25,
+		WeekendSurchargePercent: 20,
+		CleaningFee:             5000,
+		ServiceFeePercent:       10,
+		TaxRatePercent:          5,
+		Seasons:                 []pricing.Season{{From: date(8), To: date(10), PricePerDay: 15000}},
+	}
+
+	It("should derive the weekly and the monthly price from the regular price", func() {
+		Expect(rules.Week()).To(Equal(66600))
+		Expect(rules.Month()).To(Equal(222000))
+	})
+
+	When("quoting a week with weekend and seasonal nights", func() {
+		It("should itemize every rule", func() {
+			quote := rules.Quote(pricing.Trip{Start: date(3), End: date(10)})
+			Expect(quote.Nights).To(HaveLen(7))
+			Expect(quote.Nights[4]).To(Equal(pricing.Night{Date: date(7), Price: 12000, Weekend: true}))
+			Expect(quote.Nights[5]).To(Equal(pricing.Night{Date: date(8), Price: 18000, Seasonal: true, Weekend: true}))
+			Expect(quote.Nights[6]).To(Equal(pricing.Night{Date: date(9), Price: 15000, Seasonal: true}))
+
+			amounts := make(map[string]int)
+			for _, item := range quote.Items {
+				amounts[item.Kind] = item.Amount
+			}
+			Expect(amounts).To(Equal(map[string]int{
+				pricing.ItemNights:           80000,
+				pricing.ItemWeekendSurcharge: 5000,
+				pricing.ItemWeeklyDiscount:   -8500,
+				pricing.ItemCleaningFee:      5000,
+				pricing.ItemServiceFee:       7650,
+				pricing.ItemTax:              4458,
+			}))
+			Expect(quote.Total).To(Equal(93608))
+		})
+	})
+
+	When("quoting a month", func() {
+		It("should apply the monthly discount instead of the weekly one", func() {
+			quote := pricing.Rules{PricePerDay: 10000, WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 25}.
+				Quote(pricing.Trip{Start: date(1), End: date(29)})
+			Expect(quote.Items).To(Equal([]pricing.LineItem{
+				{Kind: pricing.ItemNights, Description: "28 nights", Amount: 280000},
+				{Kind: pricing.ItemMonthlyDiscount, Description: "25% off stays of 28 nights or more", Amount: -70000},
+			}))
+			Expect(quote.Total).To(Equal(210000))
+		})
+	})
+
+	When("quoting a short trip without rules", func() {
+		It("should only charge the nights", func() {
+			quote := pricing.Rules{PricePerDay: 8900}.Quote(pricing.Trip{Start: date(3), End: date(5)})
+			Expect(quote.Items).To(Equal([]pricing.LineItem{{Kind: pricing.ItemNights, Description: "2 nights", Amount: 17800}}))
+			Expect(quote.Total).To(Equal(17800))
+		})
+	})
+
+	DescribeTable("malformed trips",
+		func(query map[string][]string) {
+			_, err := pricing.ParseTrip(query)
+			Expect(err).To(MatchError(pricing.ErrInvalidTrip))
+		},
+		Entry("missing start", map[string][]string{"end": {"2024-06-10"}}),
+		Entry("end that is not a date", map[string][]string{"start": {"2024-06-01"}, "end": {"tomorrow"}}),
+		Entry("end equal to start", map[string][]string{"start": {"2024-06-01"}, "end": {"2024-06-01"}}),
+		Entry("trip longer than the maximum", map[string][]string{"start": {"2024-01-01"}, "end": {"2025-06-01"}}),
+		Entry("unknown parameter", map[string][]string{"start": {"2024-06-01"}, "end": {"2024-06-10"}, "guests": {"2"}}),
+		Entry("repeated end", map[string][]string{"start": {"2024-06-01"}, "end": {"2024-06-10", "2024-06-03"}}),
+	)
+
+	When("start is repeated", func() {
+		It("should reject it instead of quoting one of the dates", func() {
+			_, err := pricing.ParseTrip(map[string][]string{"start": {"2024-06-01", "2024-06-05"}, "end": {"2024-06-10"}})
+			Expect(err.(*postgres.ValidationError).Errors).To(Equal([]postgres.FieldError{
+				{Field: "start", Reason: postgres.ReasonInvalidValue, Message: "must be given once"},
+			}))
+		})
+	})
+
+	DescribeTable("invalid seasonal prices",
+		func(seasons []pricing.Season, field string) {
+			err := pricing.ValidateSeasons(seasons)
+			Expect(err).To(MatchError(pricing.ErrInvalidSeasons))
+			Expect(err.(*postgres.ValidationError).Errors[0].Field).To(Equal(field))
+		},
+		Entry("missing from", []pricing.Season{{To: date(5)}}, "seasons[0].from"),
+		Entry("to before from", []pricing.Season{{From: date(5), To: date(1)}}, "seasons[0].to"),
+		Entry("negative price", []pricing.Season{{From: date(1), To: date(5), PricePerDay: -1}}, "seasons[0].price_per_day"),
+		Entry("overlapping seasons", []pricing.Season{{From: date(10), To: date(20)}, {From: date(1), To: date(11)}}, "seasons[0]"),
+	)
+})
@@ -0,0 +1,24 @@
+package pricing
+
+// selectRules reads the pricing rules and the currency of a rental, a home state without a tax rate is not taxed
+const selectRules = `SELECT r.price_per_day, r.weekly_discount_percent, r.monthly_discount_percent,
+							r.weekend_surcharge_percent, r.cleaning_fee, r.service_fee_percent, COALESCE(t.rate_percent, 0), r.currency
+							FROM rentals r
+							LEFT JOIN tax_rates t
+							ON r.home_state = t.home_state
+							WHERE r.id = $1`
+
+const selectSeasons = `SELECT lower(period), upper(period), price_per_day FROM seasonal_prices
+							WHERE rental_id = $1 ORDER BY lower(period)`
+
+const selectTripSeasons = `SELECT lower(period), upper(period), price_per_day FROM seasonal_prices
+							WHERE rental_id = $1 AND period && daterange($2::date, $3::date) ORDER BY lower(period)`
+
+const rentalExists = `SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`
+
+// lockRental serializes concurrent replacements of the seasonal prices of a rental
+const lockRental = `SELECT id FROM rentals WHERE id = $1 FOR UPDATE`
+
+const deleteSeasons = `DELETE FROM seasonal_prices WHERE rental_id = $1`
+
+const insertSeason = `INSERT INTO seasonal_prices (rental_id, period, price_per_day) VALUES ($1, daterange($2::date, $3::date), $4)`
@@ -0,0 +1,135 @@
+package rentals_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+
+	"github.com/lib/pq"
+	"github.com/nvasilev98/rentals/pkg/repository/conformance"
+	"github.com/nvasilev98/rentals/pkg/repository/memory"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+// conformanceEnv runs the conformance specs against the database configured by the DB_* variables,
+// they are opt-in because every spec truncates the users, rentals, amenities and exchange_rates tables
+const conformanceEnv = "CONFORMANCE_POSTGRES"
+
+var _ = conformance.DescribeRentalRepository("postgres", func(seed memory.Seed) (conformance.RentalRepository, error) {
+	if os.Getenv(conformanceEnv) != "true" {
+		Skip("set " + conformanceEnv + "=true and the DB_* variables to run against a disposable database")
+	}
+
+	dbConfig, err := postgres.LoadDBConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := postgres.Connect(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateAndSeed(context.Background(), db, seed); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	repository, err := rentals.NewRepository(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &conformanceRepository{Repository: repository, db: db}, nil
+})
+
+// conformanceRepository closes the connection of the spec together with the repository
+type conformanceRepository struct {
+	*rentals.Repository
+	db *sql.DB
+}
+
+func (r *conformanceRepository) Close() error {
+	defer r.db.Close()
+	return r.Repository.Close()
+}
+
+// migrateAndSeed replaces the content of the tables read by the rentals repository with the seed
+func migrateAndSeed(ctx context.Context, db *sql.DB, seed memory.Seed) error {
+	migrator, err := postgres.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "TRUNCATE users, rentals, amenities, exchange_rates RESTART IDENTITY CASCADE"); err != nil {
+		return err
+	}
+
+	for _, rate := range seed.ExchangeRates {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO exchange_rates (currency, minor_units, rate_per_usd) VALUES ($1, $2, $3)",
+			rate.Currency, rate.MinorUnits, rate.RatePerUSD); err != nil {
+			return err
+		}
+	}
+
+	for _, user := range seed.Users {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO users (id, first_name, last_name, updated) VALUES ($1, $2, $3, $4)",
+			user.ID, user.FirstName, user.LastName, user.Updated); err != nil {
+			return err
+		}
+	}
+
+	for _, rental := range seed.Rentals {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO rentals (id, user_id, name, type, description, sleeps, price_per_day,
+			weekly_discount_percent, monthly_discount_percent, weekend_surcharge_percent, cleaning_fee, service_fee_percent,
+			currency, home_city, home_state, home_zip, home_country, vehicle_make, vehicle_model, vehicle_year, vehicle_length,
+			lat, lng, primary_image_url, rating_avg, rating_count, created, updated)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23,
+			$24, $25, $26, $27, $28)`,
+			rental.ID, rental.UserID, rental.Name, rental.Type, rental.Description, rental.Sleeps, rental.PricePerDay,
+			rental.WeeklyDiscountPercent, rental.MonthlyDiscountPercent, rental.WeekendSurchargePercent, rental.CleaningFee,
+			rental.ServiceFeePercent, rental.Currency, rental.HomeCity, rental.HomeState, rental.HomeZIP, rental.HomeCountry,
+			rental.VehicleMake, rental.VehicleModel, rental.VehicleYear, rental.VehicleLength, rental.LAT, rental.LNG,
+			rental.PrimaryImageURL, rental.RatingAvg, rental.RatingCount, rental.Created, rental.Updated); err != nil {
+			return err
+		}
+
+		for _, slug := range rental.Amenities {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO amenities (slug, name) VALUES ($1, $1) ON CONFLICT (slug) DO NOTHING",
+				slug); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO rental_amenities (rental_id, amenity_id)
+			SELECT $1, id FROM amenities WHERE slug = ANY($2)`, rental.ID, pq.Array(rental.Amenities)); err != nil {
+			return err
+		}
+
+		// rental_amenities_touch_rental bumped updated, the seed is what the rental was last updated at
+		if _, err := tx.ExecContext(ctx, "UPDATE rentals SET updated = $2 WHERE id = $1", rental.ID, rental.Updated); err != nil {
+			return err
+		}
+	}
+
+	// ids are seeded explicitly, move the sequences past them so created rentals get fresh ids
+	if _, err := tx.ExecContext(ctx, `SELECT setval('users_id_seq', COALESCE(MAX(id), 0) + 1, false) FROM users;
+		SELECT setval('rentals_id_seq', COALESCE(MAX(id), 0) + 1, false) FROM rentals`); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
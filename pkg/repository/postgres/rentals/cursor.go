@@ -0,0 +1,99 @@
+package rentals
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+// Cursor marks the position right after the last rental of a page. It stores the values
+// of the active sort keys and the rental id, so the next page can be found with a keyset
+// condition instead of an OFFSET.
+type Cursor struct {
+	Sort     string        `json:"sort"`
+	Values   []interface{} `json:"values"`
+	ID       int           `json:"id"`
+	Currency string        `json:"currency,omitempty"`
+}
+
+// Encode returns the opaque representation of the cursor handed to clients
+func (c Cursor) Encode() string {
+	encoded, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeCursor(value string) (Cursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil {
+		return Cursor{}, fmt.Errorf("failed to unmarshal cursor: %w", err)
+	}
+
+	if cursor.ID <= 0 {
+		return Cursor{}, fmt.Errorf("cursor id must be a positive integer")
+	}
+
+	return cursor, nil
+}
+
+// NewCursor builds the cursor pointing after a given rental for the sort of the filter
+func NewCursor(filter Filter, rental Model) Cursor {
+	values := make([]interface{}, 0, len(filter.Sort))
+	for _, key := range filter.Sort {
+		values = append(values, sortValue(key.Field, rental))
+	}
+
+	return Cursor{Sort: sortSpec(filter.Sort), Values: values, ID: rental.ID, Currency: filter.Currency}
+}
+
+func sortValue(field string, rental Model) interface{} {
+	switch field {
+	case "price":
+		return rental.PricePerDay
+	case "year":
+		return rental.VehicleYear
+	case "sleeps":
+		return rental.Sleeps
+	case "length":
+		return rental.VehicleLength
+	case "created":
+		return rental.Created
+	case "updated":
+		return rental.Updated
+	case "name":
+		return rental.Name
+	case "rating":
+		return rental.RatingAvg
+	case "distance":
+		if rental.Distance != nil {
+			return *rental.Distance
+		}
+	case "relevance":
+		if rental.Relevance != nil {
+			return *rental.Relevance
+		}
+	}
+
+	return nil
+}
+
+func (p *filterParser) cursor(key string) *Cursor {
+	value, ok := p.value(key)
+	if !ok {
+		return nil
+	}
+
+	cursor, err := decodeCursor(value)
+	if err != nil {
+		p.fail(key, postgres.ReasonInvalidValue, "is not a valid cursor")
+		return nil
+	}
+
+	return &cursor
+}
@@ -0,0 +1,125 @@
+package rentals
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Facets accepted by the facets query parameter, type, make and state count rentals per value
+// while price and sleeps count them per histogram bucket
+const (
+	FacetType   = "type"
+	FacetMake   = "make"
+	FacetState  = "state"
+	FacetPrice  = "price"
+	FacetSleeps = "sleeps"
+)
+
+// Lower bounds of the histogram buckets after the first one, a bucket holds values from its lower bound up to
+// the lower bound of the next one. Prices are per day in the smallest unit of the currency, e.g. cents.
+var (
+	PriceBucketBounds  = []int{5000, 10000, 15000, 20000, 30000}
+	SleepsBucketBounds = []int{2, 4, 6}
+)
+
+// FacetBucket counts the rentals sharing the Value of a term facet, or falling within [Min, Max) of a histogram facet.
+// Min is nil for the first bucket of a histogram and Max for the last one.
+type FacetBucket struct {
+	Value string
+	Min   *int
+	Max   *int
+	Count int
+}
+
+type facet struct {
+	// column is the grouped expression of a term facet or the bucketed one of a histogram facet
+	column func(b *queryBuilder, filter Filter) string
+	// bounds are set for histogram facets only
+	bounds []int
+	// without drops the own filter of the facet, so its buckets count what selecting them would return
+	without func(filter Filter) Filter
+}
+
+var facets = map[string]facet{
+	FacetType: {
+		column:  fixedColumn("r.type"),
+		without: func(filter Filter) Filter { filter.Types = nil; return filter },
+	},
+	FacetMake: {
+		column:  fixedColumn("r.vehicle_make"),
+		without: func(filter Filter) Filter { filter.Makes = nil; return filter },
+	},
+	FacetState: {
+		column:  fixedColumn("r.home_state"),
+		without: func(filter Filter) Filter { filter.States = nil; return filter },
+	},
+	FacetPrice: {
+		column: func(b *queryBuilder, filter Filter) string { return b.priceColumn(filter) },
+		bounds: PriceBucketBounds,
+		without: func(filter Filter) Filter {
+			filter.PriceMin, filter.PriceMax = nil, nil
+			return filter
+		},
+	},
+	FacetSleeps: {
+		column:  fixedColumn("r.sleeps"),
+		bounds:  SleepsBucketBounds,
+		without: func(filter Filter) Filter { filter.SleepsMin = nil; return filter },
+	},
+}
+
+// facetNames lists the facets in the order they are documented
+var facetNames = []string{FacetType, FacetMake, FacetState, FacetPrice, FacetSleeps}
+
+func fixedColumn(column string) func(*queryBuilder, Filter) string {
+	return func(*queryBuilder, Filter) string { return column }
+}
+
+// buildFacetQuery counts the rentals matching the filter without the own filter of the facet, grouped by value or
+// by histogram bucket. Buckets are numbered by width_bucket, 0 below the first bound and len(bounds) above the last.
+func buildFacetQuery(name string, filter Filter) (string, []interface{}) {
+	facet := facets[name]
+	filter = facet.without(filter)
+
+	builder := &queryBuilder{}
+	column := facet.column(builder, filter)
+	if facet.bounds != nil {
+		bounds := make([]string, 0, len(facet.bounds))
+		for _, bound := range facet.bounds {
+			bounds = append(bounds, fmt.Sprint(bound))
+		}
+
+		column = fmt.Sprintf("width_bucket(%s, ARRAY[%s])", column, strings.Join(bounds, ", "))
+	}
+
+	builder.query = fmt.Sprintf("SELECT %s AS value, COUNT(*) %s", column, rentalsFrom)
+	builder.addWhereClause(filter, "")
+	if facet.bounds != nil {
+		builder.query += " GROUP BY value ORDER BY value"
+	} else {
+		builder.query += " GROUP BY value ORDER BY COUNT(*) DESC, value"
+	}
+
+	return builder.query, builder.args
+}
+
+// Histogram lists every bucket of a histogram facet in order, counts holds the non-empty ones by bucket number
+func Histogram(bounds []int, counts map[int]int) []FacetBucket {
+	buckets := make([]FacetBucket, 0, len(bounds)+1)
+	for i := 0; i <= len(bounds); i++ {
+		bucket := FacetBucket{Count: counts[i]}
+		if i > 0 {
+			min := bounds[i-1]
+			bucket.Min = &min
+		}
+
+		if i < len(bounds) {
+			max := bounds[i]
+			bucket.Max = &max
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets
+}
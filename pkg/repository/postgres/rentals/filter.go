@@ -0,0 +1,490 @@
+package rentals
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/amenities"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/currencies"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/reviews"
+)
+
+// ErrInvalidFilter is returned when query parameters cannot be parsed into a filter
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// ErrInvalidRental is returned when the fields of a written rental are not valid
+var ErrInvalidRental = errors.New("invalid rental")
+
+const (
+	// DefaultLimit is the page size used when limit is not provided
+	DefaultLimit = 20
+	// MaxLimit is the largest page size a client may request
+	MaxLimit = 100
+	// dateLayout is the format of available_from and available_to
+	dateLayout = "2006-01-02"
+)
+
+// Values of amenities_match, an empty AmenitiesMatch means all
+const (
+	AmenitiesMatchAll = "all"
+	AmenitiesMatchAny = "any"
+)
+
+type Filter struct {
+	PriceMin  *int
+	PriceMax  *int
+	IDs       []int
+	Types     []string
+	SleepsMin *int
+	YearMin   *int
+	YearMax   *int
+	LengthMin *float64
+	LengthMax *float64
+	Makes     []string
+	Models    []string
+	Countries []string
+	States    []string
+	Cities    []string
+	ZIPs      []string
+	UserID    *int
+	Query     string
+	Near      *Coordinates
+	Radius    Distance
+	BBox      *BoundingBox
+	Within    *Polygon
+	// AvailableFrom and AvailableTo form a half-open range of nights [from, to) that must not be booked
+	AvailableFrom *time.Time
+	AvailableTo   *time.Time
+	// Currency converts prices and the price_min and price_max bounds, they compare prices in the currency
+	// of every rental when it is empty
+	Currency string
+	// RatingMin keeps rentals whose mean rating is at least the given value, unrated rentals have a mean of 0
+	RatingMin *float64
+	// Amenities keeps rentals offering every listed amenity slug, or any of them when AmenitiesMatch is AmenitiesMatchAny
+	Amenities      []string
+	AmenitiesMatch string
+	// Facets are the names of the facets counted together with the page, see FacetType and the other facets
+	Facets []string
+	Sort   []SortKey
+	Cursor *Cursor
+	Offset int
+	Limit  int
+}
+
+// ParseFilter parses and validates raw query parameters into a Filter.
+// All problems are collected and returned together as a *postgres.ValidationError.
+func ParseFilter(query map[string][]string) (Filter, error) {
+	parser := &filterParser{query: query, known: make(map[string]bool)}
+	filter := Filter{
+		PriceMin:       parser.nonNegativeInt("price_min"),
+		PriceMax:       parser.nonNegativeInt("price_max"),
+		IDs:            parser.ids("ids"),
+		Types:          parser.texts("type"),
+		SleepsMin:      parser.nonNegativeInt("sleeps_min"),
+		YearMin:        parser.nonNegativeInt("year_min"),
+		YearMax:        parser.nonNegativeInt("year_max"),
+		LengthMin:      parser.nonNegativeFloat("length_min"),
+		LengthMax:      parser.nonNegativeFloat("length_max"),
+		Makes:          parser.texts("make"),
+		Models:         parser.texts("model"),
+		Countries:      parser.texts("country"),
+		States:         parser.texts("state"),
+		Cities:         parser.texts("city"),
+		ZIPs:           parser.texts("zip"),
+		UserID:         parser.integer("user_id", 1),
+		Query:          parser.text("q"),
+		Near:           parser.coordinates("near"),
+		Radius:         parser.distance("radius"),
+		BBox:           parser.boundingBox("bbox"),
+		Within:         parser.polygon("within"),
+		AvailableFrom:  parser.date("available_from"),
+		AvailableTo:    parser.date("available_to"),
+		Currency:       parser.currency("currency"),
+		RatingMin:      parser.rating("rating_min"),
+		Amenities:      parser.amenities("amenities"),
+		AmenitiesMatch: parser.amenitiesMatch("amenities_match"),
+		Facets:         parser.facets("facets"),
+		Sort:           parser.sort("sort"),
+		Cursor:         parser.cursor("cursor"),
+		Offset:         parser.offset("offset"),
+		Limit:          parser.limit("limit"),
+	}
+
+	if filter.PriceMin != nil && filter.PriceMax != nil && *filter.PriceMin > *filter.PriceMax {
+		parser.fail("price_min", postgres.ReasonOutOfRange, "must not be greater than price_max")
+	}
+
+	if filter.YearMin != nil && filter.YearMax != nil && *filter.YearMin > *filter.YearMax {
+		parser.fail("year_min", postgres.ReasonOutOfRange, "must not be greater than year_max")
+	}
+
+	if filter.LengthMin != nil && filter.LengthMax != nil && *filter.LengthMin > *filter.LengthMax {
+		parser.fail("length_min", postgres.ReasonOutOfRange, "must not be greater than length_max")
+	}
+
+	if filter.Near == nil && filter.Radius != (Distance{}) {
+		parser.fail("radius", postgres.ReasonInvalidValue, "requires near")
+	}
+
+	if filter.Near != nil && filter.Radius == (Distance{}) {
+		filter.Radius = DefaultRadius
+	}
+
+	if (filter.AvailableFrom == nil) != (filter.AvailableTo == nil) {
+		parser.fail("available_from", postgres.ReasonInvalidValue, "must be combined with available_to")
+	}
+
+	if filter.AvailableFrom != nil && filter.AvailableTo != nil && !filter.AvailableTo.After(*filter.AvailableFrom) {
+		parser.fail("available_to", postgres.ReasonOutOfRange, "must be after available_from")
+	}
+
+	if len(query["amenities"]) == 0 && filter.AmenitiesMatch != "" {
+		parser.fail("amenities_match", postgres.ReasonInvalidValue, "requires amenities")
+	}
+
+	if filter.Near == nil && filter.sortsBy("distance") {
+		parser.fail("sort", postgres.ReasonInvalidValue, "sorting by distance requires near")
+	}
+
+	if filter.Query == "" && filter.sortsBy("relevance") {
+		parser.fail("sort", postgres.ReasonInvalidValue, "sorting by relevance requires q")
+	}
+
+	if filter.Query != "" && len(filter.Sort) == 0 {
+		filter.Sort = []SortKey{{Field: "relevance", Descending: true}}
+	}
+
+	if filter.Cursor != nil {
+		if filter.Cursor.Sort != sortSpec(filter.Sort) || len(filter.Cursor.Values) != len(filter.Sort) {
+			parser.fail("cursor", postgres.ReasonInvalidValue, "does not match the requested sort")
+		}
+
+		if filter.Cursor.Currency != filter.Currency {
+			parser.fail("cursor", postgres.ReasonInvalidValue, "was issued for another currency")
+		}
+
+		if filter.Offset > 0 {
+			parser.fail("offset", postgres.ReasonInvalidValue, "cannot be combined with cursor")
+		}
+	}
+
+	parser.rejectUnknown()
+	if len(parser.errors) > 0 {
+		return Filter{}, &postgres.ValidationError{Errors: parser.errors, Kind: ErrInvalidFilter}
+	}
+
+	return filter, nil
+}
+
+func (f Filter) sortsBy(field string) bool {
+	for _, key := range f.Sort {
+		if key.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+type filterParser struct {
+	query  map[string][]string
+	known  map[string]bool
+	errors []postgres.FieldError
+}
+
+func (p *filterParser) fail(field string, reason postgres.Reason, message string) {
+	p.errors = append(p.errors, postgres.FieldError{Field: field, Reason: reason, Message: message})
+}
+
+// value returns the value of a single-valued query parameter, repeating it is rejected rather than
+// keeping one of the values
+func (p *filterParser) value(key string) (string, bool) {
+	p.known[key] = true
+	values, ok := p.query[key]
+	if !ok || len(values) == 0 {
+		return "", false
+	}
+
+	if len(values) > 1 {
+		p.fail(key, postgres.ReasonInvalidValue, "must be given once")
+		return "", false
+	}
+
+	return values[0], true
+}
+
+// values returns every value of a repeated query parameter, e.g. type=camper-van&type=trailer
+func (p *filterParser) values(key string) []string {
+	p.known[key] = true
+	return p.query[key]
+}
+
+func (p *filterParser) rejectUnknown() {
+	unknown := make([]string, 0)
+	for key := range p.query {
+		if !p.known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		p.fail(key, postgres.ReasonUnknownParameter, "is not a supported query parameter")
+	}
+}
+
+func (p *filterParser) integer(key string, min int) *int {
+	value, ok := p.value(key)
+	if !ok {
+		return nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail(key, postgres.ReasonInvalidValue, "must be an integer")
+		return nil
+	}
+
+	if number < min {
+		p.fail(key, postgres.ReasonOutOfRange, fmt.Sprintf("must be greater than or equal to %d", min))
+		return nil
+	}
+
+	return &number
+}
+
+func (p *filterParser) nonNegativeInt(key string) *int {
+	return p.integer(key, 0)
+}
+
+func (p *filterParser) nonNegativeFloat(key string) *float64 {
+	value, ok := p.value(key)
+	if !ok {
+		return nil
+	}
+
+	number, err := parseFiniteFloat(value)
+	if err != nil {
+		p.fail(key, postgres.ReasonInvalidValue, "must be a number")
+		return nil
+	}
+
+	if number < 0 {
+		p.fail(key, postgres.ReasonOutOfRange, "must be greater than or equal to 0")
+		return nil
+	}
+
+	return &number
+}
+
+// rating accepts a mean rating between the bounds of a review
+func (p *filterParser) rating(key string) *float64 {
+	rating := p.nonNegativeFloat(key)
+	if rating != nil && *rating > reviews.MaxRating {
+		p.fail(key, postgres.ReasonOutOfRange, fmt.Sprintf("must be between 0 and %d", reviews.MaxRating))
+		return nil
+	}
+
+	return rating
+}
+
+func (p *filterParser) text(key string) string {
+	value, ok := p.value(key)
+	if !ok {
+		return ""
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		p.fail(key, postgres.ReasonInvalidValue, "must not be empty")
+	}
+
+	return value
+}
+
+// texts collects the values of a multi-valued parameter, blank values are rejected
+func (p *filterParser) texts(key string) []string {
+	values := p.values(key)
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			p.fail(key, postgres.ReasonInvalidValue, "must not be empty")
+			return nil
+		}
+
+		result = append(result, value)
+	}
+
+	return result
+}
+
+// date parses a calendar date as midnight UTC
+func (p *filterParser) date(key string) *time.Time {
+	value, ok := p.value(key)
+	if !ok {
+		return nil
+	}
+
+	date, err := time.ParseInLocation(dateLayout, strings.TrimSpace(value), time.UTC)
+	if err != nil {
+		p.fail(key, postgres.ReasonInvalidValue, "must be a date in YYYY-MM-DD format")
+		return nil
+	}
+
+	return &date
+}
+
+// currency accepts an ISO 4217 code in any case, whether rates exist for it is checked by the repository
+func (p *filterParser) currency(key string) string {
+	value, ok := p.value(key)
+	if !ok {
+		return ""
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(value))
+	if !currencies.ValidCode(code) {
+		p.fail(key, postgres.ReasonInvalidValue, "must be an ISO 4217 code such as USD")
+		return ""
+	}
+
+	return code
+}
+
+// amenities collects slugs from comma-separated and repeated values, e.g. amenities=kitchen,pets&amenities=solar.
+// Slugs are matched in lowercase and duplicates are dropped.
+func (p *filterParser) amenities(key string) []string {
+	values := p.values(key)
+	if len(values) == 0 {
+		return nil
+	}
+
+	slugs := make([]string, 0, len(values))
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, slug := range strings.Split(value, ",") {
+			slug = strings.ToLower(strings.TrimSpace(slug))
+			if !amenities.ValidSlug(slug) {
+				p.fail(key, postgres.ReasonInvalidValue, "must be a comma-separated list of amenity slugs such as kitchen,pets")
+				return nil
+			}
+
+			if !seen[slug] {
+				seen[slug] = true
+				slugs = append(slugs, slug)
+			}
+		}
+	}
+
+	return slugs
+}
+
+func (p *filterParser) amenitiesMatch(key string) string {
+	value, ok := p.value(key)
+	if !ok {
+		return ""
+	}
+
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case AmenitiesMatchAll, AmenitiesMatchAny:
+		return value
+	default:
+		p.fail(key, postgres.ReasonInvalidValue, fmt.Sprintf("must be %s or %s", AmenitiesMatchAll, AmenitiesMatchAny))
+		return ""
+	}
+}
+
+// facets collects facet names from comma-separated and repeated values, e.g. facets=type,make&facets=price
+func (p *filterParser) facets(key string) []string {
+	values := p.values(key)
+	if len(values) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if _, ok := facets[name]; !ok {
+				p.fail(key, postgres.ReasonInvalidValue, "must be a comma-separated list of "+strings.Join(facetNames, ", "))
+				return nil
+			}
+
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+func (p *filterParser) offset(key string) int {
+	if offset := p.integer(key, 0); offset != nil {
+		return *offset
+	}
+
+	return 0
+}
+
+func (p *filterParser) limit(key string) int {
+	limit := p.integer(key, 1)
+	if limit == nil {
+		return DefaultLimit
+	}
+
+	if *limit > MaxLimit {
+		p.fail(key, postgres.ReasonOutOfRange, fmt.Sprintf("must be less than or equal to %d", MaxLimit))
+		return DefaultLimit
+	}
+
+	return *limit
+}
+
+// ids collects ids from comma-separated and repeated values, e.g. ids=1,2&ids=3
+func (p *filterParser) ids(key string) []int {
+	values := p.values(key)
+	if len(values) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0)
+	for _, value := range values {
+		for _, rawID := range strings.Split(value, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(rawID))
+			if err != nil || id <= 0 {
+				p.fail(key, postgres.ReasonInvalidValue, "must be a comma-separated list of positive integers")
+				return nil
+			}
+
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func parseFiniteFloat(value string) (float64, error) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("%s is not a finite number", value)
+	}
+
+	return number, nil
+}
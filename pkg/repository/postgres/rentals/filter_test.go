@@ -0,0 +1,322 @@
+package rentals_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filter", func() {
+	Context("ParseFilter", func() {
+		When("no query parameters are provided", func() {
+			It("should return a filter with the default limit", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter).To(Equal(rentals.Filter{Limit: rentals.DefaultLimit}))
+			})
+		})
+
+		When("all query parameters are valid", func() {
+			It("should parse them", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{
+					"price_min": {"100"},
+					"price_max": {"200"},
+					"ids":       {"1,2, 3"},
+					"near":      {"33.64,-117.93"},
+					"sort":      {"price"},
+					"offset":    {"10"},
+					"limit":     {"5"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(*filter.PriceMin).To(Equal(100))
+				Expect(*filter.PriceMax).To(Equal(200))
+				Expect(filter.IDs).To(Equal([]int{1, 2, 3}))
+				Expect(*filter.Near).To(Equal(rentals.Coordinates{LAT: 33.64, LNG: -117.93}))
+				Expect(filter.Sort).To(Equal([]rentals.SortKey{{Field: "price"}}))
+				Expect(filter.Offset).To(Equal(10))
+				Expect(filter.Limit).To(Equal(5))
+			})
+		})
+
+		When("attribute filters are provided", func() {
+			It("should parse them and keep every value of multi-valued parameters", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{
+					"type":       {"camper-van", "trailer"},
+					"sleeps_min": {"4"},
+					"year_min":   {"2010"},
+					"year_max":   {"2020"},
+					"length_min": {"15.5"},
+					"length_max": {"30"},
+					"make":       {"Volkswagen"},
+					"model":      {"Westfalia"},
+					"country":    {"US"},
+					"state":      {"CA", "OR"},
+					"city":       {"Portland"},
+					"zip":        {"97201"},
+					"user_id":    {"3"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter.Types).To(Equal([]string{"camper-van", "trailer"}))
+				Expect(*filter.SleepsMin).To(Equal(4))
+				Expect(*filter.YearMin).To(Equal(2010))
+				Expect(*filter.YearMax).To(Equal(2020))
+				Expect(*filter.LengthMin).To(Equal(15.5))
+				Expect(*filter.LengthMax).To(Equal(30.0))
+				Expect(filter.Makes).To(Equal([]string{"Volkswagen"}))
+				Expect(filter.Models).To(Equal([]string{"Westfalia"}))
+				Expect(filter.Countries).To(Equal([]string{"US"}))
+				Expect(filter.States).To(Equal([]string{"CA", "OR"}))
+				Expect(filter.Cities).To(Equal([]string{"Portland"}))
+				Expect(filter.ZIPs).To(Equal([]string{"97201"}))
+				Expect(*filter.UserID).To(Equal(3))
+			})
+		})
+
+		When("ids are repeated", func() {
+			It("should merge every value", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{"ids": {"1,2", "3"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter.IDs).To(Equal([]int{1, 2, 3}))
+			})
+		})
+
+		When("a single-valued parameter is repeated", func() {
+			It("should reject it instead of keeping one of the values", func() {
+				_, err := rentals.ParseFilter(map[string][]string{"price_min": {"100", "500"}, "currency": {"USD", "EUR"}})
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Errors).To(ConsistOf(
+					postgres.FieldError{Field: "price_min", Reason: postgres.ReasonInvalidValue, Message: "must be given once"},
+					postgres.FieldError{Field: "currency", Reason: postgres.ReasonInvalidValue, Message: "must be given once"},
+				))
+			})
+		})
+
+		DescribeTable("radius",
+			func(radius string, expected rentals.Distance) {
+				query := map[string][]string{"near": {"33.64,-117.93"}}
+				if radius != "" {
+					query["radius"] = []string{radius}
+				}
+
+				filter, err := rentals.ParseFilter(query)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter.Radius).To(Equal(expected))
+			},
+			Entry("defaults when missing", "", rentals.DefaultRadius),
+			Entry("assumes miles without a unit", "25", rentals.Distance{Value: 25, Unit: rentals.Miles}),
+			Entry("accepts miles", "25.5mi", rentals.Distance{Value: 25.5, Unit: rentals.Miles}),
+			Entry("accepts kilometers", "40km", rentals.Distance{Value: 40, Unit: rentals.Kilometers}),
+		)
+
+		When("bbox and within are provided", func() {
+			It("should parse the viewport and the polygon", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{
+					"bbox":   {"-118.5,33.5,-117.5,34.5"},
+					"within": {`{"type":"Polygon","coordinates":[[[-118,33],[-117,33],[-117,34],[-118,33]]]}`},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(*filter.BBox).To(Equal(rentals.BoundingBox{MinLNG: -118.5, MinLAT: 33.5, MaxLNG: -117.5, MaxLAT: 34.5}))
+				Expect(filter.Within.Rings).To(Equal([][][2]float64{{{-118, 33}, {-117, 33}, {-117, 34}, {-118, 33}}}))
+			})
+		})
+
+		When("an availability window is provided", func() {
+			It("should parse both dates as midnight UTC", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{
+					"available_from": {"2024-06-01"},
+					"available_to":   {"2024-06-10"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(*filter.AvailableFrom).To(Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+				Expect(*filter.AvailableTo).To(Equal(time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)))
+			})
+		})
+
+		When("a minimum rating is provided together with sorting by rating", func() {
+			It("should parse it and sort best rated first with a minus", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{"rating_min": {"4.5"}, "sort": {"-rating"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(*filter.RatingMin).To(Equal(4.5))
+				Expect(filter.Sort).To(Equal([]rentals.SortKey{{Field: "rating", Descending: true}}))
+			})
+
+			It("should sort worst rated first without a minus, like every key but relevance", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{"sort": {"rating"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter.Sort).To(Equal([]rentals.SortKey{{Field: "rating"}}))
+				Expect(filter.Sort[0].String()).To(Equal("rating"))
+			})
+
+			It("should reject a rating above the maximum", func() {
+				_, err := rentals.ParseFilter(map[string][]string{"rating_min": {"6"}})
+				Expect(err).To(MatchError(rentals.ErrInvalidFilter))
+			})
+		})
+
+		When("amenities are provided", func() {
+			It("should collect comma-separated and repeated slugs in lowercase without duplicates", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{
+					"amenities":       {"Kitchen, pets", "solar,kitchen"},
+					"amenities_match": {"any"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter.Amenities).To(Equal([]string{"kitchen", "pets", "solar"}))
+				Expect(filter.AmenitiesMatch).To(Equal(rentals.AmenitiesMatchAny))
+			})
+
+			It("should reject a value that is not a slug", func() {
+				_, err := rentals.ParseFilter(map[string][]string{"amenities": {"kitchen,,pets"}})
+				Expect(err).To(MatchError(rentals.ErrInvalidFilter))
+			})
+
+			It("should reject amenities_match without amenities", func() {
+				_, err := rentals.ParseFilter(map[string][]string{"amenities_match": {"all"}})
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Errors).To(ConsistOf(postgres.FieldError{Field: "amenities_match",
+					Reason: postgres.ReasonInvalidValue, Message: "requires amenities"}))
+			})
+		})
+
+		When("facets are requested", func() {
+			It("should collect comma-separated and repeated names without duplicates", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{"facets": {"type, Make", "price,type"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter.Facets).To(Equal([]string{rentals.FacetType, rentals.FacetMake, rentals.FacetPrice}))
+			})
+
+			It("should reject an unknown facet", func() {
+				_, err := rentals.ParseFilter(map[string][]string{"facets": {"type,color"}})
+				Expect(err).To(MatchError(rentals.ErrInvalidFilter))
+			})
+		})
+
+		When("a currency is provided", func() {
+			It("should accept it in any case", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{"currency": {"eur"}, "price_max": {"9000"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter.Currency).To(Equal("EUR"))
+				Expect(*filter.PriceMax).To(Equal(9000))
+			})
+
+			It("should reject a code that is not three letters", func() {
+				_, err := rentals.ParseFilter(map[string][]string{"currency": {"euro"}})
+				Expect(err).To(MatchError(rentals.ErrInvalidFilter))
+			})
+		})
+
+		When("several sort keys are provided", func() {
+			It("should keep their order and direction", func() {
+				filter, err := rentals.ParseFilter(map[string][]string{"sort": {"-price,year,created"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter.Sort).To(Equal([]rentals.SortKey{
+					{Field: "price", Descending: true}, {Field: "year"}, {Field: "created"}}))
+			})
+		})
+
+		DescribeTable("text search sort",
+			func(query map[string][]string, expected []rentals.SortKey) {
+				filter, err := rentals.ParseFilter(query)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filter.Query).To(Equal("Westfalia pop-top"))
+				Expect(filter.Sort).To(Equal(expected))
+			},
+			Entry("defaults to best matches first", map[string][]string{"q": {" Westfalia pop-top "}},
+				[]rentals.SortKey{{Field: "relevance", Descending: true}}),
+			Entry("sorts best matches first by relevance", map[string][]string{"q": {"Westfalia pop-top"}, "sort": {"relevance"}},
+				[]rentals.SortKey{{Field: "relevance", Descending: true}}),
+			Entry("reverses relevance with a minus", map[string][]string{"q": {"Westfalia pop-top"}, "sort": {"-relevance,price"}},
+				[]rentals.SortKey{{Field: "relevance"}, {Field: "price"}}),
+			Entry("keeps an explicit sort", map[string][]string{"q": {"Westfalia pop-top"}, "sort": {"price"}},
+				[]rentals.SortKey{{Field: "price"}}),
+		)
+
+		When("a cursor issued for the same sort is provided", func() {
+			It("should decode it", func() {
+				cursor := rentals.Cursor{Sort: "year", Values: []interface{}{2001.0}, ID: 7}
+				filter, err := rentals.ParseFilter(map[string][]string{"sort": {"year"}, "cursor": {cursor.Encode()}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(*filter.Cursor).To(Equal(cursor))
+			})
+		})
+
+		DescribeTable("malformed query parameters",
+			func(query map[string][]string) {
+				_, err := rentals.ParseFilter(query)
+				Expect(err).To(MatchError(rentals.ErrInvalidFilter))
+			},
+			Entry("non-numeric price_min", map[string][]string{"price_min": {"1 OR 1=1"}}),
+			Entry("negative price_max", map[string][]string{"price_max": {"-1"}}),
+			Entry("price_min greater than price_max", map[string][]string{"price_min": {"20"}, "price_max": {"10"}}),
+			Entry("non-numeric ids", map[string][]string{"ids": {"1,2);DROP TABLE rentals;--"}}),
+			Entry("non-numeric repeated ids", map[string][]string{"ids": {"1", "two"}}),
+			Entry("repeated sort", map[string][]string{"sort": {"price", "-year"}}),
+			Entry("repeated limit", map[string][]string{"limit": {"5", "100"}}),
+			Entry("empty type", map[string][]string{"type": {"camper-van", " "}}),
+			Entry("non-numeric sleeps_min", map[string][]string{"sleeps_min": {"four"}}),
+			Entry("year_min greater than year_max", map[string][]string{"year_min": {"2020"}, "year_max": {"2010"}}),
+			Entry("negative length_min", map[string][]string{"length_min": {"-1"}}),
+			Entry("length_min greater than length_max", map[string][]string{"length_min": {"30"}, "length_max": {"20"}}),
+			Entry("non-positive user_id", map[string][]string{"user_id": {"0"}}),
+			Entry("near without longitude", map[string][]string{"near": {"33.64"}}),
+			Entry("near with out of range latitude", map[string][]string{"near": {"91,10"}}),
+			Entry("near with non-numeric longitude", map[string][]string{"near": {"33.64,abc"}}),
+			Entry("near with NaN latitude", map[string][]string{"near": {"NaN,10"}}),
+			Entry("radius without near", map[string][]string{"radius": {"10km"}}),
+			Entry("radius with unknown unit", map[string][]string{"near": {"33.64,-117.93"}, "radius": {"10ft"}}),
+			Entry("zero radius", map[string][]string{"near": {"33.64,-117.93"}, "radius": {"0"}}),
+			Entry("blank q", map[string][]string{"q": {"  "}}),
+			Entry("relevance sort without q", map[string][]string{"sort": {"relevance"}}),
+			Entry("distance sort without near", map[string][]string{"sort": {"distance"}}),
+			Entry("descending distance sort without near", map[string][]string{"sort": {"price,-distance"}}),
+			Entry("unknown sort key", map[string][]string{"sort": {"price,color"}}),
+			Entry("repeated sort key", map[string][]string{"sort": {"price,-price"}}),
+			Entry("empty sort key", map[string][]string{"sort": {"price,"}}),
+			Entry("bbox with three values", map[string][]string{"bbox": {"-118,33,-117"}}),
+			Entry("bbox with inverted corners", map[string][]string{"bbox": {"-117,33,-118,34"}}),
+			Entry("bbox with out of range latitude", map[string][]string{"bbox": {"-118,-91,-117,34"}}),
+			Entry("within that is not GeoJSON", map[string][]string{"within": {"POLYGON((0 0,1 0,1 1,0 0))"}}),
+			Entry("within that is a point", map[string][]string{"within": {`{"type":"Point","coordinates":[1,2]}`}}),
+			Entry("within with an open ring", map[string][]string{"within": {`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`}}),
+			Entry("within with too few positions", map[string][]string{"within": {`{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}`}}),
+			Entry("available_from that is not a date", map[string][]string{"available_from": {"June 1"}, "available_to": {"2024-06-10"}}),
+			Entry("available_from without available_to", map[string][]string{"available_from": {"2024-06-01"}}),
+			Entry("available_to without available_from", map[string][]string{"available_to": {"2024-06-10"}}),
+			Entry("available_to before available_from", map[string][]string{"available_from": {"2024-06-10"}, "available_to": {"2024-06-01"}}),
+			Entry("cursor that is not base64", map[string][]string{"cursor": {"!!!"}}),
+			Entry("cursor issued for another direction", map[string][]string{"sort": {"-year"},
+				"cursor": {rentals.Cursor{Sort: "year", Values: []interface{}{2001}, ID: 7}.Encode()}}),
+			Entry("cursor issued for another sort", map[string][]string{"sort": {"price"},
+				"cursor": {rentals.Cursor{Sort: "year", Values: []interface{}{2001}, ID: 7}.Encode()}}),
+			Entry("cursor combined with offset", map[string][]string{"offset": {"10"},
+				"cursor": {rentals.Cursor{ID: 7}.Encode()}}),
+			Entry("non-numeric offset", map[string][]string{"offset": {"abc"}}),
+			Entry("zero limit", map[string][]string{"limit": {"0"}}),
+			Entry("limit above the maximum", map[string][]string{"limit": {"101"}}),
+			Entry("unknown parameter", map[string][]string{"price": {"100"}}),
+		)
+
+		When("several query parameters are invalid", func() {
+			It("should report every invalid field", func() {
+				_, err := rentals.ParseFilter(map[string][]string{
+					"price_min": {"abc"},
+					"near":      {"100,10"},
+					"colour":    {"red"},
+				})
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Errors).To(ConsistOf(
+					postgres.FieldError{Field: "price_min", Reason: postgres.ReasonInvalidValue, Message: "must be an integer"},
+					postgres.FieldError{Field: "near", Reason: postgres.ReasonOutOfRange,
+						Message: "latitude must be between -90 and 90 and longitude between -180 and 180"},
+					postgres.FieldError{Field: "colour", Reason: postgres.ReasonUnknownParameter, Message: "is not a supported query parameter"},
+				))
+			})
+		})
+	})
+})
@@ -0,0 +1,213 @@
+package rentals
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+type Coordinates struct {
+	LAT float64
+	LNG float64
+}
+
+type DistanceUnit string
+
+const (
+	Miles      DistanceUnit = "mi"
+	Kilometers DistanceUnit = "km"
+)
+
+// Meters returns how many meters there are in one unit
+func (u DistanceUnit) Meters() float64 {
+	if u == Kilometers {
+		return 1000
+	}
+
+	return 1609.344
+}
+
+type Distance struct {
+	Value float64
+	Unit  DistanceUnit
+}
+
+// DefaultRadius is the search radius used when near is given without radius
+var DefaultRadius = Distance{Value: 100, Unit: Miles}
+
+// BoundingBox is a map viewport expressed in degrees
+type BoundingBox struct {
+	MinLNG float64
+	MinLAT float64
+	MaxLNG float64
+	MaxLAT float64
+}
+
+type Polygon struct {
+	// Rings holds the exterior ring followed by any holes, every position is a lng,lat pair
+	Rings [][][2]float64
+}
+
+type geoJSONPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+// GeoJSON encodes the polygon as a GeoJSON geometry
+func (p Polygon) GeoJSON() string {
+	encoded, _ := json.Marshal(geoJSONPolygon{Type: "Polygon", Coordinates: p.Rings})
+	return string(encoded)
+}
+
+func validCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+func (p *filterParser) coordinates(key string) *Coordinates {
+	value, ok := p.value(key)
+	if !ok {
+		return nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		p.fail(key, postgres.ReasonInvalidValue, "must be in the format lat,lng")
+		return nil
+	}
+
+	lat, latErr := parseFiniteFloat(parts[0])
+	lng, lngErr := parseFiniteFloat(parts[1])
+	if latErr != nil || lngErr != nil {
+		p.fail(key, postgres.ReasonInvalidValue, "latitude and longitude must be numbers")
+		return nil
+	}
+
+	if !validCoordinates(lat, lng) {
+		p.fail(key, postgres.ReasonOutOfRange, "latitude must be between -90 and 90 and longitude between -180 and 180")
+		return nil
+	}
+
+	return &Coordinates{LAT: lat, LNG: lng}
+}
+
+// distance parses values such as 50, 50mi or 80km, miles are assumed without a unit
+func (p *filterParser) distance(key string) Distance {
+	value, ok := p.value(key)
+	if !ok {
+		return Distance{}
+	}
+
+	unit := Miles
+	for _, candidate := range []DistanceUnit{Miles, Kilometers} {
+		if strings.HasSuffix(value, string(candidate)) {
+			unit = candidate
+			value = strings.TrimSuffix(value, string(candidate))
+		}
+	}
+
+	number, err := parseFiniteFloat(value)
+	if err != nil {
+		p.fail(key, postgres.ReasonInvalidValue, "must be a number optionally followed by mi or km")
+		return Distance{}
+	}
+
+	if number <= 0 {
+		p.fail(key, postgres.ReasonOutOfRange, "must be greater than 0")
+		return Distance{}
+	}
+
+	return Distance{Value: number, Unit: unit}
+}
+
+// boundingBox parses values in the format minLng,minLat,maxLng,maxLat
+func (p *filterParser) boundingBox(key string) *BoundingBox {
+	value, ok := p.value(key)
+	if !ok {
+		return nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		p.fail(key, postgres.ReasonInvalidValue, "must be in the format minLng,minLat,maxLng,maxLat")
+		return nil
+	}
+
+	numbers := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		number, err := parseFiniteFloat(part)
+		if err != nil {
+			p.fail(key, postgres.ReasonInvalidValue, "must contain only numbers")
+			return nil
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	box := BoundingBox{MinLNG: numbers[0], MinLAT: numbers[1], MaxLNG: numbers[2], MaxLAT: numbers[3]}
+	if !validCoordinates(box.MinLAT, box.MinLNG) || !validCoordinates(box.MaxLAT, box.MaxLNG) {
+		p.fail(key, postgres.ReasonOutOfRange, "latitudes must be between -90 and 90 and longitudes between -180 and 180")
+		return nil
+	}
+
+	if box.MinLNG >= box.MaxLNG || box.MinLAT >= box.MaxLAT {
+		p.fail(key, postgres.ReasonOutOfRange, "minimum coordinates must be lower than maximum coordinates")
+		return nil
+	}
+
+	return &box
+}
+
+// polygon parses a GeoJSON Polygon geometry
+func (p *filterParser) polygon(key string) *Polygon {
+	value, ok := p.value(key)
+	if !ok {
+		return nil
+	}
+
+	var geometry struct {
+		Type        string        `json:"type"`
+		Coordinates [][][]float64 `json:"coordinates"`
+	}
+	if err := json.Unmarshal([]byte(value), &geometry); err != nil || geometry.Type != "Polygon" {
+		p.fail(key, postgres.ReasonInvalidValue, "must be a GeoJSON Polygon geometry")
+		return nil
+	}
+
+	if len(geometry.Coordinates) == 0 {
+		p.fail(key, postgres.ReasonInvalidValue, "must contain at least one linear ring")
+		return nil
+	}
+
+	polygon := Polygon{Rings: make([][][2]float64, 0, len(geometry.Coordinates))}
+	for _, rawRing := range geometry.Coordinates {
+		if len(rawRing) < 4 {
+			p.fail(key, postgres.ReasonInvalidValue, "every linear ring must have at least four positions")
+			return nil
+		}
+
+		ring := make([][2]float64, 0, len(rawRing))
+		for _, position := range rawRing {
+			if len(position) < 2 {
+				p.fail(key, postgres.ReasonInvalidValue, "every position must be a lng,lat pair")
+				return nil
+			}
+
+			if !validCoordinates(position[1], position[0]) {
+				p.fail(key, postgres.ReasonOutOfRange, "latitudes must be between -90 and 90 and longitudes between -180 and 180")
+				return nil
+			}
+
+			ring = append(ring, [2]float64{position[0], position[1]})
+		}
+
+		if ring[0] != ring[len(ring)-1] {
+			p.fail(key, postgres.ReasonInvalidValue, "every linear ring must be closed")
+			return nil
+		}
+
+		polygon.Rings = append(polygon.Rings, ring)
+	}
+
+	return &polygon
+}
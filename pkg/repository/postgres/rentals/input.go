@@ -0,0 +1,237 @@
+package rentals
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/currencies"
+)
+
+// maxVehicleLength is the first length that does not fit in the numeric(4,2) vehicle_length column
+const maxVehicleLength = 100
+
+// maxSurchargePercent is the largest percentage that fits in the numeric(5,2) weekend_surcharge_percent column
+const maxSurchargePercent = 999.99
+
+// Input holds every writable field of a rental, it is used when creating or replacing a rental
+type Input struct {
+	Name            string
+	Description     string
+	Type            string
+	VehicleMake     string
+	VehicleModel    string
+	VehicleYear     int
+	VehicleLength   float32
+	Sleeps          int
+	PrimaryImageURL string
+	PricePerDay     int
+	// pricing rules, percentages are stored as 12.5 for 12.5%
+	WeeklyDiscountPercent   float64
+	MonthlyDiscountPercent  float64
+	WeekendSurchargePercent float64
+	CleaningFee             int
+	ServiceFeePercent       float64
+	// Currency of the prices above, currencies.DefaultCurrency when empty
+	Currency    string
+	HomeCity    string
+	HomeState   string
+	HomeZIP     string
+	HomeCountry string
+	LAT         float32
+	LNG         float32
+	UserID      int
+}
+
+// Precondition makes a write fail with postgres.ErrPreconditionFailed when the rental was updated at another time than Updated,
+// which is how clients holding an ETag get optimistic concurrency. The zero value writes unconditionally.
+type Precondition struct {
+	Updated time.Time
+}
+
+// IsZero reports whether the write is unconditional
+func (p Precondition) IsZero() bool {
+	return p.Updated.IsZero()
+}
+
+// Patch holds the fields of a partial update, nil fields are left unchanged
+type Patch struct {
+	Name            *string
+	Description     *string
+	Type            *string
+	VehicleMake     *string
+	VehicleModel    *string
+	VehicleYear     *int
+	VehicleLength   *float32
+	Sleeps          *int
+	PrimaryImageURL *string
+	PricePerDay     *int
+	// pricing rules, percentages are stored as 12.5 for 12.5%
+	WeeklyDiscountPercent   *float64
+	MonthlyDiscountPercent  *float64
+	WeekendSurchargePercent *float64
+	CleaningFee             *int
+	ServiceFeePercent       *float64
+	Currency                *string
+	HomeCity                *string
+	HomeState               *string
+	HomeZIP                 *string
+	HomeCountry             *string
+	LAT                     *float32
+	LNG                     *float32
+	UserID                  *int
+}
+
+// ToPatch turns the input into a patch setting every field, the currency defaults to currencies.DefaultCurrency
+func (i Input) ToPatch() Patch {
+	if i.Currency == "" {
+		i.Currency = currencies.DefaultCurrency
+	}
+
+	return Patch{
+		Name:                    &i.Name,
+		Description:             &i.Description,
+		Type:                    &i.Type,
+		VehicleMake:             &i.VehicleMake,
+		VehicleModel:            &i.VehicleModel,
+		VehicleYear:             &i.VehicleYear,
+		VehicleLength:           &i.VehicleLength,
+		Sleeps:                  &i.Sleeps,
+		PrimaryImageURL:         &i.PrimaryImageURL,
+		PricePerDay:             &i.PricePerDay,
+		WeeklyDiscountPercent:   &i.WeeklyDiscountPercent,
+		MonthlyDiscountPercent:  &i.MonthlyDiscountPercent,
+		WeekendSurchargePercent: &i.WeekendSurchargePercent,
+		CleaningFee:             &i.CleaningFee,
+		ServiceFeePercent:       &i.ServiceFeePercent,
+		Currency:                &i.Currency,
+		HomeCity:                &i.HomeCity,
+		HomeState:               &i.HomeState,
+		HomeZIP:                 &i.HomeZIP,
+		HomeCountry:             &i.HomeCountry,
+		LAT:                     &i.LAT,
+		LNG:                     &i.LNG,
+		UserID:                  &i.UserID,
+	}
+}
+
+type assignment struct {
+	column string
+	value  interface{}
+}
+
+// assignments lists the columns set by the patch in a stable order
+func (p Patch) assignments() []assignment {
+	assignments := make([]assignment, 0)
+	add := func(column string, set bool, value interface{}) {
+		if set {
+			assignments = append(assignments, assignment{column: column, value: value})
+		}
+	}
+
+	add("name", p.Name != nil, p.Name)
+	add("description", p.Description != nil, p.Description)
+	add("type", p.Type != nil, p.Type)
+	add("vehicle_make", p.VehicleMake != nil, p.VehicleMake)
+	add("vehicle_model", p.VehicleModel != nil, p.VehicleModel)
+	add("vehicle_year", p.VehicleYear != nil, p.VehicleYear)
+	add("vehicle_length", p.VehicleLength != nil, p.VehicleLength)
+	add("sleeps", p.Sleeps != nil, p.Sleeps)
+	add("primary_image_url", p.PrimaryImageURL != nil, p.PrimaryImageURL)
+	add("price_per_day", p.PricePerDay != nil, p.PricePerDay)
+	add("weekly_discount_percent", p.WeeklyDiscountPercent != nil, p.WeeklyDiscountPercent)
+	add("monthly_discount_percent", p.MonthlyDiscountPercent != nil, p.MonthlyDiscountPercent)
+	add("weekend_surcharge_percent", p.WeekendSurchargePercent != nil, p.WeekendSurchargePercent)
+	add("cleaning_fee", p.CleaningFee != nil, p.CleaningFee)
+	add("service_fee_percent", p.ServiceFeePercent != nil, p.ServiceFeePercent)
+	add("currency", p.Currency != nil, p.Currency)
+	add("home_city", p.HomeCity != nil, p.HomeCity)
+	add("home_state", p.HomeState != nil, p.HomeState)
+	add("home_zip", p.HomeZIP != nil, p.HomeZIP)
+	add("home_country", p.HomeCountry != nil, p.HomeCountry)
+	add("lat", p.LAT != nil, p.LAT)
+	add("lng", p.LNG != nil, p.LNG)
+	add("user_id", p.UserID != nil, p.UserID)
+
+	return assignments
+}
+
+// Validate checks the fields set by the patch and returns every problem as a *postgres.ValidationError.
+// Field names follow the JSON representation of a rental.
+func (p Patch) Validate() error {
+	var errs []postgres.FieldError
+	fail := func(field string, reason postgres.Reason, message string) {
+		errs = append(errs, postgres.FieldError{Field: field, Reason: reason, Message: message})
+	}
+
+	if p.Name != nil && *p.Name == "" {
+		fail("name", postgres.ReasonInvalidValue, "must not be empty")
+	}
+
+	if p.Type != nil && *p.Type == "" {
+		fail("type", postgres.ReasonInvalidValue, "must not be empty")
+	}
+
+	if p.VehicleYear != nil && *p.VehicleYear < 0 {
+		fail("year", postgres.ReasonOutOfRange, "must be greater than or equal to 0")
+	}
+
+	if p.VehicleLength != nil && (*p.VehicleLength < 0 || *p.VehicleLength >= maxVehicleLength) {
+		fail("length", postgres.ReasonOutOfRange, fmt.Sprintf("must be greater than or equal to 0 and less than %d", maxVehicleLength))
+	}
+
+	if p.Sleeps != nil && *p.Sleeps < 0 {
+		fail("sleeps", postgres.ReasonOutOfRange, "must be greater than or equal to 0")
+	}
+
+	if p.PricePerDay != nil && *p.PricePerDay < 0 {
+		fail("price.day", postgres.ReasonOutOfRange, "must be greater than or equal to 0")
+	}
+
+	percents := []struct {
+		field string
+		value *float64
+		max   float64
+	}{
+		{"price.weekly_discount_percent", p.WeeklyDiscountPercent, 100},
+		{"price.monthly_discount_percent", p.MonthlyDiscountPercent, 100},
+		{"price.weekend_surcharge_percent", p.WeekendSurchargePercent, maxSurchargePercent},
+		{"price.service_fee_percent", p.ServiceFeePercent, 100},
+	}
+	for _, percent := range percents {
+		if percent.value != nil && (*percent.value < 0 || *percent.value > percent.max) {
+			fail(percent.field, postgres.ReasonOutOfRange, fmt.Sprintf("must be between 0 and %g", percent.max))
+		}
+	}
+
+	if p.CleaningFee != nil && *p.CleaningFee < 0 {
+		fail("price.cleaning_fee", postgres.ReasonOutOfRange, "must be greater than or equal to 0")
+	}
+
+	if p.Currency != nil && !currencies.ValidCode(*p.Currency) {
+		fail("price.currency", postgres.ReasonInvalidValue, "must be an ISO 4217 code such as USD")
+	}
+
+	if p.LAT != nil && (*p.LAT < -90 || *p.LAT > 90) {
+		fail("location.lat", postgres.ReasonOutOfRange, "must be between -90 and 90")
+	}
+
+	if p.LNG != nil && (*p.LNG < -180 || *p.LNG > 180) {
+		fail("location.lng", postgres.ReasonOutOfRange, "must be between -180 and 180")
+	}
+
+	if p.UserID != nil && *p.UserID <= 0 {
+		fail("user_id", postgres.ReasonInvalidValue, "must be a positive integer")
+	}
+
+	if len(errs) > 0 {
+		return &postgres.ValidationError{Errors: errs, Kind: ErrInvalidRental}
+	}
+
+	return nil
+}
+
+// Validate checks every field of the input and returns every problem as a *postgres.ValidationError
+func (i Input) Validate() error {
+	return i.ToPatch().Validate()
+}
@@ -0,0 +1,58 @@
+package rentals_test
+
+import (
+	"errors"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Input", func() {
+	valid := func() rentals.Input {
+		return rentals.Input{Name: "Westfalia", Type: "camper-van", VehicleYear: 1984, VehicleLength: 15.5,
+			Sleeps: 4, PricePerDay: 120, LAT: 45.5, LNG: -122.6, UserID: 3}
+	}
+
+	When("every field is valid", func() {
+		It("should succeed", func() {
+			Expect(valid().Validate()).To(Succeed())
+		})
+	})
+
+	DescribeTable("invalid fields",
+		func(change func(input *rentals.Input), field string) {
+			input := valid()
+			change(&input)
+
+			err := input.Validate()
+			Expect(err).To(MatchError(rentals.ErrInvalidRental))
+			var validationErr *postgres.ValidationError
+			Expect(errors.As(err, &validationErr)).To(BeTrue())
+			Expect(validationErr.Errors).To(HaveLen(1))
+			Expect(validationErr.Errors[0].Field).To(Equal(field))
+		},
+		Entry("empty name", func(input *rentals.Input) { input.Name = "" }, "name"),
+		Entry("empty type", func(input *rentals.Input) { input.Type = "" }, "type"),
+		Entry("negative year", func(input *rentals.Input) { input.VehicleYear = -1 }, "year"),
+		Entry("length not fitting the column", func(input *rentals.Input) { input.VehicleLength = 100 }, "length"),
+		Entry("negative sleeps", func(input *rentals.Input) { input.Sleeps = -1 }, "sleeps"),
+		Entry("negative price", func(input *rentals.Input) { input.PricePerDay = -1 }, "price.day"),
+		Entry("weekly discount above 100%", func(input *rentals.Input) { input.WeeklyDiscountPercent = 101 },
+			"price.weekly_discount_percent"),
+		Entry("negative weekend surcharge", func(input *rentals.Input) { input.WeekendSurchargePercent = -5 },
+			"price.weekend_surcharge_percent"),
+		Entry("negative cleaning fee", func(input *rentals.Input) { input.CleaningFee = -1 }, "price.cleaning_fee"),
+		Entry("out of range latitude", func(input *rentals.Input) { input.LAT = 91 }, "location.lat"),
+		Entry("out of range longitude", func(input *rentals.Input) { input.LNG = -181 }, "location.lng"),
+		Entry("missing owner", func(input *rentals.Input) { input.UserID = 0 }, "user_id"),
+	)
+
+	When("a patch leaves fields unset", func() {
+		It("should validate only the fields it sets", func() {
+			price := 150
+			Expect(rentals.Patch{PricePerDay: &price}.Validate()).To(Succeed())
+		})
+	})
+})
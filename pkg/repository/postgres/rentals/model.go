@@ -1,5 +1,7 @@
 package rentals
 
+import "time"
+
 type Model struct {
 	ID              int
 	Name            string
@@ -12,13 +14,44 @@ type Model struct {
 	Sleeps          int
 	PrimaryImageURL string
 	PricePerDay     int
-	HomeCity        string
-	HomeState       string
-	HomeZIP         string
-	HomeCountry     string
-	LAT             float32
-	LNG             float32
-	UserID          int
-	FirstName       string
-	LastName        string
+	// pricing rules, see pricing.Rules
+	WeeklyDiscountPercent   float64
+	MonthlyDiscountPercent  float64
+	WeekendSurchargePercent float64
+	CleaningFee             int
+	ServiceFeePercent       float64
+	// Currency is the currency of the prices above, the one requested when prices were converted
+	Currency string
+	// RatingAvg is the mean rating of the reviews of the rental, 0 together with RatingCount when there are none
+	RatingAvg   float64
+	RatingCount int
+	HomeCity    string
+	HomeState   string
+	HomeZIP     string
+	HomeCountry string
+	LAT         float32
+	LNG         float32
+	UserID      int
+	FirstName   string
+	LastName    string
+	// UserUpdated is when the owner last changed, it versions FirstName and LastName
+	UserUpdated time.Time
+	Created     time.Time
+	Updated     time.Time
+	// Amenities are the slugs of the amenities offered by the rental, ordered by slug
+	Amenities []string
+	// Distance is only set when searching near a location, in the unit of the search radius
+	Distance *float64
+	// Relevance is only set when searching by text, higher values match q better
+	Relevance *float64
+}
+
+// Page is a window of rentals together with the number of rentals matching the filter.
+// NextCursor is empty when there are no more rentals after the page.
+// Facets holds the buckets of every facet requested by the filter, by facet name.
+type Page struct {
+	Rentals    []Model
+	Total      int
+	NextCursor string
+	Facets     map[string][]FacetBucket
 }
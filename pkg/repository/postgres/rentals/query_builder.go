@@ -5,94 +5,386 @@ import (
 	"strings"
 )
 
-const withinMiles = 100
+// geographic conditions compare against r.location, which is covered by a GiST index
+const (
+	geographyPoint    = "ST_SetSRID(ST_MakePoint(%s, %s), 4326)::geography"
+	geographyEnvelope = "ST_MakeEnvelope(%s, %s, %s, %s, 4326)::geography"
+	geographyGeoJSON  = "ST_SetSRID(ST_GeomFromGeoJSON(%s::text), 4326)::geography"
+)
+
+// textSearchQuery parses q the way search engines do, e.g. "pop-top" -diesel, using the GIN indexed r.search_vector
+const textSearchQuery = "websearch_to_tsquery('english', %s)"
+
+// convertedPrice converts an amount of the currency of the rental with the convert_price function of the schema
+const convertedPrice = "convert_price(%s, r.currency, %s)"
+
+// notBooked excludes rentals with a booking overlapping the window, using the GiST index of bookings_no_overlap
+const notBooked = "NOT EXISTS (SELECT 1 FROM bookings b WHERE b.rental_id = r.id AND b.period && daterange(%s::date, %s::date))"
+
+// amenitiesMatching counts the amenities of a rental among the listed slugs, using the primary key of rental_amenities
+const amenitiesMatching = "SELECT COUNT(*) FROM rental_amenities ra JOIN amenities a ON a.id = ra.amenity_id" +
+	" WHERE ra.rental_id = r.id AND %s"
+
+type queryBuilder struct {
+	query     string
+	args      []interface{}
+	point     string
+	distance  string
+	tsQuery   string
+	relevance string
+	currency  string
+	price     string
+}
+
+func buildSQLQuery(filter Filter) (string, []interface{}) {
+	builder := &queryBuilder{}
+	builder.addSelect(filter)
+	builder.addWhereClause(filter, builder.keysetCondition(filter))
+	builder.addSorting(filter)
+	builder.addPagination(filter)
+
+	return builder.query, builder.args
+}
+
+// buildCountQuery counts every rental matching the filter, ignoring sorting and pagination
+func buildCountQuery(filter Filter) (string, []interface{}) {
+	builder := &queryBuilder{query: fmt.Sprintf("SELECT COUNT(*) %s", rentalsFrom)}
+	builder.addWhereClause(filter, "")
+
+	return builder.query, builder.args
+}
+
+// bind registers an argument and returns its positional placeholder
+func (b *queryBuilder) bind(arg interface{}) string {
+	b.args = append(b.args, arg)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *queryBuilder) addSelect(filter Filter) {
+	if filter.Near == nil && filter.Query == "" && filter.Currency == "" {
+		b.query = selectRentals
+		return
+	}
+
+	columns := []string{rentalColumns}
+	if filter.Near != nil {
+		b.distance = fmt.Sprintf("ST_Distance(r.location, %s) / %s", b.nearPoint(filter), b.bind(filter.Radius.Unit.Meters()))
+		columns = append(columns, fmt.Sprintf("%s AS distance", b.distance))
+	}
+
+	if filter.Query != "" {
+		// ts_rank returns a real, the cast keeps the value exact when it comes back in a cursor
+		b.relevance = fmt.Sprintf("ts_rank(r.search_vector, %s)::double precision", b.textSearch(filter))
+		columns = append(columns, fmt.Sprintf("%s AS relevance", b.relevance))
+	}
+
+	if filter.Currency != "" {
+		columns = append(columns, fmt.Sprintf("%s AS price_in_currency", b.priceColumn(filter)),
+			fmt.Sprintf("%s AS cleaning_fee_in_currency", fmt.Sprintf(convertedPrice, "r.cleaning_fee", b.targetCurrency(filter))))
+	}
+
+	b.query = fmt.Sprintf("SELECT %s %s", strings.Join(columns, ", "), rentalsFrom)
+}
+
+// nearPoint binds the near coordinates once and reuses their placeholders afterwards
+func (b *queryBuilder) nearPoint(filter Filter) string {
+	if b.point == "" {
+		b.point = fmt.Sprintf(geographyPoint, b.bind(filter.Near.LNG), b.bind(filter.Near.LAT))
+	}
+
+	return b.point
+}
+
+// textSearch binds q once and reuses its placeholder afterwards
+func (b *queryBuilder) textSearch(filter Filter) string {
+	if b.tsQuery == "" {
+		b.tsQuery = fmt.Sprintf(textSearchQuery, b.bind(filter.Query))
+	}
+
+	return b.tsQuery
+}
+
+// targetCurrency binds the requested currency once and reuses its placeholder afterwards
+func (b *queryBuilder) targetCurrency(filter Filter) string {
+	if b.currency == "" {
+		b.currency = b.bind(filter.Currency)
+	}
 
-func buildSQLQuery(query string, clauses map[string][]string) string {
-	return addPagination(addSorting(addWhereClause(query, clauses), clauses), clauses)
+	return b.currency
 }
 
-func addWhereClause(query string, clauses map[string][]string) string {
-	addedConditions := 0
-	resultQuery := fmt.Sprintf("%s WHERE", query)
-	priceMin, ok := clauses["price_min"]
-	if ok {
-		resultQuery = fmt.Sprintf("%s r.price_per_day >= %s", resultQuery, priceMin[0])
-		addedConditions++
+// priceColumn is the price per day in the requested currency, or in the currency of every rental when none is requested
+func (b *queryBuilder) priceColumn(filter Filter) string {
+	if filter.Currency == "" {
+		return "r.price_per_day"
+	}
+
+	if b.price == "" {
+		b.price = fmt.Sprintf(convertedPrice, "r.price_per_day", b.targetCurrency(filter))
+	}
+
+	return b.price
+}
+
+func (b *queryBuilder) addWhereClause(filter Filter, extraConditions ...string) {
+	conditions := make([]string, 0)
+	if filter.PriceMin != nil {
+		conditions = append(conditions, fmt.Sprintf("%s >= %s", b.priceColumn(filter), b.bind(*filter.PriceMin)))
+	}
+
+	if filter.PriceMax != nil {
+		conditions = append(conditions, fmt.Sprintf("%s <= %s", b.priceColumn(filter), b.bind(*filter.PriceMax)))
 	}
 
-	priceMax, ok := clauses["price_max"]
-	if ok {
-		if addedConditions > 0 {
-			resultQuery = fmt.Sprintf("%s AND", resultQuery)
+	if len(filter.IDs) > 0 {
+		placeholders := make([]string, 0, len(filter.IDs))
+		for _, id := range filter.IDs {
+			placeholders = append(placeholders, b.bind(id))
 		}
 
-		resultQuery = fmt.Sprintf("%s r.price_per_day <= %s", resultQuery, priceMax[0])
-		addedConditions++
+		conditions = append(conditions, fmt.Sprintf("r.id IN (%s)", strings.Join(placeholders, ", ")))
+	}
+
+	if len(filter.Types) > 0 {
+		conditions = append(conditions, b.inCondition("r.type", filter.Types))
+	}
+
+	if filter.SleepsMin != nil {
+		conditions = append(conditions, fmt.Sprintf("r.sleeps >= %s", b.bind(*filter.SleepsMin)))
+	}
+
+	if filter.YearMin != nil {
+		conditions = append(conditions, fmt.Sprintf("r.vehicle_year >= %s", b.bind(*filter.YearMin)))
 	}
 
-	ids, ok := clauses["ids"]
-	if ok {
-		if addedConditions > 0 {
-			resultQuery = fmt.Sprintf("%s AND", resultQuery)
+	if filter.YearMax != nil {
+		conditions = append(conditions, fmt.Sprintf("r.vehicle_year <= %s", b.bind(*filter.YearMax)))
+	}
+
+	if filter.LengthMin != nil {
+		conditions = append(conditions, fmt.Sprintf("r.vehicle_length >= %s", b.bind(*filter.LengthMin)))
+	}
+
+	if filter.LengthMax != nil {
+		conditions = append(conditions, fmt.Sprintf("r.vehicle_length <= %s", b.bind(*filter.LengthMax)))
+	}
+
+	if filter.RatingMin != nil {
+		conditions = append(conditions, fmt.Sprintf("r.rating_avg >= %s", b.bind(*filter.RatingMin)))
+	}
+
+	if len(filter.Amenities) > 0 {
+		conditions = append(conditions, b.amenitiesCondition(filter))
+	}
+
+	textFilters := []struct {
+		column string
+		values []string
+	}{
+		{"r.vehicle_make", filter.Makes},
+		{"r.vehicle_model", filter.Models},
+		{"r.home_country", filter.Countries},
+		{"r.home_state", filter.States},
+		{"r.home_city", filter.Cities},
+	}
+	for _, textFilter := range textFilters {
+		if len(textFilter.values) > 0 {
+			conditions = append(conditions, b.caseInsensitiveInCondition(textFilter.column, textFilter.values))
 		}
+	}
+
+	if len(filter.ZIPs) > 0 {
+		conditions = append(conditions, b.inCondition("r.home_zip", filter.ZIPs))
+	}
+
+	if filter.UserID != nil {
+		conditions = append(conditions, fmt.Sprintf("r.user_id = %s", b.bind(*filter.UserID)))
+	}
+
+	if filter.Query != "" {
+		conditions = append(conditions, fmt.Sprintf("r.search_vector @@ %s", b.textSearch(filter)))
+	}
+
+	if filter.Near != nil {
+		point := b.nearPoint(filter)
+		radius := b.bind(filter.Radius.Value * filter.Radius.Unit.Meters())
+		conditions = append(conditions, fmt.Sprintf("ST_DWithin(r.location, %s, %s)", point, radius))
+	}
+
+	if filter.BBox != nil {
+		envelope := fmt.Sprintf(geographyEnvelope, b.bind(filter.BBox.MinLNG), b.bind(filter.BBox.MinLAT),
+			b.bind(filter.BBox.MaxLNG), b.bind(filter.BBox.MaxLAT))
+		conditions = append(conditions, fmt.Sprintf("ST_Covers(%s, r.location)", envelope))
+	}
+
+	if filter.Within != nil {
+		polygon := fmt.Sprintf(geographyGeoJSON, b.bind(filter.Within.GeoJSON()))
+		conditions = append(conditions, fmt.Sprintf("ST_Covers(%s, r.location)", polygon))
+	}
 
-		resultQuery = fmt.Sprintf("%s r.id IN (%s)", resultQuery, ids[0])
-		addedConditions++
+	if filter.AvailableFrom != nil && filter.AvailableTo != nil {
+		conditions = append(conditions, fmt.Sprintf(notBooked, b.bind(*filter.AvailableFrom), b.bind(*filter.AvailableTo)))
 	}
 
-	near, ok := clauses["near"]
-	if ok {
-		if addedConditions > 0 {
-			resultQuery = fmt.Sprintf("%s AND", resultQuery)
+	for _, condition := range extraConditions {
+		if condition != "" {
+			conditions = append(conditions, condition)
 		}
-		coordinates := strings.Split(near[0], ",")
-		distanceFormula := "%s (3959 * acos(cos(radians(%s)) * cos(radians(lat)) * cos(radians(lng) - radians(%s)) + sin(radians(%s)) * sin(radians(lat)))) < %d"
-		resultQuery = fmt.Sprintf(distanceFormula, resultQuery, coordinates[0], coordinates[1], coordinates[0], withinMiles)
-		addedConditions++
 	}
 
-	if addedConditions == 0 {
-		return query
+	if len(conditions) == 0 {
+		return
+	}
+
+	b.query = fmt.Sprintf("%s WHERE %s", b.query, strings.Join(conditions, " AND "))
+}
+
+func (b *queryBuilder) inCondition(column string, values []string) string {
+	placeholders := make([]string, 0, len(values))
+	for _, value := range values {
+		placeholders = append(placeholders, b.bind(value))
 	}
 
-	return resultQuery
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))
 }
 
-func addPagination(query string, clauses map[string][]string) string {
-	resultQuery := query
-	offset, ok := clauses["offset"]
-	if ok {
-		resultQuery = fmt.Sprintf("%s OFFSET %s", resultQuery, offset[0])
+// amenitiesCondition keeps rentals offering every listed amenity, or at least one of them when AmenitiesMatch is any
+func (b *queryBuilder) amenitiesCondition(filter Filter) string {
+	matching := fmt.Sprintf(amenitiesMatching, b.inCondition("a.slug", filter.Amenities))
+	if filter.AmenitiesMatch == AmenitiesMatchAny {
+		return fmt.Sprintf("(%s) > 0", matching)
 	}
 
-	limit, ok := clauses["limit"]
-	if ok {
-		resultQuery = fmt.Sprintf("%s LIMIT %s", resultQuery, limit[0])
+	return fmt.Sprintf("(%s) = %s", matching, b.bind(len(filter.Amenities)))
+}
+
+// caseInsensitiveInCondition matches free text such as make or city regardless of its case
+func (b *queryBuilder) caseInsensitiveInCondition(column string, values []string) string {
+	placeholders := make([]string, 0, len(values))
+	for _, value := range values {
+		placeholders = append(placeholders, b.bind(strings.ToLower(value)))
 	}
 
-	return resultQuery
+	return fmt.Sprintf("LOWER(%s) IN (%s)", column, strings.Join(placeholders, ", "))
 }
 
-func addSorting(query string, clauses map[string][]string) string {
-	resultQuery := query
-	sort, ok := clauses["sort"]
-	if ok {
-		// if the column does not exist, a order by clause will not be added
-		if column, ok := toDBColumnName(sort[0]); ok {
-			resultQuery = fmt.Sprintf("%s ORDER BY %s", resultQuery, column)
+// keysetCondition selects the rentals placed after the cursor in the order of the sort keys,
+// e.g. (price_per_day < $1) OR (price_per_day = $1 AND r.id > $2) for sort=-price
+func (b *queryBuilder) keysetCondition(filter Filter) string {
+	if filter.Cursor == nil {
+		return ""
+	}
+
+	// copied, appending to filter.Sort could write into its spare capacity shared with the caller
+	keys := append(append([]SortKey{}, filter.Sort...), SortKey{Field: "id"})
+	placeholders := make([]string, 0, len(keys))
+	for _, value := range filter.Cursor.Values {
+		placeholders = append(placeholders, b.bind(value))
+	}
+	placeholders = append(placeholders, b.bind(filter.Cursor.ID))
+
+	alternatives := make([]string, 0, len(keys))
+	for i, key := range keys {
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, fmt.Sprintf("%s = %s", b.sortExpression(keys[j]), placeholders[j]))
 		}
+
+		operator := ">"
+		if key.Descending {
+			operator = "<"
+		}
+		terms = append(terms, fmt.Sprintf("%s %s %s", b.sortExpression(key), operator, placeholders[i]))
+		alternatives = append(alternatives, fmt.Sprintf("(%s)", strings.Join(terms, " AND ")))
 	}
 
-	return resultQuery
+	return fmt.Sprintf("(%s)", strings.Join(alternatives, " OR "))
 }
 
-func toDBColumnName(key string) (string, bool) {
-	//supports price and year sorting, adding more columns will extend sorting options
-	columns := map[string]string{
-		"price": "price_per_day",
-		"year":  "vehicle_year",
+// addPagination requests one rental more than the limit, so the repository knows whether a next page exists
+func (b *queryBuilder) addPagination(filter Filter) {
+	if filter.Offset > 0 {
+		b.query = fmt.Sprintf("%s OFFSET %s", b.query, b.bind(filter.Offset))
+	}
+
+	if filter.Limit > 0 {
+		b.query = fmt.Sprintf("%s LIMIT %s", b.query, b.bind(filter.Limit+1))
+	}
+}
+
+// addSorting orders by the sort keys with r.id as a tie-breaker, which keeps pages stable
+func (b *queryBuilder) addSorting(filter Filter) {
+	columns := make([]string, 0, len(filter.Sort)+1)
+	for _, key := range filter.Sort {
+		column, _ := toDBColumnName(key.Field)
+		if key.Field == "price" && b.price != "" {
+			column = "price_in_currency"
+		}
+
+		if key.Descending {
+			column = fmt.Sprintf("%s DESC", column)
+		}
+
+		columns = append(columns, column)
 	}
-	dbColumnName, ok := columns[key]
+	columns = append(columns, "r.id")
+
+	b.query = fmt.Sprintf("%s ORDER BY %s", b.query, strings.Join(columns, ", "))
+}
+
+// sortExpression returns an expression usable in a WHERE clause, where select aliases are not visible
+func (b *queryBuilder) sortExpression(key SortKey) string {
+	switch key.Field {
+	case "id":
+		return "r.id"
+	case "distance":
+		return b.distance
+	case "relevance":
+		return b.relevance
+	case "price":
+		if b.price != "" {
+			return b.price
+		}
+	}
+
+	column, _ := toDBColumnName(key.Field)
+	return column
+}
+
+// buildInsertQuery inserts the input and reads the new rental back together with its owner
+func buildInsertQuery(input Input) (string, []interface{}) {
+	builder := &queryBuilder{}
+	columns := make([]string, 0)
+	placeholders := make([]string, 0)
+	for _, assignment := range input.ToPatch().assignments() {
+		columns = append(columns, assignment.column)
+		placeholders = append(placeholders, builder.bind(assignment.value))
+	}
+	columns = append(columns, "created", "updated")
+	placeholders = append(placeholders, "now()", "now()")
+
+	builder.query = fmt.Sprintf("WITH written AS (INSERT INTO rentals (%s) VALUES (%s) RETURNING *) %s",
+		strings.Join(columns, ", "), strings.Join(placeholders, ", "), selectWrittenRental)
+
+	return builder.query, builder.args
+}
+
+// buildUpdateQuery updates the fields set by the patch and reads the rental back together with its owner
+func buildUpdateQuery(id int, patch Patch, precondition Precondition) (string, []interface{}) {
+	builder := &queryBuilder{}
+	assignments := make([]string, 0)
+	for _, assignment := range patch.assignments() {
+		assignments = append(assignments, fmt.Sprintf("%s = %s", assignment.column, builder.bind(assignment.value)))
+	}
+	assignments = append(assignments, "updated = now()")
+
+	where := fmt.Sprintf("id = %s", builder.bind(id))
+	if !precondition.IsZero() {
+		where += fmt.Sprintf(" AND updated = %s", builder.bind(precondition.Updated))
+	}
+
+	builder.query = fmt.Sprintf("WITH written AS (UPDATE rentals SET %s WHERE %s RETURNING *) %s",
+		strings.Join(assignments, ", "), where, selectWrittenRental)
 
-	return dbColumnName, ok
+	return builder.query, builder.args
 }
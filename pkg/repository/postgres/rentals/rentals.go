@@ -3,7 +3,13 @@ package rentals
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/currencies"
 )
 
 type Repository struct {
@@ -24,11 +30,284 @@ func NewRepository(db *sql.DB) (*Repository, error) {
 	}, nil
 }
 
-// RetrieveRentalByID retrieves rental by a given id from repository
-func (r *Repository) RetrieveRentalByID(ctx context.Context, id string) (Model, error) {
-	row := r.selectRentalByIDStmt.QueryRowContext(ctx, id)
+// RetrieveRentalByID retrieves rental by a given id from repository,
+// its prices are converted to a given currency unless it is empty
+func (r *Repository) RetrieveRentalByID(ctx context.Context, id int, currency string) (Model, error) {
+	if id <= 0 {
+		return Model{}, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	filter := Filter{IDs: []int{id}, Currency: currency}
+	var row *sql.Row
+	if currency == "" {
+		row = r.selectRentalByIDStmt.QueryRowContext(ctx, id)
+	} else {
+		if err := checkCurrency(ctx, r.db, currency); err != nil {
+			return Model{}, err
+		}
+
+		query, args := buildSQLQuery(filter)
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	rental, err := scanRental(row, filter)
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to scan row: %w", err))
+	}
+
+	return rental, nil
+}
+
+// RetrieveRentals retrieves a page of rentals matching a given filter together with the total count
+// and the requested facets. Every query runs in a single read-only transaction, so they observe the same snapshot.
+func (r *Repository) RetrieveRentals(ctx context.Context, filter Filter) (Page, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	if filter.Currency != "" {
+		if err := checkCurrency(ctx, tx, filter.Currency); err != nil {
+			return Page{}, err
+		}
+	}
+
+	var page Page
+	countQuery, countArgs := buildCountQuery(filter)
+	if err := tx.QueryRowContext(ctx, countQuery, countArgs...).Scan(&page.Total); err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to execute count rentals query: %w", err))
+	}
+
+	if page.Rentals, err = selectRentalsPage(ctx, tx, filter); err != nil {
+		return Page{}, err
+	}
+
+	if filter.Limit > 0 && len(page.Rentals) > filter.Limit {
+		page.Rentals = page.Rentals[:filter.Limit]
+		page.NextCursor = NewCursor(filter, page.Rentals[filter.Limit-1]).Encode()
+	}
+
+	if len(filter.Facets) > 0 {
+		page.Facets = make(map[string][]FacetBucket, len(filter.Facets))
+		for _, name := range filter.Facets {
+			if page.Facets[name], err = selectFacet(ctx, tx, name, filter); err != nil {
+				return Page{}, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return page, nil
+}
+
+func selectRentalsPage(ctx context.Context, tx *sql.Tx, filter Filter) ([]Model, error) {
+	query, args := buildSQLQuery(filter)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to execute select rentals query: %w", err))
+	}
+	defer rows.Close()
+
+	rentals := make([]Model, 0)
+	for rows.Next() {
+		rental, err := scanRental(rows, filter)
+		if err != nil {
+			return nil, postgres.Classify(fmt.Errorf("failed to scan a row: %w", err))
+		}
+
+		rentals = append(rentals, rental)
+	}
+
+	if rows.Err() != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed while iterating over rows: %w", rows.Err()))
+	}
+
+	return rentals, nil
+}
+
+// selectFacet counts the rentals of every bucket of a facet, histograms list empty buckets too
+func selectFacet(ctx context.Context, tx *sql.Tx, name string, filter Filter) ([]FacetBucket, error) {
+	query, args := buildFacetQuery(name, filter)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed to execute %s facet query: %w", name, err))
+	}
+	defer rows.Close()
+
+	bounds := facets[name].bounds
+	buckets := make([]FacetBucket, 0)
+	counts := make(map[int]int)
+	for rows.Next() {
+		var (
+			value sql.NullString
+			count int
+		)
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, postgres.Classify(fmt.Errorf("failed to scan a row: %w", err))
+		}
+
+		if bounds == nil {
+			buckets = append(buckets, FacetBucket{Value: value.String, Count: count})
+			continue
+		}
+
+		bucket, err := strconv.Atoi(value.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s facet bucket %q: %w", name, value.String, err)
+		}
+
+		counts[bucket] = count
+	}
+
+	if rows.Err() != nil {
+		return nil, postgres.Classify(fmt.Errorf("failed while iterating over rows: %w", rows.Err()))
+	}
+
+	if bounds != nil {
+		return Histogram(bounds, counts), nil
+	}
+
+	return buckets, nil
+}
+
+// CreateRental validates and stores a new rental, created and updated are set to the current time
+func (r *Repository) CreateRental(ctx context.Context, input Input) (Model, error) {
+	if err := input.Validate(); err != nil {
+		return Model{}, err
+	}
+
+	query, args := buildInsertQuery(input)
+	rental, err := scanRental(r.db.QueryRowContext(ctx, query, args...), Filter{})
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to insert rental: %w", err))
+	}
+
+	return rental, nil
+}
+
+// UpdateRental validates and replaces every writable field of a rental by a given id
+func (r *Repository) UpdateRental(ctx context.Context, id int, input Input, precondition Precondition) (Model, error) {
+	if err := input.Validate(); err != nil {
+		return Model{}, err
+	}
+
+	return r.updateRental(ctx, id, input.ToPatch(), precondition)
+}
+
+// PatchRental validates and changes only the fields set by the patch of a rental by a given id
+func (r *Repository) PatchRental(ctx context.Context, id int, patch Patch, precondition Precondition) (Model, error) {
+	if err := patch.Validate(); err != nil {
+		return Model{}, err
+	}
+
+	return r.updateRental(ctx, id, patch, precondition)
+}
+
+func (r *Repository) updateRental(ctx context.Context, id int, patch Patch, precondition Precondition) (Model, error) {
+	if id <= 0 {
+		return Model{}, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	query, args := buildUpdateQuery(id, patch, precondition)
+	rental, err := scanRental(r.db.QueryRowContext(ctx, query, args...), Filter{})
+	if errors.Is(err, sql.ErrNoRows) && !precondition.IsZero() {
+		return Model{}, r.failedWrite(ctx, id)
+	}
+
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to update rental: %w", err))
+	}
+
+	return rental, nil
+}
+
+// DeleteRental deletes a rental by a given id
+func (r *Repository) DeleteRental(ctx context.Context, id int, precondition Precondition) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	query, args := deleteRental, []interface{}{id}
+	if !precondition.IsZero() {
+		query, args = deleteRentalIfUnchanged, append(args, precondition.Updated)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to delete rental: %w", err))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to read deleted rows: %w", err))
+	}
+
+	if affected == 0 && !precondition.IsZero() {
+		return r.failedWrite(ctx, id)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%w: rental %d does not exist", postgres.ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// failedWrite tells a missing rental from one that changed after a conditional write matched no row
+func (r *Repository) failedWrite(ctx context.Context, id int) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, rentalExists, id).Scan(&exists); err != nil {
+		return postgres.Classify(fmt.Errorf("failed to check rental: %w", err))
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: rental %d does not exist", postgres.ErrNotFound, id)
+	}
+
+	return fmt.Errorf("%w: rental %d was updated since it was read", postgres.ErrPreconditionFailed, id)
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// checkCurrency fails with a *postgres.ValidationError when a given currency is malformed or has no exchange rate
+func checkCurrency(ctx context.Context, db queryRower, currency string) error {
+	if !currencies.ValidCode(currency) {
+		return &postgres.ValidationError{
+			Errors: []postgres.FieldError{{Field: "currency", Reason: postgres.ReasonInvalidValue, Message: "must be an ISO 4217 code such as USD"}},
+			Kind:   ErrInvalidFilter,
+		}
+	}
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, currencyExists, currency).Scan(&exists); err != nil {
+		return postgres.Classify(fmt.Errorf("failed to check currency: %w", err))
+	}
+
+	if !exists {
+		return &postgres.ValidationError{
+			Errors: []postgres.FieldError{{Field: "currency", Reason: postgres.ReasonInvalidValue, Message: "has no exchange rate"}},
+			Kind:   ErrInvalidFilter,
+		}
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRental scans the rental columns, followed by the distance, relevance and converted price columns
+// when the filter selects them
+func scanRental(row scanner, filter Filter) (Model, error) {
 	var rental Model
-	err := row.Scan(
+	dest := []interface{}{
 		&rental.ID,
 		&rental.Name,
 		&rental.Description,
@@ -40,6 +319,14 @@ func (r *Repository) RetrieveRentalByID(ctx context.Context, id string) (Model,
 		&rental.Sleeps,
 		&rental.PrimaryImageURL,
 		&rental.PricePerDay,
+		&rental.WeeklyDiscountPercent,
+		&rental.MonthlyDiscountPercent,
+		&rental.WeekendSurchargePercent,
+		&rental.CleaningFee,
+		&rental.ServiceFeePercent,
+		&rental.Currency,
+		&rental.RatingAvg,
+		&rental.RatingCount,
 		&rental.HomeCity,
 		&rental.HomeState,
 		&rental.HomeZIP,
@@ -49,59 +336,32 @@ func (r *Repository) RetrieveRentalByID(ctx context.Context, id string) (Model,
 		&rental.UserID,
 		&rental.FirstName,
 		&rental.LastName,
-	)
-	if err != nil {
-		return Model{}, fmt.Errorf("failed to scan row: %w", err)
+		&rental.UserUpdated,
+		&rental.Created,
+		&rental.Updated,
+		pq.Array(&rental.Amenities),
+	}
+	if filter.Near != nil {
+		dest = append(dest, &rental.Distance)
 	}
 
-	return rental, nil
-}
-
-// RetrieveRentals retrieves filtered rentals by adding clauses to the query
-func (r *Repository) RetrieveRentals(ctx context.Context, clauses map[string][]string) ([]Model, error) {
-	rows, err := r.db.QueryContext(ctx, buildSQLQuery(selectRentals, clauses))
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute select rentals query: %w", err)
+	if filter.Query != "" {
+		dest = append(dest, &rental.Relevance)
 	}
-	defer rows.Close()
 
-	rentals := make([]Model, 0)
-	for rows.Next() {
-		var rental Model
-		err := rows.Scan(
-			&rental.ID,
-			&rental.Name,
-			&rental.Description,
-			&rental.Type,
-			&rental.VehicleMake,
-			&rental.VehicleModel,
-			&rental.VehicleYear,
-			&rental.VehicleLength,
-			&rental.Sleeps,
-			&rental.PrimaryImageURL,
-			&rental.PricePerDay,
-			&rental.HomeCity,
-			&rental.HomeState,
-			&rental.HomeZIP,
-			&rental.HomeCountry,
-			&rental.LAT,
-			&rental.LNG,
-			&rental.UserID,
-			&rental.FirstName,
-			&rental.LastName,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan a row: %w", err)
-		}
+	if filter.Currency != "" {
+		dest = append(dest, &rental.PricePerDay, &rental.CleaningFee)
+	}
 
-		rentals = append(rentals, rental)
+	if err := row.Scan(dest...); err != nil {
+		return Model{}, err
 	}
 
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("failed while iterating over rows: %w", rows.Err())
+	if filter.Currency != "" {
+		rental.Currency = filter.Currency
 	}
 
-	return rentals, nil
+	return rental, nil
 }
 
 // Close closes statements for repository
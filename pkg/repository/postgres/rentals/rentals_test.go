@@ -2,21 +2,49 @@ package rentals_test
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
 	"github.com/nvasilev98/rentals/pkg/repository/postgres/rentals"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
-const expectedSelectRentals = `SELECT 
-							r.id, name, description, type, vehicle_make, vehicle_model, vehicle_year,
-							vehicle_length, sleeps, primary_image_url, price_per_day, home_city, home_state,
-							home_zip, home_country, lat, lng, user_id, first_name, last_name
+const (
+	expectedRentalColumns = `r.id, name, description, type, vehicle_make, vehicle_model, vehicle_year,
+							vehicle_length, sleeps, primary_image_url, price_per_day, weekly_discount_percent,
+							monthly_discount_percent, weekend_surcharge_percent, cleaning_fee, service_fee_percent,
+							r.currency, r.rating_avg, r.rating_count, home_city, home_state, home_zip, home_country, lat, lng,
+							user_id, first_name, last_name, u.updated, r.created, r.updated,
+							ARRAY(SELECT a.slug FROM rental_amenities ra JOIN amenities a ON a.id = ra.amenity_id
+							WHERE ra.rental_id = r.id ORDER BY a.slug) AS amenities`
+	expectedSelectRentals = `SELECT ` + expectedRentalColumns + `
 							FROM rentals r
 							LEFT JOIN users u
 							ON r.user_id = u.id`
+	expectedCountRentals = `SELECT COUNT(*) FROM rentals r LEFT JOIN users u ON r.user_id = u.id`
+)
+
+func newRentalRows(models ...rentals.Model) *sqlmock.Rows {
+	rows := mock.NewRows(strings.Split(strings.Join(strings.Fields(expectedRentalColumns), ""), ","))
+	for _, model := range models {
+		amenities, _ := pq.StringArray(model.Amenities).Value()
+		rows.AddRow(model.ID, model.Name, model.Description, model.Type, model.VehicleMake, model.VehicleModel,
+			model.VehicleYear, model.VehicleLength, model.Sleeps, model.PrimaryImageURL, model.PricePerDay,
+			model.WeeklyDiscountPercent, model.MonthlyDiscountPercent, model.WeekendSurchargePercent,
+			model.CleaningFee, model.ServiceFeePercent, model.Currency, model.RatingAvg, model.RatingCount,
+			model.HomeCity, model.HomeState, model.HomeZIP, model.HomeCountry, model.LAT, model.LNG,
+			model.UserID, model.FirstName, model.LastName, model.UserUpdated, model.Created, model.Updated, amenities)
+	}
+
+	return rows
+}
 
 var _ = Describe("Rentals", func() {
 	AfterEach(func() {
@@ -26,7 +54,7 @@ var _ = Describe("Rentals", func() {
 	Context("NewRepository", func() {
 		When("preparing select rental by id statement fails", func() {
 			BeforeEach(func() {
-				mock.ExpectPrepare(expectedSelectRentals).WillReturnError(errors.New("err"))
+				mock.ExpectPrepare(regexp.QuoteMeta(expectedSelectRentals)).WillReturnError(errors.New("err"))
 			})
 
 			It("should return an error", func() {
@@ -45,7 +73,7 @@ var _ = Describe("Rentals", func() {
 		)
 
 		BeforeEach(func() {
-			prepare = mock.ExpectPrepare(expectedSelectRentals)
+			prepare = mock.ExpectPrepare(regexp.QuoteMeta(expectedSelectRentals))
 			repository, err = rentals.NewRepository(dbClient)
 			Expect(err).ToNot(HaveOccurred())
 			ctx = context.Background()
@@ -61,38 +89,752 @@ var _ = Describe("Rentals", func() {
 			})
 
 			It("should return an error", func() {
-				_, err := repository.RetrieveRentalByID(ctx, "id")
+				_, err := repository.RetrieveRentalByID(ctx, 1, "")
 				Expect(err).To(HaveOccurred())
 			})
 		})
 
+		When("id is not positive", func() {
+			It("should return an invalid input error without querying the database", func() {
+				_, err := repository.RetrieveRentalByID(ctx, 0, "")
+				Expect(err).To(MatchError(postgres.ErrInvalidInput))
+			})
+		})
+
+		When("rental does not exist", func() {
+			BeforeEach(func() {
+				prepare.ExpectQuery().WithArgs(sqlmock.AnyArg()).WillReturnRows(mock.NewRows([]string{"r.id"}))
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.RetrieveRentalByID(ctx, 1, "")
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		When("query is canceled by statement timeout", func() {
+			BeforeEach(func() {
+				prepare.ExpectQuery().WithArgs(sqlmock.AnyArg()).WillReturnError(&pq.Error{Code: "57014"})
+			})
+
+			It("should return a timeout error", func() {
+				_, err := repository.RetrieveRentalByID(ctx, 1, "")
+				Expect(err).To(MatchError(postgres.ErrTimeout))
+			})
+		})
+
 		When("retrieving a rental by a given id", func() {
 			testFields := []string{"r.id", "name", "description", "type", "vehicle_make", "vehicle_model", "vehicle_year",
-				"vehicle_length", "sleeps", "primary_image_url", "price_per_day", "home_city", "home_state",
-				"home_zip", "home_country", "lat", "lng", "user_id", "first_name", "last_name"}
+				"vehicle_length", "sleeps", "primary_image_url", "price_per_day", "weekly_discount_percent",
+				"monthly_discount_percent", "weekend_surcharge_percent", "cleaning_fee", "service_fee_percent", "r.currency", "r.rating_avg", "r.rating_count", "home_city", "home_state",
+				"home_zip", "home_country", "lat", "lng", "user_id", "first_name", "last_name", "u.updated", "r.created", "r.updated", "amenities"}
 			expectedRental := rentals.Model{
 				ID: 1, Name: "name", Description: "description", Type: "type", VehicleMake: "maker",
 				VehicleModel: "model", VehicleYear: 2, VehicleLength: 123.3, Sleeps: 3, PrimaryImageURL: "URL",
-				PricePerDay: 10, HomeCity: "city", HomeState: "state", HomeZIP: "ZIP", HomeCountry: "country",
-				LAT: 123.2, LNG: 456.1, UserID: 3, FirstName: "first-name", LastName: "last-name"}
+				PricePerDay: 10, WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 25, WeekendSurchargePercent: 15,
+				CleaningFee: 7500, ServiceFeePercent: 12, Currency: "USD", RatingAvg: 4.5, RatingCount: 2, HomeCity: "city", HomeState: "state", HomeZIP: "ZIP", HomeCountry: "country",
+				LAT: 123.2, LNG: 456.1, UserID: 3, FirstName: "first-name", LastName: "last-name",
+				UserUpdated: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				Created:     time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC), Updated: time.Date(2022, 2, 3, 4, 5, 6, 0, time.UTC),
+				Amenities: []string{"kitchen", "pets"}}
 
 			BeforeEach(func() {
 				mockRows := mock.NewRows(testFields).
 					AddRow(expectedRental.ID, expectedRental.Name, expectedRental.Description,
 						expectedRental.Type, expectedRental.VehicleMake, expectedRental.VehicleModel,
 						expectedRental.VehicleYear, expectedRental.VehicleLength, expectedRental.Sleeps,
-						expectedRental.PrimaryImageURL, expectedRental.PricePerDay, expectedRental.HomeCity,
+						expectedRental.PrimaryImageURL, expectedRental.PricePerDay,
+						[]byte("10.00"), []byte("25.00"), []byte("15.00"), expectedRental.CleaningFee, []byte("12.00"),
+						expectedRental.Currency, []byte("4.50"), expectedRental.RatingCount, expectedRental.HomeCity,
 						expectedRental.HomeState, expectedRental.HomeZIP, expectedRental.HomeCountry,
 						expectedRental.LAT, expectedRental.LNG, expectedRental.UserID,
-						expectedRental.FirstName, expectedRental.LastName)
+						expectedRental.FirstName, expectedRental.LastName, expectedRental.UserUpdated,
+						expectedRental.Created, expectedRental.Updated, "{kitchen,pets}")
 				prepare.ExpectQuery().WithArgs(sqlmock.AnyArg()).WillReturnRows(mockRows)
 			})
 
 			It("should succeeds", func() {
-				rental, err := repository.RetrieveRentalByID(ctx, "1")
+				rental, err := repository.RetrieveRentalByID(ctx, 1, "")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(rental).To(Equal(expectedRental))
 			})
 		})
+
+		When("retrieving a rental by a given id in another currency", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM exchange_rates WHERE currency = $1)")).
+					WithArgs("CAD").
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT "+expectedRentalColumns+
+					", convert_price(r.price_per_day, r.currency, $1) AS price_in_currency,"+
+					" convert_price(r.cleaning_fee, r.currency, $1) AS cleaning_fee_in_currency"+
+					" FROM rentals r LEFT JOIN users u ON r.user_id = u.id WHERE r.id IN ($2) ORDER BY r.id")).
+					WithArgs("CAD", 1).
+					WillReturnRows(mock.NewRows(append(strings.Split(expectedRentalColumns, ","), "price_in_currency", "cleaning_fee_in_currency")).
+						AddRow(1, "name", "description", "type", "maker", "model", 2, 123.3, 3, "URL", 10000, 0.0, 0.0, 0.0, 5000, 0.0, "USD", 0.0, 0, "city",
+							"state", "ZIP", "country", 33.6, -117.9, 3, "first-name", "last-name", time.Time{}, time.Time{}, time.Time{}, "{}", 13600, 6800))
+			})
+
+			It("should return the converted prices", func() {
+				rental, err := repository.RetrieveRentalByID(ctx, 1, "CAD")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.PricePerDay).To(Equal(13600))
+				Expect(rental.CleaningFee).To(Equal(6800))
+				Expect(rental.Currency).To(Equal("CAD"))
+			})
+		})
+	})
+
+	Context("RetrieveRentals", func() {
+		var (
+			repository *rentals.Repository
+			err        error
+			ctx        context.Context
+		)
+
+		BeforeEach(func() {
+			mock.ExpectPrepare(regexp.QuoteMeta(expectedSelectRentals))
+			repository, err = rentals.NewRepository(dbClient)
+			Expect(err).ToNot(HaveOccurred())
+			ctx = context.Background()
+		})
+
+		AfterEach(func() {
+			Expect(repository.Close()).To(Succeed())
+		})
+
+		When("beginning a transaction fails", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin().WillReturnError(&pq.Error{Code: "08006"})
+			})
+
+			It("should return an unavailable error", func() {
+				_, err := repository.RetrieveRentals(ctx, rentals.Filter{})
+				Expect(err).To(MatchError(postgres.ErrUnavailable))
+			})
+		})
+
+		When("executing count rentals query fails", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals)).WillReturnError(errors.New("err"))
+				mock.ExpectRollback()
+			})
+
+			It("should return an error", func() {
+				_, err := repository.RetrieveRentals(ctx, rentals.Filter{})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		When("executing select rentals query fails", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals)).WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRentals)).WillReturnError(&pq.Error{Code: "08006"})
+				mock.ExpectRollback()
+			})
+
+			It("should return an unavailable error", func() {
+				_, err := repository.RetrieveRentals(ctx, rentals.Filter{})
+				Expect(err).To(MatchError(postgres.ErrUnavailable))
+			})
+		})
+
+		When("filters, sorting and pagination are provided", func() {
+			conditions := " WHERE r.price_per_day >= $1 AND r.price_per_day <= $2 AND r.id IN ($3, $4)"
+			expectedQuery := expectedSelectRentals + conditions + " ORDER BY price_per_day, r.id OFFSET $5 LIMIT $6"
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals+conditions)).
+					WithArgs(100, 200, 1, 2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(42))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(100, 200, 1, 2, 10, 6).
+					WillReturnRows(mock.NewRows([]string{"r.id"}))
+				mock.ExpectCommit()
+			})
+
+			It("should bind the values as query arguments and count every matching rental", func() {
+				priceMin, priceMax := 100, 200
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					PriceMin: &priceMin,
+					PriceMax: &priceMax,
+					IDs:      []int{1, 2},
+					Sort:     []rentals.SortKey{{Field: "price"}},
+					Offset:   10,
+					Limit:    5,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Total).To(Equal(42))
+				Expect(page.Rentals).To(BeEmpty())
+			})
+		})
+
+		When("attribute filters are provided", func() {
+			conditions := " WHERE r.type IN ($1, $2) AND r.sleeps >= $3 AND r.vehicle_year >= $4 AND r.vehicle_year <= $5" +
+				" AND r.vehicle_length >= $6 AND r.vehicle_length <= $7 AND LOWER(r.vehicle_make) IN ($8)" +
+				" AND LOWER(r.home_state) IN ($9, $10) AND r.home_zip IN ($11) AND r.user_id = $12"
+
+			BeforeEach(func() {
+				args := []driver.Value{"camper-van", "trailer", 4, 2010, 2020, 15.5, 30.0, "volkswagen", "ca", "or", "97201", 3}
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals + conditions)).
+					WithArgs(args...).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRentals + conditions + " ORDER BY r.id")).
+					WithArgs(args...).
+					WillReturnRows(mock.NewRows([]string{"r.id"}))
+				mock.ExpectCommit()
+			})
+
+			It("should bind every value and match free text regardless of case", func() {
+				sleepsMin, yearMin, yearMax, userID := 4, 2010, 2020, 3
+				lengthMin, lengthMax := 15.5, 30.0
+				_, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					Types:     []string{"camper-van", "trailer"},
+					SleepsMin: &sleepsMin,
+					YearMin:   &yearMin,
+					YearMax:   &yearMax,
+					LengthMin: &lengthMin,
+					LengthMax: &lengthMax,
+					Makes:     []string{"Volkswagen"},
+					States:    []string{"CA", "OR"},
+					ZIPs:      []string{"97201"},
+					UserID:    &userID,
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("searching near a location", func() {
+			point := "ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography"
+			expectedQuery := "SELECT " + expectedRentalColumns + ", ST_Distance(r.location, " + point + ") / $3 AS distance" +
+				" FROM rentals r LEFT JOIN users u ON r.user_id = u.id" +
+				" WHERE ST_DWithin(r.location, " + point + ", $4) ORDER BY distance, r.id"
+
+			BeforeEach(func() {
+				mockRows := mock.NewRows(append(strings.Split(expectedRentalColumns, ","), "distance")).
+					AddRow(1, "name", "description", "type", "maker", "model", 2, 123.3, 3, "URL", 10, 0.0, 0.0, 0.0, 0, 0.0, "USD", 0.0, 0, "city", "state",
+						"ZIP", "country", 33.6, -117.9, 3, "first-name", "last-name", time.Time{}, time.Time{}, time.Time{}, "{}", 12.5)
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals+" WHERE ST_DWithin(r.location, "+point+", $3)")).
+					WithArgs(-117.93, 33.64, 20000.0).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(-117.93, 33.64, 1000.0, 20000.0).
+					WillReturnRows(mockRows)
+				mock.ExpectCommit()
+			})
+
+			It("should return the distance of every rental", func() {
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					Near:   &rentals.Coordinates{LAT: 33.64, LNG: -117.93},
+					Radius: rentals.Distance{Value: 20, Unit: rentals.Kilometers},
+					Sort:   []rentals.SortKey{{Field: "distance"}},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Rentals).To(HaveLen(1))
+				Expect(*page.Rentals[0].Distance).To(Equal(12.5))
+			})
+		})
+
+		When("searching by text", func() {
+			tsQuery := "websearch_to_tsquery('english', $1)"
+			expectedQuery := "SELECT " + expectedRentalColumns + ", ts_rank(r.search_vector, " + tsQuery + ")::double precision AS relevance" +
+				" FROM rentals r LEFT JOIN users u ON r.user_id = u.id" +
+				" WHERE r.search_vector @@ " + tsQuery + " ORDER BY relevance DESC, r.id"
+
+			BeforeEach(func() {
+				mockRows := mock.NewRows(append(strings.Split(expectedRentalColumns, ","), "relevance")).
+					AddRow(1, "name", "description", "type", "maker", "model", 2, 123.3, 3, "URL", 10, 0.0, 0.0, 0.0, 0, 0.0, "USD", 0.0, 0, "city", "state",
+						"ZIP", "country", 33.6, -117.9, 3, "first-name", "last-name", time.Time{}, time.Time{}, time.Time{}, "{}", 0.25)
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals + " WHERE r.search_vector @@ " + tsQuery)).
+					WithArgs("Westfalia pop-top").
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs("Westfalia pop-top").
+					WillReturnRows(mockRows)
+				mock.ExpectCommit()
+			})
+
+			It("should return the relevance of every rental", func() {
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					Query: "Westfalia pop-top",
+					Sort:  []rentals.SortKey{{Field: "relevance", Descending: true}},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Rentals).To(HaveLen(1))
+				Expect(*page.Rentals[0].Relevance).To(Equal(0.25))
+			})
+		})
+
+		When("searching within a bounding box and a polygon", func() {
+			conditions := " WHERE ST_Covers(ST_MakeEnvelope($1, $2, $3, $4, 4326)::geography, r.location)" +
+				" AND ST_Covers(ST_SetSRID(ST_GeomFromGeoJSON($5::text), 4326)::geography, r.location)"
+			polygon := rentals.Polygon{Rings: [][][2]float64{{{-118, 33}, {-117, 33}, {-117, 34}, {-118, 33}}}}
+			geoJSON := `{"type":"Polygon","coordinates":[[[-118,33],[-117,33],[-117,34],[-118,33]]]}`
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals+conditions)).
+					WithArgs(-118.0, 33.0, -117.0, 34.0, geoJSON).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRentals+conditions+" ORDER BY r.id")).
+					WithArgs(-118.0, 33.0, -117.0, 34.0, geoJSON).
+					WillReturnRows(mock.NewRows([]string{"r.id"}))
+				mock.ExpectCommit()
+			})
+
+			It("should bind the viewport and the GeoJSON geometry", func() {
+				_, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					BBox:   &rentals.BoundingBox{MinLNG: -118, MinLAT: 33, MaxLNG: -117, MaxLAT: 34},
+					Within: &polygon,
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("only rentals available within a window are requested", func() {
+			conditions := " WHERE NOT EXISTS (SELECT 1 FROM bookings b WHERE b.rental_id = r.id" +
+				" AND b.period && daterange($1::date, $2::date))"
+			from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+			to := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals+conditions)).
+					WithArgs(from, to).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRentals+conditions+" ORDER BY r.id")).
+					WithArgs(from, to).
+					WillReturnRows(mock.NewRows([]string{"r.id"}))
+				mock.ExpectCommit()
+			})
+
+			It("should exclude rentals booked within the window", func() {
+				_, err := repository.RetrieveRentals(ctx, rentals.Filter{AvailableFrom: &from, AvailableTo: &to})
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("rentals offering every listed amenity are requested", func() {
+			conditions := " WHERE (SELECT COUNT(*) FROM rental_amenities ra JOIN amenities a ON a.id = ra.amenity_id" +
+				" WHERE ra.rental_id = r.id AND a.slug IN ($1, $2)) = $3"
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals+conditions)).
+					WithArgs("kitchen", "pets", 2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRentals+conditions+" ORDER BY r.id")).
+					WithArgs("kitchen", "pets", 2).
+					WillReturnRows(newRentalRows(rentals.Model{ID: 1, Amenities: []string{"kitchen", "pets", "shower"}}))
+				mock.ExpectCommit()
+			})
+
+			It("should return them with all of their amenities", func() {
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{Amenities: []string{"kitchen", "pets"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Rentals).To(HaveLen(1))
+				Expect(page.Rentals[0].Amenities).To(Equal([]string{"kitchen", "pets", "shower"}))
+			})
+		})
+
+		When("rentals offering any of the listed amenities are requested", func() {
+			conditions := " WHERE (SELECT COUNT(*) FROM rental_amenities ra JOIN amenities a ON a.id = ra.amenity_id" +
+				" WHERE ra.rental_id = r.id AND a.slug IN ($1, $2)) > 0"
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals+conditions)).
+					WithArgs("kitchen", "pets").
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRentals+conditions+" ORDER BY r.id")).
+					WithArgs("kitchen", "pets").
+					WillReturnRows(mock.NewRows([]string{"r.id"}))
+				mock.ExpectCommit()
+			})
+
+			It("should only require one of them", func() {
+				_, err := repository.RetrieveRentals(ctx, rentals.Filter{Amenities: []string{"kitchen", "pets"},
+					AmenitiesMatch: rentals.AmenitiesMatchAny})
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("facets are requested", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals+" WHERE r.price_per_day <= $1 AND r.type IN ($2)")).
+					WithArgs(20000, "camper-van").
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRentals+" WHERE r.price_per_day <= $1 AND r.type IN ($2) ORDER BY r.id")).
+					WithArgs(20000, "camper-van").
+					WillReturnRows(newRentalRows(rentals.Model{ID: 1}, rentals.Model{ID: 2}))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT r.type AS value, COUNT(*) FROM rentals r LEFT JOIN users u ON r.user_id = u.id" +
+					" WHERE r.price_per_day <= $1 GROUP BY value ORDER BY COUNT(*) DESC, value")).
+					WithArgs(20000).
+					WillReturnRows(mock.NewRows([]string{"value", "count"}).AddRow("camper-van", 2).AddRow("trailer", 1))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT width_bucket(r.price_per_day, ARRAY[5000, 10000, 15000, 20000, 30000]) AS value," +
+					" COUNT(*) FROM rentals r LEFT JOIN users u ON r.user_id = u.id WHERE r.type IN ($1) GROUP BY value ORDER BY value")).
+					WithArgs("camper-van").
+					WillReturnRows(mock.NewRows([]string{"value", "count"}).AddRow(1, 1).AddRow(3, 1).AddRow(5, 1))
+				mock.ExpectCommit()
+			})
+
+			It("should count every facet without its own filter in the same transaction", func() {
+				priceMax := 20000
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					PriceMax: &priceMax,
+					Types:    []string{"camper-van"},
+					Facets:   []string{rentals.FacetType, rentals.FacetPrice},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Facets[rentals.FacetType]).To(Equal([]rentals.FacetBucket{
+					{Value: "camper-van", Count: 2}, {Value: "trailer", Count: 1}}))
+
+				prices := page.Facets[rentals.FacetPrice]
+				Expect(prices).To(HaveLen(6))
+				Expect(prices[0].Min).To(BeNil())
+				Expect(*prices[0].Max).To(Equal(5000))
+				Expect(prices[0].Count).To(Equal(0))
+				Expect(*prices[1].Min).To(Equal(5000))
+				Expect(prices[1].Count).To(Equal(1))
+				Expect(*prices[5].Min).To(Equal(30000))
+				Expect(prices[5].Max).To(BeNil())
+				Expect(prices[5].Count).To(Equal(1))
+			})
+		})
+
+		When("well rated rentals are requested best first", func() {
+			expectedQuery := expectedSelectRentals + " WHERE r.rating_avg >= $3" +
+				" AND ((r.rating_avg < $1) OR (r.rating_avg = $1 AND r.id > $2))" +
+				" ORDER BY r.rating_avg DESC, r.id LIMIT $4"
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals + " WHERE r.rating_avg >= $1")).
+					WithArgs(4.0).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(4.5, 2, 4.0, 2).
+					WillReturnRows(newRentalRows(rentals.Model{ID: 5, RatingAvg: 4.25, RatingCount: 4}))
+				mock.ExpectCommit()
+			})
+
+			It("should filter by the mean rating and continue after the cursor", func() {
+				ratingMin := 4.0
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					RatingMin: &ratingMin,
+					Sort:      []rentals.SortKey{{Field: "rating", Descending: true}},
+					Cursor:    &rentals.Cursor{Sort: "-rating", Values: []interface{}{4.5}, ID: 2},
+					Limit:     1,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Rentals).To(HaveLen(1))
+				Expect(page.Rentals[0].RatingAvg).To(Equal(4.25))
+				Expect(page.Rentals[0].RatingCount).To(Equal(4))
+			})
+		})
+
+		When("prices are requested in another currency", func() {
+			price := "convert_price(r.price_per_day, r.currency, $1)"
+			expectedQuery := "SELECT " + expectedRentalColumns + ", " + price + " AS price_in_currency," +
+				" convert_price(r.cleaning_fee, r.currency, $1) AS cleaning_fee_in_currency" +
+				" FROM rentals r LEFT JOIN users u ON r.user_id = u.id" +
+				" WHERE " + price + " >= $2 ORDER BY price_in_currency, r.id"
+
+			BeforeEach(func() {
+				mockRows := mock.NewRows(append(strings.Split(expectedRentalColumns, ","), "price_in_currency", "cleaning_fee_in_currency")).
+					AddRow(1, "name", "description", "type", "maker", "model", 2, 123.3, 3, "URL", 10000, 0.0, 0.0, 0.0, 5000, 0.0, "USD", 0.0, 0, "city", "state",
+						"ZIP", "country", 33.6, -117.9, 3, "first-name", "last-name", time.Time{}, time.Time{}, time.Time{}, "{}", 9200, 4600)
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM exchange_rates WHERE currency = $1)")).
+					WithArgs("EUR").
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals+" WHERE convert_price(r.price_per_day, r.currency, $1) >= $2")).
+					WithArgs("EUR", 5000).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs("EUR", 5000).
+					WillReturnRows(mockRows)
+				mock.ExpectCommit()
+			})
+
+			It("should compare, sort and return the converted prices", func() {
+				priceMin := 5000
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					PriceMin: &priceMin,
+					Currency: "EUR",
+					Sort:     []rentals.SortKey{{Field: "price"}},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Rentals).To(HaveLen(1))
+				Expect(page.Rentals[0].PricePerDay).To(Equal(9200))
+				Expect(page.Rentals[0].CleaningFee).To(Equal(4600))
+				Expect(page.Rentals[0].Currency).To(Equal("EUR"))
+			})
+		})
+
+		When("there is no exchange rate for the requested currency", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM exchange_rates WHERE currency = $1)")).
+					WithArgs("XYZ").
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			})
+
+			It("should return a validation error for the currency", func() {
+				_, err := repository.RetrieveRentals(ctx, rentals.Filter{Currency: "XYZ"})
+				Expect(err).To(MatchError(rentals.ErrInvalidFilter))
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Errors).To(ConsistOf(
+					postgres.FieldError{Field: "currency", Reason: postgres.ReasonInvalidValue, Message: "has no exchange rate"}))
+			})
+		})
+
+		When("more rentals than the limit match", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals)).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectRentals + " ORDER BY price_per_day, r.id LIMIT $1")).
+					WithArgs(3).
+					WillReturnRows(newRentalRows(
+						rentals.Model{ID: 1, PricePerDay: 100},
+						rentals.Model{ID: 2, PricePerDay: 200},
+						rentals.Model{ID: 3, PricePerDay: 300}))
+				mock.ExpectCommit()
+			})
+
+			It("should trim the extra rental and return a cursor to the next page", func() {
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{Sort: []rentals.SortKey{{Field: "price"}}, Limit: 2})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Rentals).To(HaveLen(2))
+				Expect(page.NextCursor).To(Equal(rentals.Cursor{Sort: "price", Values: []interface{}{200}, ID: 2}.Encode()))
+			})
+		})
+
+		When("a cursor is provided", func() {
+			expectedQuery := expectedSelectRentals +
+				" WHERE ((price_per_day > $1) OR (price_per_day = $1 AND r.id > $2))" +
+				" ORDER BY price_per_day, r.id LIMIT $3"
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals)).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(200.0, 2, 3).
+					WillReturnRows(newRentalRows(rentals.Model{ID: 3, PricePerDay: 300}))
+				mock.ExpectCommit()
+			})
+
+			It("should continue after the cursor and count every matching rental", func() {
+				// spare capacity must not receive the id tie-break
+				sort := make([]rentals.SortKey, 1, 2)
+				sort[0] = rentals.SortKey{Field: "price"}
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					Sort:   sort,
+					Cursor: &rentals.Cursor{Sort: "price", Values: []interface{}{200.0}, ID: 2},
+					Limit:  2,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Total).To(Equal(3))
+				Expect(page.Rentals).To(HaveLen(1))
+				Expect(page.NextCursor).To(BeEmpty())
+				Expect(sort[:2][1]).To(BeZero())
+			})
+		})
+
+		When("a cursor is provided for several sort keys in both directions", func() {
+			expectedQuery := expectedSelectRentals +
+				" WHERE ((price_per_day < $1) OR (price_per_day = $1 AND vehicle_year > $2)" +
+				" OR (price_per_day = $1 AND vehicle_year = $2 AND r.id > $3))" +
+				" ORDER BY price_per_day DESC, vehicle_year, r.id LIMIT $4"
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountRentals)).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(200.0, 2001.0, 2, 3).
+					WillReturnRows(newRentalRows(rentals.Model{ID: 3, PricePerDay: 100, VehicleYear: 1999}))
+				mock.ExpectCommit()
+			})
+
+			It("should compare descending keys with a lower than and break ties by id", func() {
+				page, err := repository.RetrieveRentals(ctx, rentals.Filter{
+					Sort:   []rentals.SortKey{{Field: "price", Descending: true}, {Field: "year"}},
+					Cursor: &rentals.Cursor{Sort: "-price,year", Values: []interface{}{200.0, 2001.0}, ID: 2},
+					Limit:  2,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Rentals).To(HaveLen(1))
+			})
+		})
+	})
+
+	Context("writing rentals", func() {
+		const expectedSelectWritten = ` SELECT ` + expectedRentalColumns + ` FROM written r LEFT JOIN users u ON r.user_id = u.id`
+		updated := time.Date(2022, 2, 3, 4, 5, 6, 0, time.UTC)
+
+		var (
+			repository *rentals.Repository
+			ctx        context.Context
+			input      rentals.Input
+		)
+
+		BeforeEach(func() {
+			var err error
+			mock.ExpectPrepare(regexp.QuoteMeta(expectedSelectRentals))
+			repository, err = rentals.NewRepository(dbClient)
+			Expect(err).ToNot(HaveOccurred())
+			ctx = context.Background()
+			input = rentals.Input{Name: "Westfalia", Type: "camper-van", VehicleYear: 1984, VehicleLength: 15.5,
+				Sleeps: 4, PricePerDay: 120, WeeklyDiscountPercent: 10, CleaningFee: 5000, LAT: 45.5, LNG: -122.6, UserID: 3}
+		})
+
+		AfterEach(func() {
+			Expect(repository.Close()).To(Succeed())
+		})
+
+		When("creating a valid rental", func() {
+			BeforeEach(func() {
+				expectedQuery := `WITH written AS (INSERT INTO rentals (name, description, type, vehicle_make, vehicle_model,` +
+					` vehicle_year, vehicle_length, sleeps, primary_image_url, price_per_day, weekly_discount_percent,` +
+					` monthly_discount_percent, weekend_surcharge_percent, cleaning_fee, service_fee_percent, currency,` +
+					` home_city, home_state, home_zip, home_country, lat, lng, user_id, created, updated) VALUES ($1, $2, $3, $4, $5,` +
+					` $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, now(), now()) RETURNING *)` +
+					expectedSelectWritten
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs("Westfalia", "", "camper-van", "", "", 1984, sqlmock.AnyArg(), 4, "", 120, 10.0, 0.0, 0.0, 5000, 0.0, "USD",
+						"", "", "", "",
+						sqlmock.AnyArg(), sqlmock.AnyArg(), 3).
+					WillReturnRows(newRentalRows(rentals.Model{ID: 7, Name: "Westfalia"}))
+			})
+
+			It("should insert it and return it with its id", func() {
+				rental, err := repository.CreateRental(ctx, input)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.ID).To(Equal(7))
+			})
+		})
+
+		When("creating an invalid rental", func() {
+			It("should return a validation error without querying the database", func() {
+				input.Name = ""
+				_, err := repository.CreateRental(ctx, input)
+				Expect(err).To(MatchError(rentals.ErrInvalidRental))
+				Expect(err).To(MatchError(postgres.ErrInvalidInput))
+			})
+		})
+
+		When("patching some fields of a rental", func() {
+			BeforeEach(func() {
+				expectedQuery := `WITH written AS (UPDATE rentals SET name = $1, price_per_day = $2, updated = now()` +
+					` WHERE id = $3 RETURNING *)` + expectedSelectWritten
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs("Vanagon", 150, 7).
+					WillReturnRows(newRentalRows(rentals.Model{ID: 7, Name: "Vanagon", PricePerDay: 150}))
+			})
+
+			It("should update only those fields", func() {
+				name, price := "Vanagon", 150
+				rental, err := repository.PatchRental(ctx, 7, rentals.Patch{Name: &name, PricePerDay: &price}, rentals.Precondition{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.Name).To(Equal("Vanagon"))
+			})
+		})
+
+		When("updating a rental that does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`WITH written AS (UPDATE rentals SET name = $1`)).
+					WillReturnRows(mock.NewRows([]string{"r.id"}))
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.UpdateRental(ctx, 7, input, rentals.Precondition{})
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		When("patching a rental that was not updated since it was read", func() {
+			BeforeEach(func() {
+				expectedQuery := `WITH written AS (UPDATE rentals SET name = $1, updated = now()` +
+					` WHERE id = $2 AND updated = $3 RETURNING *)` + expectedSelectWritten
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs("Vanagon", 7, updated).
+					WillReturnRows(newRentalRows(rentals.Model{ID: 7, Name: "Vanagon"}))
+			})
+
+			It("should update it", func() {
+				name := "Vanagon"
+				rental, err := repository.PatchRental(ctx, 7, rentals.Patch{Name: &name}, rentals.Precondition{Updated: updated})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rental.Name).To(Equal("Vanagon"))
+			})
+		})
+
+		When("patching a rental that was updated since it was read", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`WITH written AS (UPDATE rentals SET name = $1`)).
+					WithArgs("Vanagon", 7, updated).
+					WillReturnRows(mock.NewRows([]string{"r.id"}))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`)).
+					WithArgs(7).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+			})
+
+			It("should return a precondition failed error", func() {
+				name := "Vanagon"
+				_, err := repository.PatchRental(ctx, 7, rentals.Patch{Name: &name}, rentals.Precondition{Updated: updated})
+				Expect(err).To(MatchError(postgres.ErrPreconditionFailed))
+			})
+		})
+
+		When("deleting a rental", func() {
+			BeforeEach(func() {
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM rentals WHERE id = $1`)).
+					WithArgs(7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			})
+
+			It("should succeed", func() {
+				Expect(repository.DeleteRental(ctx, 7, rentals.Precondition{})).To(Succeed())
+			})
+		})
+
+		When("deleting a rental that does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM rentals WHERE id = $1`)).
+					WithArgs(7).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			})
+
+			It("should return a not found error", func() {
+				Expect(repository.DeleteRental(ctx, 7, rentals.Precondition{})).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		When("conditionally deleting a rental that does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM rentals WHERE id = $1 AND updated = $2`)).
+					WithArgs(7, updated).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`)).
+					WithArgs(7).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+			})
+
+			It("should return a not found error", func() {
+				Expect(repository.DeleteRental(ctx, 7, rentals.Precondition{Updated: updated})).To(MatchError(postgres.ErrNotFound))
+			})
+		})
 	})
 })
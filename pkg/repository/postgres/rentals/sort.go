@@ -0,0 +1,100 @@
+package rentals
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+// sortColumns maps the supported sort keys to their columns, adding more columns will extend sorting options
+var sortColumns = map[string]string{
+	"price":     "price_per_day",
+	"year":      "vehicle_year",
+	"sleeps":    "sleeps",
+	"length":    "vehicle_length",
+	"created":   "r.created",
+	"updated":   "r.updated",
+	"name":      "name",
+	"distance":  "distance",
+	"relevance": "relevance",
+	"rating":    "r.rating_avg",
+}
+
+// descendingByDefault lists the keys sorting best matches first without a minus, a leading minus reverses them too.
+// Only relevance does, every other key such as rating is ascending unless it has a leading minus.
+var descendingByDefault = map[string]bool{
+	"relevance": true,
+}
+
+// SortKey is a single sort criterion, a leading minus in the query selects descending order
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+func (k SortKey) String() string {
+	if k.Descending != descendingByDefault[k.Field] {
+		return "-" + k.Field
+	}
+
+	return k.Field
+}
+
+// sortSpec formats sort keys the way they are accepted in the sort query parameter
+func sortSpec(keys []SortKey) string {
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, key.String())
+	}
+
+	return strings.Join(values, ",")
+}
+
+func toDBColumnName(key string) (string, bool) {
+	dbColumnName, ok := sortColumns[key]
+	return dbColumnName, ok
+}
+
+func supportedSortKeys() string {
+	keys := make([]string, 0, len(sortColumns))
+	for key := range sortColumns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return strings.Join(keys, ", ")
+}
+
+// sort parses values such as -price,year into sort keys
+func (p *filterParser) sort(key string) []SortKey {
+	value, ok := p.value(key)
+	if !ok || value == "" {
+		return nil
+	}
+
+	keys := make([]SortKey, 0)
+	seen := make(map[string]bool)
+	for _, rawKey := range strings.Split(value, ",") {
+		field := strings.TrimSpace(rawKey)
+		reversed := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+		sortKey := SortKey{Field: field, Descending: reversed != descendingByDefault[field]}
+
+		if _, ok := toDBColumnName(sortKey.Field); !ok {
+			p.fail(key, postgres.ReasonInvalidValue, fmt.Sprintf("unknown sort key %q, supported keys are %s", sortKey.Field, supportedSortKeys()))
+			return nil
+		}
+
+		if seen[sortKey.Field] {
+			p.fail(key, postgres.ReasonInvalidValue, fmt.Sprintf("sort key %q is repeated", sortKey.Field))
+			return nil
+		}
+
+		seen[sortKey.Field] = true
+		keys = append(keys, sortKey)
+	}
+
+	return keys
+}
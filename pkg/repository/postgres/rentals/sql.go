@@ -1,9 +1,34 @@
 package rentals
 
-const selectRentals = `SELECT 
-							r.id, name, description, type, vehicle_make, vehicle_model, vehicle_year,
-							vehicle_length, sleeps, primary_image_url, price_per_day, home_city, home_state,
-							home_zip, home_country, lat, lng, user_id, first_name, last_name
-							FROM rentals r
+const rentalColumns = `r.id, name, description, type, vehicle_make, vehicle_model, vehicle_year,
+							vehicle_length, sleeps, primary_image_url, price_per_day, weekly_discount_percent,
+							monthly_discount_percent, weekend_surcharge_percent, cleaning_fee, service_fee_percent, r.currency,
+							r.rating_avg, r.rating_count, home_city, home_state, home_zip, home_country, lat, lng, user_id,
+							first_name, last_name, u.updated, r.created, r.updated, ` + rentalAmenities + ` AS amenities`
+
+// rentalAmenities collects the amenity slugs of a rental into an array ordered by slug
+const rentalAmenities = `ARRAY(SELECT a.slug FROM rental_amenities ra JOIN amenities a ON a.id = ra.amenity_id
+							WHERE ra.rental_id = r.id ORDER BY a.slug)`
+
+const rentalsFrom = `FROM rentals r
+							LEFT JOIN users u
+							ON r.user_id = u.id`
+
+const selectRentals = `SELECT
+							` + rentalColumns + `
+							` + rentalsFrom
+
+// selectWrittenRental reads back a rental written by the statement in the written common table expression
+const selectWrittenRental = `SELECT
+							` + rentalColumns + `
+							FROM written r
 							LEFT JOIN users u
 							ON r.user_id = u.id`
+
+const currencyExists = `SELECT EXISTS (SELECT 1 FROM exchange_rates WHERE currency = $1)`
+
+const rentalExists = `SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`
+
+const deleteRental = `DELETE FROM rentals WHERE id = $1`
+
+const deleteRentalIfUnchanged = deleteRental + ` AND updated = $2`
@@ -0,0 +1,10 @@
+package reviews
+
+import "errors"
+
+var (
+	// ErrInvalidFilter is returned when query parameters cannot be parsed into a filter
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidReview is returned when the fields of a review are not valid
+	ErrInvalidReview = errors.New("invalid review")
+)
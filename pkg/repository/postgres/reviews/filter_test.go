@@ -0,0 +1,28 @@
+package reviews_test
+
+import (
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/reviews"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filter", func() {
+	When("no query parameters are provided", func() {
+		It("should use the default limit", func() {
+			filter, err := reviews.ParseFilter(map[string][]string{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filter).To(Equal(reviews.Filter{Limit: reviews.DefaultLimit}))
+		})
+	})
+
+	DescribeTable("malformed query parameters",
+		func(query map[string][]string) {
+			_, err := reviews.ParseFilter(query)
+			Expect(err).To(MatchError(reviews.ErrInvalidFilter))
+		},
+		Entry("non-numeric offset", map[string][]string{"offset": {"abc"}}),
+		Entry("limit above the maximum", map[string][]string{"limit": {"101"}}),
+		Entry("unknown parameter", map[string][]string{"sort": {"rating"}}),
+		Entry("repeated offset", map[string][]string{"offset": {"0", "20"}}),
+	)
+})
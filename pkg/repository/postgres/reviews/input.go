@@ -0,0 +1,46 @@
+package reviews
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+// Input holds the fields of a new review, BookingID ties it to a completed booking of the user
+type Input struct {
+	RentalID  int
+	UserID    int
+	BookingID *int
+	Rating    int
+	Comment   string
+}
+
+// Validate checks every field of the input and returns every problem as a *postgres.ValidationError.
+// Field names follow the JSON representation of a review.
+func (i Input) Validate() error {
+	var errs []postgres.FieldError
+	if i.UserID <= 0 {
+		errs = append(errs, postgres.FieldError{Field: "user_id", Reason: postgres.ReasonInvalidValue, Message: "must be a positive integer"})
+	}
+
+	if i.BookingID != nil && *i.BookingID <= 0 {
+		errs = append(errs, postgres.FieldError{Field: "booking_id", Reason: postgres.ReasonInvalidValue, Message: "must be a positive integer"})
+	}
+
+	if i.Rating < MinRating || i.Rating > MaxRating {
+		errs = append(errs, postgres.FieldError{Field: "rating", Reason: postgres.ReasonOutOfRange,
+			Message: fmt.Sprintf("must be between %d and %d", MinRating, MaxRating)})
+	}
+
+	if utf8.RuneCountInString(i.Comment) > MaxCommentLength {
+		errs = append(errs, postgres.FieldError{Field: "comment", Reason: postgres.ReasonOutOfRange,
+			Message: fmt.Sprintf("must be at most %d characters", MaxCommentLength)})
+	}
+
+	if len(errs) > 0 {
+		return &postgres.ValidationError{Errors: errs, Kind: ErrInvalidReview}
+	}
+
+	return nil
+}
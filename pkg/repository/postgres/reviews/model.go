@@ -0,0 +1,28 @@
+package reviews
+
+import "time"
+
+const (
+	// MinRating and MaxRating bound the stars a review gives
+	MinRating = 1
+	MaxRating = 5
+	// MaxCommentLength is the longest comment in characters
+	MaxCommentLength = 2000
+)
+
+// Model is a review of a rental, BookingID is set when it was left for one of the bookings of its author
+type Model struct {
+	ID        int
+	RentalID  int
+	UserID    int
+	BookingID *int
+	Rating    int
+	Comment   string
+	Created   time.Time
+}
+
+// Page is a window of the reviews of a rental, newest first, together with the number of all of them
+type Page struct {
+	Reviews []Model
+	Total   int
+}
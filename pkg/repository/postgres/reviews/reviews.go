@@ -0,0 +1,156 @@
+package reviews
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository is a constructor function
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// CreateReview validates and stores a new review of a rental, the rating aggregates of the rental
+// are updated by the reviews_sync_rental_rating trigger. A second review of the same rental by
+// the same user, or for the same booking, fails with postgres.ErrConflict.
+func (r *Repository) CreateReview(ctx context.Context, input Input) (Model, error) {
+	if input.RentalID <= 0 {
+		return Model{}, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	if err := input.Validate(); err != nil {
+		return Model{}, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	if err := checkRental(ctx, tx, input.RentalID); err != nil {
+		return Model{}, err
+	}
+
+	if input.BookingID != nil {
+		var completed bool
+		err := tx.QueryRowContext(ctx, completedBookingExists, *input.BookingID, input.RentalID, input.UserID).Scan(&completed)
+		if err != nil {
+			return Model{}, postgres.Classify(fmt.Errorf("failed to check booking: %w", err))
+		}
+
+		if !completed {
+			return Model{}, &postgres.ValidationError{
+				Errors: []postgres.FieldError{{Field: "booking_id", Reason: postgres.ReasonInvalidValue,
+					Message: "must be a completed booking of the rental by the user"}},
+				Kind: ErrInvalidReview,
+			}
+		}
+	}
+
+	review, err := scanReview(tx.QueryRowContext(ctx, insertReview,
+		input.RentalID, input.UserID, input.BookingID, input.Rating, input.Comment))
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to insert review: %w", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return review, nil
+}
+
+// RetrieveReviews retrieves a page of the reviews of a rental, newest first, together with the total count.
+// Every query runs in a single read-only transaction, so they observe the same snapshot.
+func (r *Repository) RetrieveReviews(ctx context.Context, rentalID int, filter Filter) (Page, error) {
+	if rentalID <= 0 {
+		return Page{}, fmt.Errorf("%w: rental id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	if err := checkRental(ctx, tx, rentalID); err != nil {
+		return Page{}, err
+	}
+
+	var page Page
+	if err := tx.QueryRowContext(ctx, countReviews, rentalID).Scan(&page.Total); err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to execute count reviews query: %w", err))
+	}
+
+	rows, err := tx.QueryContext(ctx, selectReviewsPage, rentalID, filter.Offset, filter.Limit)
+	if err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to execute select reviews query: %w", err))
+	}
+	defer rows.Close()
+
+	page.Reviews = make([]Model, 0)
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return Page{}, postgres.Classify(fmt.Errorf("failed to scan a row: %w", err))
+		}
+
+		page.Reviews = append(page.Reviews, review)
+	}
+
+	if rows.Err() != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed while iterating over rows: %w", rows.Err()))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return page, nil
+}
+
+// checkRental fails with postgres.ErrNotFound when a rental does not exist
+func checkRental(ctx context.Context, tx *sql.Tx, rentalID int) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, rentalExists, rentalID).Scan(&exists); err != nil {
+		return postgres.Classify(fmt.Errorf("failed to check rental existence: %w", err))
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: rental %d does not exist", postgres.ErrNotFound, rentalID)
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReview(row scanner) (Model, error) {
+	var (
+		review    Model
+		bookingID sql.NullInt64
+	)
+	if err := row.Scan(&review.ID, &review.RentalID, &review.UserID, &bookingID, &review.Rating,
+		&review.Comment, &review.Created); err != nil {
+		return Model{}, err
+	}
+
+	if bookingID.Valid {
+		id := int(bookingID.Int64)
+		review.BookingID = &id
+	}
+
+	return review, nil
+}
@@ -0,0 +1,196 @@
+package reviews_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/reviews"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	expectedRentalExists           = `SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`
+	expectedCompletedBookingExists = `SELECT EXISTS (SELECT 1 FROM bookings
+							WHERE id = $1 AND rental_id = $2 AND user_id = $3 AND upper(period) <= current_date)`
+	expectedInsertReview = `INSERT INTO reviews (rental_id, user_id, booking_id, rating, comment)
+							VALUES ($1, $2, $3, $4, $5) RETURNING id, rental_id, user_id, booking_id, rating, comment, created`
+	expectedCountReviews      = `SELECT COUNT(*) FROM reviews WHERE rental_id = $1`
+	expectedSelectReviewsPage = `SELECT id, rental_id, user_id, booking_id, rating, comment, created FROM reviews
+							WHERE rental_id = $1 ORDER BY created DESC, id DESC OFFSET $2 LIMIT $3`
+)
+
+var reviewColumns = []string{"id", "rental_id", "user_id", "booking_id", "rating", "comment", "created"}
+
+var _ = Describe("Reviews", func() {
+	created := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+
+	var (
+		repository *reviews.Repository
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		repository = reviews.NewRepository(dbClient)
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("CreateReview", func() {
+		bookingID := 4
+		input := reviews.Input{RentalID: 1, UserID: 2, BookingID: &bookingID, Rating: 5, Comment: "Spotless"}
+
+		When("the review is for a completed booking of the user", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCompletedBookingExists)).WithArgs(4, 1, 2).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedInsertReview)).WithArgs(1, 2, &bookingID, 5, "Spotless").
+					WillReturnRows(mock.NewRows(reviewColumns).AddRow(7, 1, 2, 4, 5, "Spotless", created))
+				mock.ExpectCommit()
+			})
+
+			It("should store the review", func() {
+				review, err := repository.CreateReview(ctx, input)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(review).To(Equal(reviews.Model{ID: 7, RentalID: 1, UserID: 2, BookingID: &bookingID, Rating: 5,
+					Comment: "Spotless", Created: created}))
+			})
+		})
+
+		When("the review is not tied to a booking", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedInsertReview)).WithArgs(1, 2, nil, 4, "").
+					WillReturnRows(mock.NewRows(reviewColumns).AddRow(8, 1, 2, nil, 4, "", created))
+				mock.ExpectCommit()
+			})
+
+			It("should store it without a booking", func() {
+				review, err := repository.CreateReview(ctx, reviews.Input{RentalID: 1, UserID: 2, Rating: 4})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(review.BookingID).To(BeNil())
+			})
+		})
+
+		When("the rental does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.CreateReview(ctx, input)
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		When("the booking is not completed or belongs to someone else", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCompletedBookingExists)).WithArgs(4, 1, 2).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			})
+
+			It("should return a validation error for the booking", func() {
+				_, err := repository.CreateReview(ctx, input)
+				Expect(err).To(MatchError(reviews.ErrInvalidReview))
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Errors).To(HaveLen(1))
+				Expect(validationErr.Errors[0].Field).To(Equal("booking_id"))
+			})
+		})
+
+		When("the user has already reviewed the rental", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedInsertReview)).WithArgs(1, 2, nil, 3, "").
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "reviews_one_per_user"})
+				mock.ExpectRollback()
+			})
+
+			It("should return a conflict error", func() {
+				_, err := repository.CreateReview(ctx, reviews.Input{RentalID: 1, UserID: 2, Rating: 3})
+				Expect(err).To(MatchError(postgres.ErrConflict))
+			})
+		})
+
+		When("the review is invalid", func() {
+			It("should report every invalid field without querying the database", func() {
+				_, err := repository.CreateReview(ctx, reviews.Input{RentalID: 1, Rating: 6})
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Errors).To(ConsistOf(
+					postgres.FieldError{Field: "user_id", Reason: postgres.ReasonInvalidValue, Message: "must be a positive integer"},
+					postgres.FieldError{Field: "rating", Reason: postgres.ReasonOutOfRange, Message: "must be between 1 and 5"},
+				))
+			})
+		})
+	})
+
+	Context("RetrieveReviews", func() {
+		When("the rental has reviews", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedCountReviews)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedSelectReviewsPage)).WithArgs(1, 1, 2).
+					WillReturnRows(mock.NewRows(reviewColumns).
+						AddRow(9, 1, 3, nil, 4, "Great", created).
+						AddRow(8, 1, 2, 4, 5, "Spotless", created))
+				mock.ExpectCommit()
+			})
+
+			It("should return a page of them together with the total count", func() {
+				page, err := repository.RetrieveReviews(ctx, 1, reviews.Filter{Offset: 1, Limit: 2})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(page.Total).To(Equal(3))
+				Expect(page.Reviews).To(HaveLen(2))
+				Expect(page.Reviews[0].ID).To(Equal(9))
+				Expect(*page.Reviews[1].BookingID).To(Equal(4))
+			})
+		})
+
+		When("the rental does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedRentalExists)).WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.RetrieveReviews(ctx, 1, reviews.Filter{Limit: reviews.DefaultLimit})
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+
+		When("the rental id is not positive", func() {
+			It("should return an invalid input error without querying the database", func() {
+				_, err := repository.RetrieveReviews(ctx, 0, reviews.Filter{})
+				Expect(err).To(MatchError(postgres.ErrInvalidInput))
+			})
+		})
+	})
+})
@@ -0,0 +1,18 @@
+package reviews
+
+const reviewColumns = `id, rental_id, user_id, booking_id, rating, comment, created`
+
+const rentalExists = `SELECT EXISTS (SELECT 1 FROM rentals WHERE id = $1)`
+
+// completedBookingExists checks that a booking belongs to the rental and the user and has been checked out of
+const completedBookingExists = `SELECT EXISTS (SELECT 1 FROM bookings
+							WHERE id = $1 AND rental_id = $2 AND user_id = $3 AND upper(period) <= current_date)`
+
+// insertReview is rejected by reviews_one_per_user and reviews_one_per_booking when the rental is reviewed twice
+const insertReview = `INSERT INTO reviews (rental_id, user_id, booking_id, rating, comment)
+							VALUES ($1, $2, $3, $4, $5) RETURNING ` + reviewColumns
+
+const countReviews = `SELECT COUNT(*) FROM reviews WHERE rental_id = $1`
+
+const selectReviewsPage = `SELECT ` + reviewColumns + ` FROM reviews
+							WHERE rental_id = $1 ORDER BY created DESC, id DESC OFFSET $2 LIMIT $3`
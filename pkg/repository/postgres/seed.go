@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Seed executes fixture statements, such as fixtures/sample-data.sql, in a single transaction
+func Seed(ctx context.Context, db *sql.DB, statements string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return Classify(fmt.Errorf("failed to execute fixtures: %w", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return nil
+}
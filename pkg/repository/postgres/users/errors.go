@@ -0,0 +1,10 @@
+package users
+
+import "errors"
+
+var (
+	// ErrInvalidFilter is returned when query parameters cannot be parsed into a filter
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidUser is returned when the fields of a written user are not valid
+	ErrInvalidUser = errors.New("invalid user")
+)
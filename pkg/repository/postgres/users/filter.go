@@ -0,0 +1,79 @@
+package users
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+const (
+	// DefaultLimit is the page size used when limit is not provided
+	DefaultLimit = 20
+	// MaxLimit is the largest page size a client may request
+	MaxLimit = 100
+)
+
+type Filter struct {
+	Offset int
+	Limit  int
+}
+
+// ParseFilter parses and validates raw query parameters into a Filter.
+// All problems are collected and returned together as a *postgres.ValidationError.
+func ParseFilter(query map[string][]string) (Filter, error) {
+	var errs []postgres.FieldError
+	fail := func(field string, reason postgres.Reason, message string) {
+		errs = append(errs, postgres.FieldError{Field: field, Reason: reason, Message: message})
+	}
+
+	integer := func(key string, min, max, fallback int) int {
+		values, ok := query[key]
+		if !ok || len(values) == 0 {
+			return fallback
+		}
+
+		if len(values) > 1 {
+			fail(key, postgres.ReasonInvalidValue, "must be given once")
+			return fallback
+		}
+
+		number, err := strconv.Atoi(values[0])
+		if err != nil {
+			fail(key, postgres.ReasonInvalidValue, "must be an integer")
+			return fallback
+		}
+
+		if number < min || number > max {
+			fail(key, postgres.ReasonOutOfRange, fmt.Sprintf("must be between %d and %d", min, max))
+			return fallback
+		}
+
+		return number
+	}
+
+	filter := Filter{
+		Offset: integer("offset", 0, math.MaxInt, 0),
+		Limit:  integer("limit", 1, MaxLimit, DefaultLimit),
+	}
+
+	unknown := make([]string, 0)
+	for key := range query {
+		if key != "offset" && key != "limit" {
+			unknown = append(unknown, key)
+		}
+	}
+
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		fail(key, postgres.ReasonUnknownParameter, "is not a supported query parameter")
+	}
+
+	if len(errs) > 0 {
+		return Filter{}, &postgres.ValidationError{Errors: errs, Kind: ErrInvalidFilter}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,29 @@
+package users_test
+
+import (
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/users"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filter", func() {
+	When("no query parameters are provided", func() {
+		It("should use the default limit", func() {
+			filter, err := users.ParseFilter(map[string][]string{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filter).To(Equal(users.Filter{Limit: users.DefaultLimit}))
+		})
+	})
+
+	DescribeTable("malformed query parameters",
+		func(query map[string][]string) {
+			_, err := users.ParseFilter(query)
+			Expect(err).To(MatchError(users.ErrInvalidFilter))
+		},
+		Entry("non-numeric offset", map[string][]string{"offset": {"abc"}}),
+		Entry("negative offset", map[string][]string{"offset": {"-1"}}),
+		Entry("limit above the maximum", map[string][]string{"limit": {"101"}}),
+		Entry("unknown parameter", map[string][]string{"sort": {"name"}}),
+		Entry("repeated limit", map[string][]string{"limit": {"5", "50"}}),
+	)
+})
@@ -0,0 +1,39 @@
+package users
+
+import "github.com/nvasilev98/rentals/pkg/repository/postgres"
+
+// Input holds every writable field of a user, it is used when creating a user
+type Input struct {
+	FirstName string
+	LastName  string
+}
+
+// Patch holds the fields of a partial update, nil fields are left unchanged
+type Patch struct {
+	FirstName *string
+	LastName  *string
+}
+
+// Validate checks the fields set by the patch and returns every problem as a *postgres.ValidationError.
+// Field names follow the JSON representation of a user.
+func (p Patch) Validate() error {
+	var errs []postgres.FieldError
+	if p.FirstName != nil && *p.FirstName == "" {
+		errs = append(errs, postgres.FieldError{Field: "first_name", Reason: postgres.ReasonInvalidValue, Message: "must not be empty"})
+	}
+
+	if p.LastName != nil && *p.LastName == "" {
+		errs = append(errs, postgres.FieldError{Field: "last_name", Reason: postgres.ReasonInvalidValue, Message: "must not be empty"})
+	}
+
+	if len(errs) > 0 {
+		return &postgres.ValidationError{Errors: errs, Kind: ErrInvalidUser}
+	}
+
+	return nil
+}
+
+// Validate checks every field of the input and returns every problem as a *postgres.ValidationError
+func (i Input) Validate() error {
+	return Patch{FirstName: &i.FirstName, LastName: &i.LastName}.Validate()
+}
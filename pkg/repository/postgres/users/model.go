@@ -0,0 +1,13 @@
+package users
+
+type Model struct {
+	ID        int
+	FirstName string
+	LastName  string
+}
+
+// Page is a window of users together with the number of all users
+type Page struct {
+	Users []Model
+	Total int
+}
@@ -0,0 +1,23 @@
+package users
+
+const userColumns = `id, first_name, last_name`
+
+const selectUsers = `SELECT ` + userColumns + ` FROM users`
+
+const countUsers = `SELECT COUNT(*) FROM users`
+
+const selectUsersPage = selectUsers + ` ORDER BY id OFFSET $1 LIMIT $2`
+
+const insertUser = `INSERT INTO users (first_name, last_name) VALUES ($1, $2) RETURNING ` + userColumns
+
+// patchUser keeps a column unchanged when its argument is NULL, updated versions the rentals the user owns
+const patchUser = `UPDATE users SET first_name = COALESCE($1, first_name), last_name = COALESCE($2, last_name),
+							updated = now() WHERE id = $3 RETURNING ` + userColumns
+
+const countUserRentals = `SELECT COUNT(*) FROM rentals WHERE user_id = $1`
+
+const countUserBookings = `SELECT COUNT(*) FROM bookings WHERE user_id = $1`
+
+const countUserReviews = `SELECT COUNT(*) FROM reviews WHERE user_id = $1`
+
+const deleteUser = `DELETE FROM users WHERE id = $1`
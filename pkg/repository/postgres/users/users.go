@@ -0,0 +1,190 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+)
+
+type Repository struct {
+	db                 *sql.DB
+	selectUserByIDStmt *sql.Stmt
+}
+
+// NewRepository is a constructor function
+func NewRepository(db *sql.DB) (*Repository, error) {
+	selectUserByIDStmt, err := db.Prepare(fmt.Sprintf("%s WHERE id=$1", selectUsers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare select user by id statement: %w", err)
+	}
+
+	return &Repository{
+		db:                 db,
+		selectUserByIDStmt: selectUserByIDStmt,
+	}, nil
+}
+
+// RetrieveUserByID retrieves user by a given id from repository
+func (r *Repository) RetrieveUserByID(ctx context.Context, id int) (Model, error) {
+	if id <= 0 {
+		return Model{}, fmt.Errorf("%w: user id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	user, err := scanUser(r.selectUserByIDStmt.QueryRowContext(ctx, id))
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to scan row: %w", err))
+	}
+
+	return user, nil
+}
+
+// RetrieveUsers retrieves a page of users ordered by id together with the total count.
+// Both queries run in a single read-only transaction, so they observe the same snapshot.
+func (r *Repository) RetrieveUsers(ctx context.Context, filter Filter) (Page, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	var page Page
+	if err := tx.QueryRowContext(ctx, countUsers).Scan(&page.Total); err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to execute count users query: %w", err))
+	}
+
+	rows, err := tx.QueryContext(ctx, selectUsersPage, filter.Offset, filter.Limit)
+	if err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to execute select users query: %w", err))
+	}
+	defer rows.Close()
+
+	page.Users = make([]Model, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return Page{}, postgres.Classify(fmt.Errorf("failed to scan a row: %w", err))
+		}
+
+		page.Users = append(page.Users, user)
+	}
+
+	if rows.Err() != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed while iterating over rows: %w", rows.Err()))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Page{}, postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return page, nil
+}
+
+// CreateUser validates and stores a new user
+func (r *Repository) CreateUser(ctx context.Context, input Input) (Model, error) {
+	if err := input.Validate(); err != nil {
+		return Model{}, err
+	}
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, insertUser, input.FirstName, input.LastName))
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to insert user: %w", err))
+	}
+
+	return user, nil
+}
+
+// PatchUser validates and changes only the fields set by the patch of a user by a given id
+func (r *Repository) PatchUser(ctx context.Context, id int, patch Patch) (Model, error) {
+	if id <= 0 {
+		return Model{}, fmt.Errorf("%w: user id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	if err := patch.Validate(); err != nil {
+		return Model{}, err
+	}
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, patchUser, patch.FirstName, patch.LastName, id))
+	if err != nil {
+		return Model{}, postgres.Classify(fmt.Errorf("failed to update user: %w", err))
+	}
+
+	return user, nil
+}
+
+// userReferences are the rows that keep a user from being deleted, their foreign keys restrict the delete
+var userReferences = []struct {
+	name  string
+	query string
+}{
+	{name: "rentals", query: countUserRentals},
+	{name: "bookings", query: countUserBookings},
+	{name: "reviews", query: countUserReviews},
+}
+
+// DeleteUser deletes a user by a given id, users who still own rentals or have bookings or reviews cannot be deleted
+func (r *Repository) DeleteUser(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: user id must be a positive integer", postgres.ErrInvalidInput)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	for _, reference := range userReferences {
+		var count int
+		if err := tx.QueryRowContext(ctx, reference.query, id).Scan(&count); err != nil {
+			return postgres.Classify(fmt.Errorf("failed to count user %s: %w", reference.name, err))
+		}
+
+		if count > 0 {
+			return fmt.Errorf("%w: user %d still has %d %s", postgres.ErrConflict, id, count, reference.name)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to delete user: %w", err))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return postgres.Classify(fmt.Errorf("failed to read deleted rows: %w", err))
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%w: user %d does not exist", postgres.ErrNotFound, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return postgres.Classify(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row scanner) (Model, error) {
+	var user Model
+	if err := row.Scan(&user.ID, &user.FirstName, &user.LastName); err != nil {
+		return Model{}, err
+	}
+
+	return user, nil
+}
+
+// Close closes statements for repository
+func (r *Repository) Close() error {
+	if err := r.selectUserByIDStmt.Close(); err != nil {
+		return fmt.Errorf("failed to close select user by id statement: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package users_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres"
+	"github.com/nvasilev98/rentals/pkg/repository/postgres/users"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const expectedSelectUsers = `SELECT id, first_name, last_name FROM users`
+
+var _ = Describe("Users", func() {
+	var (
+		repository *users.Repository
+		prepare    *sqlmock.ExpectedPrepare
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+		prepare = mock.ExpectPrepare(regexp.QuoteMeta(expectedSelectUsers + " WHERE id=$1"))
+		repository, err = users.NewRepository(dbClient)
+		Expect(err).ToNot(HaveOccurred())
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(repository.Close()).To(Succeed())
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("RetrieveUserByID", func() {
+		When("user exists", func() {
+			BeforeEach(func() {
+				prepare.ExpectQuery().WithArgs(1).
+					WillReturnRows(mock.NewRows([]string{"id", "first_name", "last_name"}).AddRow(1, "John", "Smith"))
+			})
+
+			It("should return it", func() {
+				user, err := repository.RetrieveUserByID(ctx, 1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(user).To(Equal(users.Model{ID: 1, FirstName: "John", LastName: "Smith"}))
+			})
+		})
+
+		When("user does not exist", func() {
+			BeforeEach(func() {
+				prepare.ExpectQuery().WithArgs(1).WillReturnRows(mock.NewRows([]string{"id"}))
+			})
+
+			It("should return a not found error", func() {
+				_, err := repository.RetrieveUserByID(ctx, 1)
+				Expect(err).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+	})
+
+	Context("RetrieveUsers", func() {
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM users`)).
+				WillReturnRows(mock.NewRows([]string{"count"}).AddRow(5))
+			mock.ExpectQuery(regexp.QuoteMeta(expectedSelectUsers+" ORDER BY id OFFSET $1 LIMIT $2")).
+				WithArgs(2, 2).
+				WillReturnRows(mock.NewRows([]string{"id", "first_name", "last_name"}).
+					AddRow(3, "Barry", "Martin").
+					AddRow(4, "Todd", "Edison"))
+			mock.ExpectCommit()
+		})
+
+		It("should return the page and the total count", func() {
+			page, err := repository.RetrieveUsers(ctx, users.Filter{Offset: 2, Limit: 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Total).To(Equal(5))
+			Expect(page.Users).To(HaveLen(2))
+		})
+	})
+
+	Context("CreateUser", func() {
+		When("input is valid", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO users (first_name, last_name) VALUES ($1, $2) RETURNING id, first_name, last_name`)).
+					WithArgs("Ada", "Lovelace").
+					WillReturnRows(mock.NewRows([]string{"id", "first_name", "last_name"}).AddRow(6, "Ada", "Lovelace"))
+			})
+
+			It("should return the new user", func() {
+				user, err := repository.CreateUser(ctx, users.Input{FirstName: "Ada", LastName: "Lovelace"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(user.ID).To(Equal(6))
+			})
+		})
+
+		When("input is not valid", func() {
+			It("should list every invalid field without querying the database", func() {
+				_, err := repository.CreateUser(ctx, users.Input{})
+				Expect(err).To(MatchError(users.ErrInvalidUser))
+				var validationErr *postgres.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Errors).To(HaveLen(2))
+			})
+		})
+	})
+
+	Context("PatchUser", func() {
+		BeforeEach(func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET first_name = COALESCE($1, first_name), last_name = COALESCE($2, last_name), updated = now() WHERE id = $3`)).
+				WithArgs(nil, "Doe-Smith", 2).
+				WillReturnRows(mock.NewRows([]string{"id", "first_name", "last_name"}).AddRow(2, "Jane", "Doe-Smith"))
+		})
+
+		It("should keep the fields missing from the patch", func() {
+			lastName := "Doe-Smith"
+			user, err := repository.PatchUser(ctx, 2, users.Patch{LastName: &lastName})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user).To(Equal(users.Model{ID: 2, FirstName: "Jane", LastName: "Doe-Smith"}))
+		})
+	})
+
+	Context("DeleteUser", func() {
+		BeforeEach(func() {
+			mock.ExpectBegin()
+		})
+
+		When("user owns no rentals and has no bookings nor reviews", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM rentals WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM bookings WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM reviews WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM users WHERE id = $1`)).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("should delete the user", func() {
+				Expect(repository.DeleteUser(ctx, 2)).To(Succeed())
+			})
+		})
+
+		When("user still owns rentals", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM rentals WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectRollback()
+			})
+
+			It("should return a conflict error", func() {
+				Expect(repository.DeleteUser(ctx, 2)).To(MatchError(postgres.ErrConflict))
+			})
+		})
+
+		When("user still has bookings", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM rentals WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM bookings WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			})
+
+			It("should return a conflict error instead of violating the bookings foreign key", func() {
+				Expect(repository.DeleteUser(ctx, 2)).To(MatchError(postgres.ErrConflict))
+			})
+		})
+
+		When("user still has reviews", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM rentals WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM bookings WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM reviews WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectRollback()
+			})
+
+			It("should return a conflict error instead of violating the reviews foreign key", func() {
+				Expect(repository.DeleteUser(ctx, 2)).To(MatchError(postgres.ErrConflict))
+			})
+		})
+
+		When("user does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM rentals WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM bookings WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM reviews WHERE user_id = $1`)).
+					WithArgs(2).
+					WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM users WHERE id = $1`)).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			})
+
+			It("should return a not found error", func() {
+				Expect(repository.DeleteUser(ctx, 2)).To(MatchError(postgres.ErrNotFound))
+			})
+		})
+	})
+})
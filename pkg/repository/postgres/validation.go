@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reason tells why a field is not valid
+type Reason string
+
+const (
+	ReasonInvalidValue     Reason = "invalid_value"
+	ReasonOutOfRange       Reason = "out_of_range"
+	ReasonUnknownParameter Reason = "unknown_parameter"
+)
+
+type FieldError struct {
+	Field   string
+	Reason  Reason
+	Message string
+}
+
+// ValidationError holds every problem found while validating query parameters or written records.
+// It matches ErrInvalidInput and Kind, which tells what was validated, with errors.Is.
+type ValidationError struct {
+	Errors []FieldError
+	Kind   error
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message))
+	}
+
+	kind := e.Kind
+	if kind == nil {
+		kind = ErrInvalidInput
+	}
+
+	return fmt.Sprintf("%s: %s", kind, strings.Join(messages, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidInput || (e.Kind != nil && target == e.Kind)
+}